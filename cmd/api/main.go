@@ -15,11 +15,16 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/vantutran2k1/flowfleet/internal/adapter/handler"
 	"github.com/vantutran2k1/flowfleet/internal/adapter/logger"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/routing"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/blob"
 	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
 	redis_adaptor "github.com/vantutran2k1/flowfleet/internal/adapter/storage/redis"
 	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
 	"github.com/vantutran2k1/flowfleet/internal/config"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/core/port"
 	"github.com/vantutran2k1/flowfleet/internal/core/service"
+	"github.com/vantutran2k1/flowfleet/internal/core/service/pricing"
 	"go.uber.org/zap"
 )
 
@@ -61,11 +66,108 @@ func main() {
 	store := postgres.NewStore(pool)
 	driverHandler := handler.NewDriverHandler(store)
 
-	geoStore := redis_adaptor.NewGeoStore(rdb)
+	geoStore := redis_adaptor.NewGeoStore(rdb, cfg.DriverStaleAfter)
 	dispatchService := service.NewDispatchService(store, geoStore, hub)
+	sweeperCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+	go geoStore.RunSweeper(sweeperCtx, cfg.DriverSweepInterval, dispatchService.ForgetDrivers)
+
+	dispatchService.SetPricingStrategy(pricing.NewPolicyStrategy(
+		postgres.NewFleetRepository(store),
+		pricing.NewPromoStrategy(
+			postgres.NewPromoRepository(store),
+			pricing.NewHandlingStrategy(
+				pricing.DefaultHandlingRates,
+				pricing.NewZoneStrategy(
+					postgres.NewZoneRepository(store),
+					pricing.NewTariffStrategy(
+						postgres.NewTariffRepository(store),
+						pricing.NewStandardStrategy(),
+					),
+				),
+			),
+		),
+	))
 	hub.SetService(dispatchService)
 
+	var router port.RoutingProvider = routing.NewHaversineProvider(cfg.RoutingDetourFactor, cfg.RoutingSpeedKmh)
+	if cfg.RoutingOSRMUrl != "" {
+		router = routing.NewFallbackProvider(
+			routing.NewOSRMProvider(cfg.RoutingOSRMUrl, cfg.RoutingOSRMProfile, nil),
+			router,
+		)
+	}
+	dispatchService.SetRoutingProvider(routing.NewCachedProvider(router, cfg.RoutingCacheTTL, 10000))
+	dispatchService.SetETAPolicy(service.ETAPolicy{
+		RefreshInterval: cfg.ETARefreshInterval,
+		LateThreshold:   cfg.ETALateThreshold,
+		LookupInterval:  cfg.ETALookupInterval,
+	})
+	arrivalCheck := service.ArrivalCheck(cfg.GeofenceManualArrivalCheck)
+	if !arrivalCheck.Valid() {
+		log.Fatalf("invalid GEOFENCE_MANUAL_ARRIVAL_CHECK %q: must be off, lenient or strict", arrivalCheck)
+	}
+	dispatchService.SetGeofencePolicy(service.GeofencePolicy{
+		ArrivalRadiusM:     cfg.GeofenceArrivalRadiusM,
+		AutoArrive:         cfg.GeofenceAutoArrive,
+		ManualArrivalMaxM:  cfg.GeofenceManualArrivalMaxM,
+		ManualArrivalCheck: arrivalCheck,
+	})
+	dispatchService.SetStackingPolicy(service.StackingPolicy{
+		Enabled:   cfg.StackingEnabled,
+		MaxDetour: cfg.StackingMaxDetour,
+		MaxOrders: cfg.StackingMaxOrders,
+	})
+	dispatchService.SetSchedulePolicy(service.SchedulePolicy{
+		ReleaseLead: cfg.ScheduleReleaseLead,
+		MaxAhead:    cfg.ScheduleMaxAhead,
+	})
+	dispatchService.SetRecurringPolicy(service.RecurringPolicy{
+		Horizon: cfg.RecurringHorizon,
+	})
+	dispatchService.SetSLAPolicy(service.SLAPolicy{
+		AtRiskMargin:    cfg.SLAAtRiskMargin,
+		RedispatchAfter: cfg.RedispatchAfter,
+		ReservedDrivers: cfg.ReservedDrivers,
+	})
+	dispatchService.SetDeliveryFailurePolicy(service.DeliveryFailurePolicy{
+		MaxAttempts:      cfg.DeliveryMaxAttempts,
+		ReturnFeePercent: cfg.ReturnFeePercent,
+	})
+	dispatchService.SetLocationPolicy(service.LocationPolicy{
+		MaxSpeedKmh:  cfg.LocationMaxSpeedKmh,
+		MaxAccuracyM: cfg.LocationMaxAccuracyM,
+		MaxClockSkew: cfg.LocationMaxClockSkew,
+	})
+
+	locationWriter := postgres.NewLocationWriter(store, cfg.LocationHistoryBatchSize, cfg.LocationHistoryFlushInterval)
+	locationCtx, stopLocationWriter := context.WithCancel(context.Background())
+	locationWriterDone := make(chan struct{})
+	go func() {
+		defer close(locationWriterDone)
+		locationWriter.Run(locationCtx)
+	}()
+	dispatchService.SetLocationRecorder(locationWriter)
+
+	blobStore, err := blob.NewLocalStore(cfg.BlobDir)
+	if err != nil {
+		appLogger.Fatal("cannot open blob storage", zap.Error(err))
+	}
+	dispatchService.SetBlobStore(blobStore)
+
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+	go dispatchService.RunScheduler(schedulerCtx, cfg.SchedulePollInterval)
+
 	orderHandler := handler.NewOrderHandler(dispatchService)
+	recurringOrderHandler := handler.NewRecurringOrderHandler(dispatchService)
+	proofHandler := handler.NewProofHandler(dispatchService)
+	zoneHandler := handler.NewZoneHandler(store)
+	serviceAreaHandler := handler.NewServiceAreaHandler(store)
+	promoHandler := handler.NewPromoHandler(store)
+	fleetHandler := handler.NewFleetHandler(store)
+	trackingHandler := handler.NewTrackingHandler(dispatchService, hub)
+	opsHandler := handler.NewOpsHandler(dispatchService, hub)
 
 	authService := service.NewAuthService()
 	authHandler := handler.NewAuthHandler(authService, pool)
@@ -80,15 +182,54 @@ func main() {
 	api := r.Group("/api/v1")
 	{
 		api.POST("/login", authHandler.Login)
+		api.GET("/orders/:id/track", trackingHandler.TrackOrder)
+		api.GET("/track/:token", trackingHandler.PublicTracking)
 
 		protected := api.Group("/")
 		protected.Use(handler.AuthMiddleware(authService))
 		{
 			api.POST("/drivers", driverHandler.CreateDriver)
 			api.POST("/orders", orderHandler.CreateOrder)
+			protected.GET("/orders/:id", orderHandler.GetOrder)
+			protected.PATCH("/orders/:id/schedule", orderHandler.RescheduleOrder)
+			protected.POST("/orders/:id/cancel", orderHandler.CancelOrder)
+			protected.POST("/orders/:id/tracking-link", trackingHandler.CreateTrackingLink)
+			protected.DELETE("/orders/:id/tracking-link/:link_id", trackingHandler.RevokeTrackingLink)
 			api.POST("/orders/:id/arrive", orderHandler.ArriveAtPickup)
 			api.POST("/orders/:id/pickup", orderHandler.PickUpOrder)
 			api.POST("/orders/:id/deliver", orderHandler.CompleteOrder)
+			protected.POST("/orders/:id/stops/:position/arrive", orderHandler.ArriveAtStop)
+			protected.POST("/orders/:id/stops/:position/complete", orderHandler.CompleteStop)
+			protected.PUT("/orders/:id/proof", proofHandler.SubmitProof)
+			protected.GET("/orders/:id/proof", proofHandler.GetProof)
+			protected.GET("/orders/:id/proof/:kind", proofHandler.GetProofImage)
+			protected.POST("/orders/:id/fail", orderHandler.FailDelivery)
+			protected.GET("/orders/:id/delivery-attempts", orderHandler.DeliveryAttempts)
+
+			protected.GET("/drivers/:id/earnings", driverHandler.GetEarnings)
+			protected.GET("/drivers/:id/track", driverHandler.GetTrack)
+			protected.PUT("/drivers/:id/capacity", driverHandler.UpdateCapacity)
+			protected.GET("/fleets/:id/pricing-policy", handler.RequireFleetRole(domain.RoleDispatcher), fleetHandler.GetPricingPolicy)
+			protected.PUT("/fleets/:id/pricing-policy", handler.RequireFleetRole(domain.RoleAdmin), fleetHandler.UpdatePricingPolicy)
+			protected.GET("/fleets/:id/proof-policy", handler.RequireFleetRole(domain.RoleDriver), fleetHandler.GetProofPolicy)
+			protected.PUT("/fleets/:id/proof-policy", handler.RequireFleetRole(domain.RoleAdmin), fleetHandler.UpdateProofPolicy)
+			protected.GET("/fleets/:id/ops", handler.RequireFleetRole(domain.RoleDispatcher), opsHandler.Feed)
+			protected.GET("/fleets/:id/sla-breaches", handler.RequireFleetRole(domain.RoleDispatcher), opsHandler.SLABreaches)
+			protected.GET("/fleets/:id/zones", handler.RequireFleetRole(domain.RoleDriver), zoneHandler.ListZones)
+			protected.POST("/fleets/:id/zones", handler.RequireFleetRole(domain.RoleAdmin), zoneHandler.UploadZones)
+			protected.DELETE("/fleets/:id/zones/:zone_id", handler.RequireFleetRole(domain.RoleAdmin), zoneHandler.DeleteZone)
+			protected.PUT("/fleets/:id/zone-fares", handler.RequireFleetRole(domain.RoleAdmin), zoneHandler.SetZoneFares)
+			protected.GET("/fleets/:id/service-areas", handler.RequireFleetRole(domain.RoleDriver), serviceAreaHandler.ListServiceAreas)
+			protected.POST("/fleets/:id/service-areas", handler.RequireFleetRole(domain.RoleAdmin), serviceAreaHandler.UploadServiceAreas)
+			protected.DELETE("/fleets/:id/service-areas/:area_id", handler.RequireFleetRole(domain.RoleAdmin), serviceAreaHandler.DeleteServiceArea)
+			protected.GET("/fleets/:id/recurring-orders", handler.RequireFleetRole(domain.RoleDispatcher), recurringOrderHandler.ListRecurringOrders)
+			protected.POST("/fleets/:id/recurring-orders", handler.RequireFleetRole(domain.RoleDispatcher), recurringOrderHandler.CreateRecurringOrder)
+			protected.POST("/recurring-orders/:id/pause", recurringOrderHandler.PauseRecurringOrder)
+			protected.POST("/recurring-orders/:id/resume", recurringOrderHandler.ResumeRecurringOrder)
+			protected.PUT("/recurring-orders/:id/skips/:date", recurringOrderHandler.SkipDate)
+			protected.DELETE("/recurring-orders/:id/skips/:date", recurringOrderHandler.UnskipDate)
+			protected.GET("/recurring-orders/:id/occurrences", recurringOrderHandler.ListOccurrences)
+			protected.POST("/promo-codes", promoHandler.CreatePromoCode)
 
 			api.GET("/ws", func(c *gin.Context) {
 				websocket.ServeWs(hub, c)
@@ -121,5 +262,8 @@ func main() {
 		appLogger.Fatal("server forced to shutdown:", zap.Error(err))
 	}
 
+	stopLocationWriter()
+	<-locationWriterDone
+
 	appLogger.Info("server exiting")
 }
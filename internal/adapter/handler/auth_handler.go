@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
 	"github.com/vantutran2k1/flowfleet/internal/core/service"
 )
 
@@ -44,7 +45,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.svc.GenerateToken(driver.ID)
+	token, err := h.svc.GenerateToken(driver.ID, driver.FleetID, domain.Role(driver.Role))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
 		return
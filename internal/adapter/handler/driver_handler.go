@@ -1,11 +1,23 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/pkg/geo"
+)
+
+const (
+	defaultTrackWindow = time.Hour
+	maxTrackWindow     = 24 * time.Hour
+	maxTrackPoints     = 10000
 )
 
 type DriverHandler struct {
@@ -63,3 +75,169 @@ func (h *DriverHandler) CreateDriver(c *gin.Context) {
 		"status":     "success",
 	})
 }
+
+// CapacityRequest sets what a driver's vehicle can carry at once. Omitted
+// limits are unlimited; cold_chain marks a refrigerated vehicle and
+// max_item_length_cm the longest item it takes.
+type CapacityRequest struct {
+	Parcels         *int32 `json:"parcels" binding:"omitempty,min=1"`
+	WeightGrams     *int32 `json:"weight_grams" binding:"omitempty,min=1"`
+	VolumeCm3       *int32 `json:"volume_cm3" binding:"omitempty,min=1"`
+	ColdChain       bool   `json:"cold_chain"`
+	MaxItemLengthCm *int32 `json:"max_item_length_cm" binding:"omitempty,min=1"`
+}
+
+func (h *DriverHandler) UpdateCapacity(c *gin.Context) {
+	driverUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid driver id"})
+		return
+	}
+	if !h.authorizeDriver(c, driverUUID, false) {
+		return
+	}
+
+	var req CapacityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := h.store.UpdateDriverCapacity(c.Request.Context(), postgres.UpdateDriverCapacityParams{
+		ID:                  driverUUID,
+		CapacityParcels:     optionalInt4(req.Parcels),
+		CapacityWeightGrams: optionalInt4(req.WeightGrams),
+		CapacityVolumeCm3:   optionalInt4(req.VolumeCm3),
+		ColdChain:           req.ColdChain,
+		MaxItemLengthCm:     optionalInt4(req.MaxItemLengthCm),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update capacity"})
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrDriverNotFound.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (h *DriverHandler) GetEarnings(c *gin.Context) {
+	driverUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid driver id"})
+		return
+	}
+	if !h.authorizeDriver(c, driverUUID, true) {
+		return
+	}
+
+	rows, err := h.store.SumDriverEarnings(c.Request.Context(), driverUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load earnings"})
+		return
+	}
+
+	totals := make([]gin.H, 0, len(rows))
+	for _, row := range rows {
+		total := domain.NewMoney(row.TotalCents, row.Currency)
+		totals = append(totals, gin.H{
+			"total":       total,
+			"display":     total.String(),
+			"order_count": row.OrderCount,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"driver_id": driverUUID, "earnings": totals})
+}
+
+// GetTrack returns the driver's breadcrumbs between the from and to query
+// params (RFC 3339, defaulting to the last hour) as a GeoJSON LineString
+// feature.
+func (h *DriverHandler) GetTrack(c *gin.Context) {
+	driverUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid driver id"})
+		return
+	}
+	if !h.authorizeDriver(c, driverUUID, true) {
+		return
+	}
+
+	to := time.Now()
+	if raw := c.Query("to"); raw != "" {
+		if to, err = time.Parse(time.RFC3339, raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 timestamp"})
+			return
+		}
+	}
+	from := to.Add(-defaultTrackWindow)
+	if raw := c.Query("from"); raw != "" {
+		if from, err = time.Parse(time.RFC3339, raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 timestamp"})
+			return
+		}
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+	if to.Sub(from) > maxTrackWindow {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "time range must not exceed 24 hours"})
+		return
+	}
+
+	rows, err := h.store.ListDriverLocations(c.Request.Context(), postgres.ListDriverLocationsParams{
+		DriverID:     driverUUID,
+		RecordedAt:   from,
+		RecordedAt_2: to,
+		Limit:        maxTrackPoints,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load driver track"})
+		return
+	}
+
+	positions := make([][2]float64, 0, len(rows))
+	timestamps := make([]time.Time, 0, len(rows))
+	for _, row := range rows {
+		positions = append(positions, [2]float64{row.Lng, row.Lat})
+		timestamps = append(timestamps, row.RecordedAt)
+	}
+
+	properties, _ := json.Marshal(gin.H{
+		"driver_id":   driverUUID,
+		"from":        from,
+		"to":          to,
+		"point_count": len(rows),
+		"truncated":   len(rows) == maxTrackPoints,
+		"timestamps":  timestamps,
+	})
+
+	c.JSON(http.StatusOK, geo.Feature{
+		Type:       geo.TypeFeature,
+		Properties: properties,
+		Geometry:   geo.NewLineString(positions),
+	})
+}
+
+// authorizeDriver responds and returns false unless the caller dispatches
+// the driver's fleet or, when self is set, is the driver.
+func (h *DriverHandler) authorizeDriver(c *gin.Context, driverID uuid.UUID, self bool) bool {
+	if self && principalOf(c).Is(driverID.String()) {
+		return true
+	}
+
+	driver, err := h.store.GetDriver(c.Request.Context(), driverID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrDriverNotFound.Error()})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load driver"})
+		return false
+	}
+
+	return authorizeFleet(c, driver.FleetID.String(), domain.RoleDispatcher)
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+type FleetHandler struct {
+	store postgres.Store
+}
+
+func NewFleetHandler(store postgres.Store) *FleetHandler {
+	return &FleetHandler{store: store}
+}
+
+type PricingPolicyRequest struct {
+	Currency           string `json:"currency" binding:"required,iso4217"`
+	RoundingIncrement  int32  `json:"rounding_increment" binding:"required,min=1"`
+	RoundingMode       string `json:"rounding_mode" binding:"required,oneof=nearest up down"`
+	MinimumFareCents   int32  `json:"minimum_fare_cents" binding:"min=0"`
+	DriverSharePercent int32  `json:"driver_share_percent" binding:"min=0,max=100"`
+}
+
+func (h *FleetHandler) GetPricingPolicy(c *gin.Context) {
+	fleetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+
+	row, err := h.store.GetFleetPricingPolicy(c.Request.Context(), fleetUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrFleetNotFound.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load pricing policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"currency":             row.Currency,
+		"rounding_increment":   row.RoundingIncrement,
+		"rounding_mode":        row.RoundingMode,
+		"minimum_fare_cents":   row.MinimumFareCents,
+		"driver_share_percent": row.DriverSharePercent,
+	})
+}
+
+func (h *FleetHandler) UpdatePricingPolicy(c *gin.Context) {
+	fleetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+
+	var req PricingPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	currency, err := domain.LookupCurrency(req.Currency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := h.store.UpdateFleetPricingPolicy(c.Request.Context(), postgres.UpdateFleetPricingPolicyParams{
+		ID:                 fleetUUID,
+		Currency:           currency.Code,
+		RoundingIncrement:  req.RoundingIncrement,
+		RoundingMode:       postgres.RoundingMode(req.RoundingMode),
+		MinimumFareCents:   req.MinimumFareCents,
+		DriverSharePercent: req.DriverSharePercent,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update pricing policy"})
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrFleetNotFound.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+type ProofPolicyRequest struct {
+	Photo         bool `json:"photo"`
+	Signature     bool `json:"signature"`
+	RecipientName bool `json:"recipient_name"`
+	PIN           bool `json:"pin"`
+}
+
+// GetProofPolicy returns the proof of delivery the fleet's drivers must
+// collect.
+func (h *FleetHandler) GetProofPolicy(c *gin.Context) {
+	fleetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+
+	row, err := h.store.GetFleetProofPolicy(c.Request.Context(), fleetUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrFleetNotFound.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load proof policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.ProofRequirements{
+		Photo:         row.PodPhoto,
+		Signature:     row.PodSignature,
+		RecipientName: row.PodRecipientName,
+		PIN:           row.PodPin,
+	})
+}
+
+func (h *FleetHandler) UpdateProofPolicy(c *gin.Context) {
+	fleetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+
+	var req ProofPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := h.store.UpdateFleetProofPolicy(c.Request.Context(), postgres.UpdateFleetProofPolicyParams{
+		ID:               fleetUUID,
+		PodPhoto:         req.Photo,
+		PodSignature:     req.Signature,
+		PodRecipientName: req.RecipientName,
+		PodPin:           req.PIN,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update proof policy"})
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrFleetNotFound.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
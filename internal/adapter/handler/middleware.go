@@ -1,13 +1,18 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
 	"github.com/vantutran2k1/flowfleet/internal/core/service"
 )
 
+const principalKey = "principal"
+
 func AuthMiddleware(authSvc *service.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -22,13 +27,61 @@ func AuthMiddleware(authSvc *service.AuthService) gin.HandlerFunc {
 			return
 		}
 
-		userID, err := authSvc.ValidateToken(parts[1])
+		principal, err := authSvc.ValidateToken(parts[1])
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
 			return
 		}
 
-		c.Set("userID", userID)
+		c.Set("userID", uuid.MustParse(principal.DriverID))
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// RequireFleetRole only lets through callers who belong to the fleet in the
+// :id path param and hold at least role there.
+func RequireFleetRole(role domain.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !principalOf(c).Can(c.Param("id"), role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": domain.ErrForbidden.Error()})
+			return
+		}
 		c.Next()
 	}
 }
+
+// principalOf returns the caller authenticated by AuthMiddleware, or the
+// zero Principal, which may do nothing, on unauthenticated routes.
+func principalOf(c *gin.Context) domain.Principal {
+	principal, _ := c.Get(principalKey)
+	p, _ := principal.(domain.Principal)
+	return p
+}
+
+// authorizeFleet responds 403 and returns false unless the caller belongs to
+// fleetID and holds at least role there.
+func authorizeFleet(c *gin.Context, fleetID string, role domain.Role) bool {
+	if principalOf(c).Can(fleetID, role) {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": domain.ErrForbidden.Error()})
+	return false
+}
+
+// authorizeOrder responds and returns false unless the caller holds at least
+// role in the order's fleet or, when assignee is set, is its driver.
+func authorizeOrder(c *gin.Context, svc *service.DispatchService, orderID uuid.UUID, role domain.Role, assignee bool) bool {
+	err := svc.AuthorizeOrder(c.Request.Context(), principalOf(c), orderID, role, assignee)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, domain.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/core/service"
+)
+
+const (
+	defaultBreachWindow = 24 * time.Hour
+	maxBreachWindow     = 31 * 24 * time.Hour
+)
+
+type OpsHandler struct {
+	svc *service.DispatchService
+	hub *websocket.Hub
+}
+
+func NewOpsHandler(svc *service.DispatchService, hub *websocket.Hub) *OpsHandler {
+	return &OpsHandler{svc: svc, hub: hub}
+}
+
+// Feed upgrades to the fleet's dispatcher websocket. Optional query filters:
+// bbox=minLng,minLat,maxLng,maxLat, driver_status and order_status as
+// comma-separated lists.
+func (h *OpsHandler) Feed(c *gin.Context) {
+	fleetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+
+	filter, err := parseOpsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	websocket.ServeOps(h.hub, c, fleetUUID.String(), filter, func() (domain.OpsSnapshot, error) {
+		return h.svc.OpsSnapshot(c.Request.Context(), fleetUUID)
+	})
+}
+
+// SLABreaches lists the fleet's orders that missed their delivery deadline
+// between the from and to query params (RFC 3339, defaulting to the last
+// day).
+func (h *OpsHandler) SLABreaches(c *gin.Context) {
+	fleetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+
+	to := time.Now()
+	if raw := c.Query("to"); raw != "" {
+		if to, err = time.Parse(time.RFC3339, raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 timestamp"})
+			return
+		}
+	}
+	from := to.Add(-defaultBreachWindow)
+	if raw := c.Query("from"); raw != "" {
+		if from, err = time.Parse(time.RFC3339, raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 timestamp"})
+			return
+		}
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+	if to.Sub(from) > maxBreachWindow {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "window must not exceed 31 days"})
+		return
+	}
+
+	breaches, err := h.svc.ListSLABreaches(c.Request.Context(), fleetUUID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load SLA breaches"})
+		return
+	}
+
+	byPriority := make(map[domain.OrderPriority]int)
+	for _, breach := range breaches {
+		byPriority[breach.Priority]++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"fleet_id":    fleetUUID,
+		"from":        from,
+		"to":          to,
+		"total":       len(breaches),
+		"by_priority": byPriority,
+		"breaches":    breaches,
+	})
+}
+
+func parseOpsFilter(c *gin.Context) (domain.OpsFilter, error) {
+	var filter domain.OpsFilter
+
+	if raw := c.Query("bbox"); raw != "" {
+		parts := strings.Split(raw, ",")
+		if len(parts) != 4 {
+			return filter, errors.New("bbox must be minLng,minLat,maxLng,maxLat")
+		}
+
+		var v [4]float64
+		for i, p := range parts {
+			f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+			if err != nil {
+				return filter, errors.New("bbox must be minLng,minLat,maxLng,maxLat")
+			}
+			v[i] = f
+		}
+
+		bbox := domain.BoundingBox{MinLng: v[0], MinLat: v[1], MaxLng: v[2], MaxLat: v[3]}
+		if bbox.MinLat < -90 || bbox.MaxLat > 90 || bbox.MinLng < -180 || bbox.MaxLng > 180 ||
+			bbox.MinLat > bbox.MaxLat || bbox.MinLng > bbox.MaxLng {
+			return filter, errors.New("bbox is out of range or inverted")
+		}
+		filter.BBox = &bbox
+	}
+
+	if raw := c.Query("driver_status"); raw != "" {
+		filter.DriverStatuses = make(map[string]bool)
+		for _, status := range strings.Split(raw, ",") {
+			filter.DriverStatuses[strings.ToLower(strings.TrimSpace(status))] = true
+		}
+	}
+
+	if raw := c.Query("order_status"); raw != "" {
+		filter.OrderStatuses = make(map[domain.OrderStatus]bool)
+		for _, status := range strings.Split(raw, ",") {
+			filter.OrderStatuses[domain.OrderStatus(strings.ToLower(strings.TrimSpace(status)))] = true
+		}
+	}
+
+	return filter, nil
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -19,12 +22,124 @@ func NewOrderHandler(svc *service.DispatchService) *OrderHandler {
 	return &OrderHandler{svc: svc}
 }
 
+// TimeWindowRequest is when a stop may be served; either bound may be left
+// out.
+type TimeWindowRequest struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// AddressRequest is where a stop is, who to meet there and how to get in.
+type AddressRequest struct {
+	Line1        string `json:"line1" binding:"required,max=200"`
+	Line2        string `json:"line2" binding:"max=200"`
+	City         string `json:"city" binding:"required,max=100"`
+	Postcode     string `json:"postcode" binding:"max=20"`
+	ContactName  string `json:"contact_name" binding:"max=100"`
+	ContactPhone string `json:"contact_phone" binding:"max=32"`
+	AccessNotes  string `json:"access_notes" binding:"max=500"`
+	DoorCode     string `json:"door_code" binding:"max=32"`
+}
+
+type StopRequest struct {
+	Kind    string             `json:"kind" binding:"required,oneof=pickup dropoff"`
+	Lat     float64            `json:"lat" binding:"required,latitude"`
+	Lng     float64            `json:"lng" binding:"required,longitude"`
+	Window  *TimeWindowRequest `json:"window"`
+	Address *AddressRequest    `json:"address"`
+}
+
+// ItemRequest is one line of what an order carries. Weight, dimensions and
+// declared value are per unit.
+type ItemRequest struct {
+	Description        string `json:"description" binding:"required,max=200"`
+	Quantity           int    `json:"quantity" binding:"required,min=1,max=1000"`
+	WeightGrams        int    `json:"weight_grams" binding:"min=0,max=1000000"`
+	LengthCm           int    `json:"length_cm" binding:"min=0,max=10000"`
+	WidthCm            int    `json:"width_cm" binding:"min=0,max=10000"`
+	HeightCm           int    `json:"height_cm" binding:"min=0,max=10000"`
+	Fragile            bool   `json:"fragile"`
+	ColdChain          bool   `json:"cold_chain"`
+	DeclaredValueCents int    `json:"declared_value_cents" binding:"min=0,max=2147483647"`
+}
+
+// CreateOrderRequest takes either a single pickup and dropoff or an ordered
+// list of stops.
 type CreateOrderRequest struct {
-	FleetID    string  `json:"fleet_id" binding:"required,uuid"`
-	PickupLat  float64 `json:"pickup_lat" binding:"required"`
-	PickupLng  float64 `json:"pickup_lng" binding:"required"`
-	DropoffLat float64 `json:"dropoff_lat" binding:"required,latitude"`
-	DropoffLng float64 `json:"dropoff_lng" binding:"required,longitude"`
+	FleetID    string        `json:"fleet_id" binding:"required,uuid"`
+	PickupLat  float64       `json:"pickup_lat" binding:"required_without=Stops,latitude"`
+	PickupLng  float64       `json:"pickup_lng" binding:"required_without=Stops,longitude"`
+	DropoffLat float64       `json:"dropoff_lat" binding:"required_without=Stops,latitude"`
+	DropoffLng float64       `json:"dropoff_lng" binding:"required_without=Stops,longitude"`
+	Stops      []StopRequest `json:"stops" binding:"omitempty,min=2,max=20,dive"`
+	// when items are given the load is taken from them and parcel_count,
+	// weight_grams and volume_cm3 are ignored
+	Items []ItemRequest `json:"items" binding:"omitempty,max=100,dive"`
+	// the order is a single parcel unless parcel_count says otherwise
+	ParcelCount int    `json:"parcel_count" binding:"min=0,max=1000"`
+	WeightGrams int    `json:"weight_grams" binding:"min=0,max=1000000000"`
+	VolumeCm3   int    `json:"volume_cm3" binding:"min=0,max=1000000000"`
+	Vehicle     string `json:"vehicle" binding:"omitempty,oneof=BIKE VAN TRUCK"`
+	Notes       string `json:"notes" binding:"max=1000"`
+	// priority defaults to standard
+	Priority   string `json:"priority" binding:"omitempty,oneof=standard express critical"`
+	CustomerID string `json:"customer_id" binding:"max=128"`
+	PromoCode  string `json:"promo_code" binding:"max=64"`
+
+	// scheduled_pickup_at books the order for later; without it the order
+	// is dispatched right away
+	ScheduledPickupAt time.Time `json:"scheduled_pickup_at"`
+	// deliver_by sets a delivery deadline
+	DeliverBy time.Time `json:"deliver_by"`
+	// the windows restrict when the first and last stop may be served, and
+	// the addresses say where they are, unless the stop has its own
+	PickupWindow   *TimeWindowRequest `json:"pickup_window"`
+	DropoffWindow  *TimeWindowRequest `json:"dropoff_window"`
+	PickupAddress  *AddressRequest    `json:"pickup_address"`
+	DropoffAddress *AddressRequest    `json:"dropoff_address"`
+}
+
+// RescheduleOrderRequest changes an order that has not been released for
+// dispatch yet. Omitted fields are left as they were booked.
+type RescheduleOrderRequest struct {
+	ScheduledPickupAt time.Time `json:"scheduled_pickup_at"`
+	DeliverBy         time.Time `json:"deliver_by"`
+	Priority          string    `json:"priority" binding:"omitempty,oneof=standard express critical"`
+	Notes             *string   `json:"notes" binding:"omitempty,max=1000"`
+}
+
+func (w *TimeWindowRequest) timeWindow() *domain.TimeWindow {
+	if w == nil {
+		return nil
+	}
+	return &domain.TimeWindow{Start: w.Start, End: w.End}
+}
+
+func (a *AddressRequest) address() *domain.Address {
+	if a == nil {
+		return nil
+	}
+	return &domain.Address{
+		Line1:        strings.TrimSpace(a.Line1),
+		Line2:        strings.TrimSpace(a.Line2),
+		City:         strings.TrimSpace(a.City),
+		Postcode:     strings.TrimSpace(a.Postcode),
+		ContactName:  strings.TrimSpace(a.ContactName),
+		ContactPhone: strings.TrimSpace(a.ContactPhone),
+		AccessNotes:  strings.TrimSpace(a.AccessNotes),
+		DoorCode:     strings.TrimSpace(a.DoorCode),
+	}
+}
+
+var promoErrors = []error{
+	domain.ErrPromoNotFound,
+	domain.ErrPromoNotActive,
+	domain.ErrPromoNotApplicable,
+	domain.ErrPromoCurrency,
+	domain.ErrPromoMinFare,
+	domain.ErrPromoExhausted,
+	domain.ErrPromoCustomerRequired,
+	domain.ErrPromoCustomerLimit,
 }
 
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
@@ -36,20 +151,214 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 
 	fleetUUID, _ := uuid.Parse(req.FleetID)
 
-	orderID, err := h.svc.CreateAndDispatchOrder(
-		c.Request.Context(),
-		fleetUUID,
-		req.PickupLat,
-		req.PickupLng,
-		req.DropoffLat,
-		req.DropoffLng,
-	)
+	stops := make([]domain.Stop, len(req.Stops))
+	for i, stop := range req.Stops {
+		stops[i] = domain.Stop{
+			Kind:     domain.StopKind(stop.Kind),
+			Location: domain.Location{Lat: stop.Lat, Lng: stop.Lng},
+			Window:   stop.Window.timeWindow(),
+			Address:  stop.Address.address(),
+		}
+	}
+
+	items := make([]domain.Item, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = domain.Item{
+			Description:        item.Description,
+			Quantity:           item.Quantity,
+			WeightGrams:        item.WeightGrams,
+			LengthCm:           item.LengthCm,
+			WidthCm:            item.WidthCm,
+			HeightCm:           item.HeightCm,
+			Fragile:            item.Fragile,
+			ColdChain:          item.ColdChain,
+			DeclaredValueCents: item.DeclaredValueCents,
+		}
+	}
+
+	result, err := h.svc.CreateAndDispatchOrder(c.Request.Context(), service.CreateOrderInput{
+		FleetID:        fleetUUID,
+		Pickup:         domain.Location{Lat: req.PickupLat, Lng: req.PickupLng},
+		Dropoff:        domain.Location{Lat: req.DropoffLat, Lng: req.DropoffLng},
+		Stops:          stops,
+		PickupWindow:   req.PickupWindow.timeWindow(),
+		DropoffWindow:  req.DropoffWindow.timeWindow(),
+		PickupAddress:  req.PickupAddress.address(),
+		DropoffAddress: req.DropoffAddress.address(),
+		Items:          items,
+		Load: domain.Load{
+			Parcels:     req.ParcelCount,
+			WeightGrams: req.WeightGrams,
+			VolumeCm3:   req.VolumeCm3,
+		},
+		Vehicle:           domain.VehicleType(req.Vehicle),
+		Notes:             req.Notes,
+		Priority:          domain.OrderPriority(req.Priority),
+		DeliverBy:         req.DeliverBy,
+		ScheduledPickupAt: req.ScheduledPickupAt,
+		CustomerID:        req.CustomerID,
+		PromoCode:         req.PromoCode,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidStops) || errors.Is(err, domain.ErrInvalidSchedule) ||
+			errors.Is(err, domain.ErrInvalidPriority) || errors.Is(err, domain.ErrInvalidDeadline) ||
+			errors.Is(err, domain.ErrInvalidTimeWindow) || errors.Is(err, domain.ErrInvalidItems) ||
+			errors.Is(err, domain.ErrItemsTooLarge) || errors.Is(err, domain.ErrInvalidAddress) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, domain.ErrPickupOutsideServiceArea) || errors.Is(err, domain.ErrDropoffOutsideServiceArea) ||
+			errors.Is(err, domain.ErrNoRates) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		for _, promoErr := range promoErrors {
+			if errors.Is(err, promoErr) {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := "processing"
+	if result.Status == domain.OrderStatusScheduled {
+		status = string(result.Status)
+	}
+
+	c.JSON(201, gin.H{
+		"order_id":       result.OrderID,
+		"tracking_token": result.TrackingToken,
+		"delivery_pin":   result.DeliveryPIN,
+		"status":         status,
+	})
+}
+
+// RescheduleOrder changes the pickup time, deadline, priority or notes of
+// an order that has not been released for dispatch yet.
+func (h *OrderHandler) RescheduleOrder(c *gin.Context) {
+	orderUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid order id"})
+		return
+	}
+	if !authorizeOrder(c, h.svc, orderUUID, domain.RoleDispatcher, false) {
+		return
+	}
+
+	var req RescheduleOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.handleSchedule(c, h.svc.RescheduleOrder(c.Request.Context(), orderUUID, service.OrderChanges{
+		ScheduledPickupAt: req.ScheduledPickupAt,
+		DeliverBy:         req.DeliverBy,
+		Priority:          domain.OrderPriority(req.Priority),
+		Notes:             req.Notes,
+	}))
+}
+
+// CancelOrder cancels an order that has not been released for dispatch yet.
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	orderUUID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid order id"})
+		return
+	}
+	if !authorizeOrder(c, h.svc, orderUUID, domain.RoleDispatcher, false) {
+		return
+	}
+
+	h.handleSchedule(c, h.svc.CancelScheduledOrder(c.Request.Context(), orderUUID))
+}
+
+func (h *OrderHandler) handleSchedule(c *gin.Context, err error) {
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidSchedule) || errors.Is(err, domain.ErrInvalidDeadline) ||
+			errors.Is(err, domain.ErrInvalidPriority) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, domain.ErrOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, domain.ErrOrderNotScheduled) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(201, gin.H{"order_id": orderID, "status": "processing"})
+	c.JSON(200, gin.H{"status": "success"})
+}
+
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	orderUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid order id"})
+		return
+	}
+	if !authorizeOrder(c, h.svc, orderUUID, domain.RoleDispatcher, true) {
+		return
+	}
+
+	order, err := h.svc.GetOrder(c.Request.Context(), orderUUID)
+	if err != nil {
+		if errors.Is(err, domain.ErrOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// only the fleet's dispatchers and the assigned driver may contact the
+	// order's senders and recipients
+	if viewer := principalOf(c); !viewer.Can(order.FleetID, domain.RoleDispatcher) && !viewer.Is(order.DriverID) {
+		order.HideContacts()
+	}
+
+	c.JSON(200, orderResponse(order))
+}
+
+func orderResponse(order *domain.Order) gin.H {
+	resp := gin.H{
+		"id":         order.ID,
+		"fleet_id":   order.FleetID,
+		"driver_id":  order.DriverID,
+		"status":     order.Status,
+		"pickup":     gin.H{"lat": order.Pickup.Lat, "lng": order.Pickup.Lng},
+		"dropoff":    gin.H{"lat": order.Dropoff.Lat, "lng": order.Dropoff.Lng},
+		"stops":      order.Stops,
+		"load":       order.Load,
+		"items":      order.Items,
+		"vehicle":    order.Vehicle,
+		"notes":      order.Notes,
+		"priority":   order.Priority,
+		"fare":       order.Fare,
+		"eta":        order.ETA,
+		"created_at": order.CreatedAt,
+		"updated_at": order.UpdatedAt,
+	}
+	if !order.ScheduledPickupAt.IsZero() {
+		resp["scheduled_pickup_at"] = order.ScheduledPickupAt
+	}
+	if order.SLA != nil {
+		resp["sla"] = order.SLA
+	}
+	if order.RecurringOrderID != "" {
+		resp["recurring_order_id"] = order.RecurringOrderID
+	}
+
+	return resp
 }
 
 func (h *OrderHandler) ArriveAtPickup(c *gin.Context) {
@@ -64,6 +373,26 @@ func (h *OrderHandler) CompleteOrder(c *gin.Context) {
 	h.handleTransition(c, h.svc.CompleteOrder)
 }
 
+func (h *OrderHandler) ArriveAtStop(c *gin.Context) {
+	h.handleStopTransition(c, h.svc.ArriveAtStop)
+}
+
+func (h *OrderHandler) CompleteStop(c *gin.Context) {
+	h.handleStopTransition(c, h.svc.CompleteStop)
+}
+
+func (h *OrderHandler) handleStopTransition(c *gin.Context, fn func(context.Context, uuid.UUID, uuid.UUID, int) error) {
+	position, err := strconv.Atoi(c.Param("position"))
+	if err != nil || position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stop position"})
+		return
+	}
+
+	h.handleTransition(c, func(ctx context.Context, driverID, orderID uuid.UUID) error {
+		return fn(ctx, driverID, orderID, position)
+	})
+}
+
 func (h *OrderHandler) handleTransition(c *gin.Context, fn func(context.Context, uuid.UUID, uuid.UUID) error) {
 	orderIDStr := c.Param("id")
 	orderUUID, err := uuid.Parse(orderIDStr)
@@ -84,6 +413,22 @@ func (h *OrderHandler) handleTransition(c *gin.Context, fn func(context.Context,
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid state transition, check if order is in correct status"})
 			return
 		}
+		if errors.Is(err, domain.ErrTooFarFromStop) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, domain.ErrProofIncomplete) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, domain.ErrStopWindowNotOpen) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, domain.ErrOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
@@ -91,3 +436,74 @@ func (h *OrderHandler) handleTransition(c *gin.Context, fn func(context.Context,
 
 	c.JSON(200, gin.H{"status": "success"})
 }
+
+// FailDeliveryRequest reports a dropoff the driver could not complete.
+// Retry asks for another attempt later instead of returning the order.
+type FailDeliveryRequest struct {
+	Reason string `json:"reason" binding:"required,oneof=recipient_absent address_not_found refused access_denied damaged other"`
+	Notes  string `json:"notes" binding:"max=500"`
+	Retry  bool   `json:"retry"`
+}
+
+func (h *OrderHandler) FailDelivery(c *gin.Context) {
+	orderUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	var req FailDeliveryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	driverID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	result, err := h.svc.FailDelivery(c.Request.Context(), driverID.(uuid.UUID), orderUUID, service.FailDeliveryInput{
+		Reason: domain.FailureReason(req.Reason),
+		Notes:  req.Notes,
+		Retry:  req.Retry,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidFailureReason):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidTransition), errors.Is(err, domain.ErrNotAtDropoff):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(500, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+func (h *OrderHandler) DeliveryAttempts(c *gin.Context) {
+	orderUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid order id"})
+		return
+	}
+	if !authorizeOrder(c, h.svc, orderUUID, domain.RoleDispatcher, true) {
+		return
+	}
+
+	attempts, err := h.svc.DeliveryAttempts(c.Request.Context(), orderUUID)
+	if err != nil {
+		if errors.Is(err, domain.ErrOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"attempts": attempts})
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/core/service"
+)
+
+// orderStore serves a single order and its delivery attempts; any other
+// query panics.
+type orderStore struct {
+	postgres.Store
+	order postgres.GetOrderRow
+}
+
+func (s orderStore) GetOrder(ctx context.Context, id uuid.UUID) (postgres.GetOrderRow, error) {
+	return s.order, nil
+}
+
+func (s orderStore) ListDeliveryAttempts(ctx context.Context, orderID uuid.UUID) ([]postgres.ListDeliveryAttemptsRow, error) {
+	return []postgres.ListDeliveryAttemptsRow{}, nil
+}
+
+func TestOrderHandler_DeliveryAttempts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	fleetID, driverID := uuid.New(), uuid.New()
+	order := postgres.GetOrderRow{ID: uuid.New(), FleetID: fleetID, DriverID: pgtype.UUID{Bytes: driverID, Valid: true}}
+	h := NewOrderHandler(service.NewDispatchService(orderStore{order: order}, nil, &websocket.Hub{}))
+
+	tests := []struct {
+		name      string
+		principal domain.Principal
+		want      int
+	}{
+		{"Dispatcher Of Fleet", domain.Principal{DriverID: uuid.NewString(), FleetID: fleetID.String(), Role: domain.RoleDispatcher}, http.StatusOK},
+		{"Assigned Driver", domain.Principal{DriverID: driverID.String(), FleetID: fleetID.String(), Role: domain.RoleDriver}, http.StatusOK},
+		{"Other Driver", domain.Principal{DriverID: uuid.NewString(), FleetID: fleetID.String(), Role: domain.RoleDriver}, http.StatusForbidden},
+		{"Other Fleet", domain.Principal{DriverID: uuid.NewString(), FleetID: uuid.NewString(), Role: domain.RoleAdmin}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/orders/:id/delivery-attempts", func(c *gin.Context) {
+				c.Set(principalKey, tt.principal)
+			}, h.DeliveryAttempts)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders/"+order.ID.String()+"/delivery-attempts", nil))
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/core/service/pricing"
+)
+
+const pgUniqueViolation = "23505"
+
+type PromoHandler struct {
+	store postgres.Store
+}
+
+func NewPromoHandler(store postgres.Store) *PromoHandler {
+	return &PromoHandler{store: store}
+}
+
+// CreatePromoCodeRequest describes a promo code of the caller's fleet, or of
+// fleet_id, which the caller must administer. Its amounts are in the minor
+// unit of the fleet's currency. Promos valid in every fleet cannot be created
+// through the API.
+type CreatePromoCodeRequest struct {
+	Code             string     `json:"code" binding:"required,alphanum,max=64"`
+	FleetID          string     `json:"fleet_id" binding:"omitempty,uuid"`
+	Kind             string     `json:"kind" binding:"required,oneof=percentage fixed"`
+	Value            int32      `json:"value" binding:"required,min=1"`
+	MinFareCents     int32      `json:"min_fare_cents" binding:"min=0"`
+	MaxDiscountCents *int32     `json:"max_discount_cents" binding:"omitempty,min=1"`
+	MaxRedemptions   *int32     `json:"max_redemptions" binding:"omitempty,min=1"`
+	MaxPerCustomer   *int32     `json:"max_per_customer" binding:"omitempty,min=1"`
+	StartsAt         *time.Time `json:"starts_at"`
+	EndsAt           *time.Time `json:"ends_at"`
+}
+
+func (h *PromoHandler) CreatePromoCode(c *gin.Context) {
+	var req CreatePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fleetID := principalOf(c).FleetID
+	if req.FleetID != "" {
+		fleetID = req.FleetID
+	}
+	if !authorizeFleet(c, fleetID, domain.RoleAdmin) {
+		return
+	}
+	fleetUUID, err := uuid.Parse(fleetID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+	policy, err := h.store.GetFleetPricingPolicy(c.Request.Context(), fleetUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrFleetNotFound.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load fleet currency"})
+		return
+	}
+
+	if req.Kind == string(postgres.PromoKindPercentage) && req.Value > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "percentage value must be between 1 and 100"})
+		return
+	}
+
+	startsAt := time.Now()
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil && !req.EndsAt.After(startsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be after starts_at"})
+		return
+	}
+
+	params := postgres.CreatePromoCodeParams{
+		Code:             pricing.NormalizePromoCode(req.Code),
+		FleetID:          pgtype.UUID{Bytes: fleetUUID, Valid: true},
+		Kind:             postgres.PromoKind(req.Kind),
+		Value:            req.Value,
+		MinFareCents:     req.MinFareCents,
+		MaxDiscountCents: optionalInt4(req.MaxDiscountCents),
+		MaxRedemptions:   optionalInt4(req.MaxRedemptions),
+		MaxPerCustomer:   optionalInt4(req.MaxPerCustomer),
+		StartsAt:         startsAt,
+		Currency:         pgtype.Text{String: policy.Currency, Valid: true},
+	}
+	if req.EndsAt != nil {
+		params.EndsAt = pgtype.Timestamptz{Time: *req.EndsAt, Valid: true}
+	}
+
+	promo, err := h.store.CreatePromoCode(c.Request.Context(), params)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			c.JSON(http.StatusConflict, gin.H{"error": "promo code already exists"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create promo code"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":         promo.ID,
+		"code":       params.Code,
+		"created_at": promo.CreatedAt,
+	})
+}
+
+func optionalInt4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/core/service"
+)
+
+// maxProofUploadBytes caps a proof of delivery upload, both images included.
+const maxProofUploadBytes = 10 << 20
+
+type ProofHandler struct {
+	svc *service.DispatchService
+}
+
+func NewProofHandler(svc *service.DispatchService) *ProofHandler {
+	return &ProofHandler{svc: svc}
+}
+
+// SubmitProof takes a multipart form with any of a photo and a signature
+// image (JPEG or PNG), the recipient_name and the recipient's pin.
+func (h *ProofHandler) SubmitProof(c *gin.Context) {
+	orderUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+	driverID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxProofUploadBytes)
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "proof must be a multipart form of at most 10MB"})
+		return
+	}
+	defer form.RemoveAll()
+
+	photo, err := openFormFile(form, "photo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if photo != nil {
+		defer photo.Close()
+	}
+	signature, err := openFormFile(form, "signature")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if signature != nil {
+		defer signature.Close()
+	}
+
+	input := service.SubmitProofInput{
+		RecipientName: formValue(form, "recipient_name"),
+		PIN:           formValue(form, "pin"),
+	}
+	if len(input.RecipientName) > 128 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "recipient_name must be at most 128 characters"})
+		return
+	}
+	if photo != nil {
+		input.Photo = photo
+	}
+	if signature != nil {
+		input.Signature = signature
+	}
+
+	proof, err := h.svc.SubmitProof(c.Request.Context(), driverID.(uuid.UUID), orderUUID, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, proof)
+}
+
+func (h *ProofHandler) GetProof(c *gin.Context) {
+	orderUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+	if !authorizeOrder(c, h.svc, orderUUID, domain.RoleDispatcher, true) {
+		return
+	}
+
+	proof, err := h.svc.GetProof(c.Request.Context(), orderUUID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, proof)
+}
+
+// GetProofImage serves the order's proof :kind, photo or signature.
+func (h *ProofHandler) GetProofImage(c *gin.Context) {
+	orderUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+	kind := c.Param("kind")
+	if kind != "photo" && kind != "signature" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be photo or signature"})
+		return
+	}
+	if !authorizeOrder(c, h.svc, orderUUID, domain.RoleDispatcher, true) {
+		return
+	}
+
+	body, key, err := h.svc.OpenProofImage(c.Request.Context(), orderUUID, kind)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	defer body.Close()
+
+	contentType := "image/jpeg"
+	if path.Ext(key) == ".png" {
+		contentType = "image/png"
+	}
+	c.DataFromReader(http.StatusOK, -1, contentType, body, nil)
+}
+
+func (h *ProofHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidProofImage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidDeliveryPIN):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDeliveryPINLocked):
+		c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidTransition):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "proof can only be collected for a picked up order assigned to you"})
+	case errors.Is(err, domain.ErrOrderNotFound), errors.Is(err, domain.ErrProofNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// openFormFile opens the form's file under field, or returns nil when there
+// is none.
+func openFormFile(form *multipart.Form, field string) (multipart.File, error) {
+	files := form.File[field]
+	if len(files) == 0 {
+		return nil, nil
+	}
+	if len(files) > 1 {
+		return nil, errors.New("only one " + field + " may be uploaded")
+	}
+	return files[0].Open()
+}
+
+func formValue(form *multipart.Form, field string) string {
+	if values := form.Value[field]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/core/service"
+)
+
+const (
+	defaultOccurrenceLimit = 10
+	maxOccurrenceLimit     = 100
+)
+
+type RecurringOrderHandler struct {
+	svc *service.DispatchService
+}
+
+func NewRecurringOrderHandler(svc *service.DispatchService) *RecurringOrderHandler {
+	return &RecurringOrderHandler{svc: svc}
+}
+
+// CreateRecurringOrderRequest describes a trip booked on a cron schedule,
+// e.g. "30 8 * * 1-5" for 08:30 on weekdays, in time_zone (UTC if empty).
+type CreateRecurringOrderRequest struct {
+	Name       string  `json:"name" binding:"required,max=128"`
+	Schedule   string  `json:"schedule" binding:"required,max=128"`
+	TimeZone   string  `json:"time_zone" binding:"max=64"`
+	PickupLat  float64 `json:"pickup_lat" binding:"required,latitude"`
+	PickupLng  float64 `json:"pickup_lng" binding:"required,longitude"`
+	DropoffLat float64 `json:"dropoff_lat" binding:"required,latitude"`
+	DropoffLng float64 `json:"dropoff_lng" binding:"required,longitude"`
+	Vehicle    string  `json:"vehicle" binding:"omitempty,oneof=BIKE VAN TRUCK"`
+	Notes      string  `json:"notes" binding:"max=1000"`
+	CustomerID string  `json:"customer_id" binding:"max=128"`
+}
+
+func (h *RecurringOrderHandler) CreateRecurringOrder(c *gin.Context) {
+	fleetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+
+	var req CreateRecurringOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := h.svc.CreateRecurringOrder(c.Request.Context(), service.CreateRecurringOrderInput{
+		FleetID:    fleetUUID,
+		CustomerID: req.CustomerID,
+		Name:       req.Name,
+		Schedule:   req.Schedule,
+		TimeZone:   req.TimeZone,
+		Pickup:     domain.Location{Lat: req.PickupLat, Lng: req.PickupLng},
+		Dropoff:    domain.Location{Lat: req.DropoffLat, Lng: req.DropoffLng},
+		Vehicle:    domain.VehicleType(req.Vehicle),
+		Notes:      req.Notes,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *RecurringOrderHandler) ListRecurringOrders(c *gin.Context) {
+	fleetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+
+	templates, err := h.svc.ListRecurringOrders(c.Request.Context(), fleetUUID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	resp := make([]gin.H, len(templates))
+	for i, template := range templates {
+		resp[i] = recurringOrderResponse(template)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recurring_orders": resp})
+}
+
+func (h *RecurringOrderHandler) PauseRecurringOrder(c *gin.Context) {
+	id, ok := h.recurringOrderID(c)
+	if !ok {
+		return
+	}
+
+	h.handleResult(c, h.svc.PauseRecurringOrder(c.Request.Context(), id))
+}
+
+func (h *RecurringOrderHandler) ResumeRecurringOrder(c *gin.Context) {
+	id, ok := h.recurringOrderID(c)
+	if !ok {
+		return
+	}
+
+	h.handleResult(c, h.svc.ResumeRecurringOrder(c.Request.Context(), id))
+}
+
+// SkipDate stops the template from firing on the :date (YYYY-MM-DD) in its
+// time zone.
+func (h *RecurringOrderHandler) SkipDate(c *gin.Context) {
+	id, ok := h.recurringOrderID(c)
+	if !ok {
+		return
+	}
+	date, ok := skipDate(c)
+	if !ok {
+		return
+	}
+
+	h.handleResult(c, h.svc.SkipRecurringDate(c.Request.Context(), id, date))
+}
+
+func (h *RecurringOrderHandler) UnskipDate(c *gin.Context) {
+	id, ok := h.recurringOrderID(c)
+	if !ok {
+		return
+	}
+	date, ok := skipDate(c)
+	if !ok {
+		return
+	}
+
+	h.handleResult(c, h.svc.UnskipRecurringDate(c.Request.Context(), id, date))
+}
+
+// ListOccurrences lists the next times the template fires, up to ?limit=.
+func (h *RecurringOrderHandler) ListOccurrences(c *gin.Context) {
+	id, ok := h.recurringOrderID(c)
+	if !ok {
+		return
+	}
+
+	limit := defaultOccurrenceLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxOccurrenceLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+		limit = n
+	}
+
+	occurrences, err := h.svc.UpcomingOccurrences(c.Request.Context(), id, limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if occurrences == nil {
+		occurrences = []domain.Occurrence{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"occurrences": occurrences})
+}
+
+// recurringOrderID parses the :id param and responds and returns false
+// unless the caller dispatches the template's fleet.
+func (h *RecurringOrderHandler) recurringOrderID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recurring order id"})
+		return uuid.Nil, false
+	}
+
+	template, err := h.svc.GetRecurringOrder(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return uuid.Nil, false
+	}
+	if !authorizeFleet(c, template.FleetID, domain.RoleDispatcher) {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func skipDate(c *gin.Context) (time.Time, bool) {
+	date, err := time.Parse(time.DateOnly, c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must be YYYY-MM-DD"})
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func (h *RecurringOrderHandler) handleResult(c *gin.Context, err error) {
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (h *RecurringOrderHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidRecurrence):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrRecurringOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func recurringOrderResponse(template *domain.RecurringOrder) gin.H {
+	skips := make([]string, 0, len(template.Skips))
+	for date := range template.Skips {
+		skips = append(skips, date)
+	}
+	slices.Sort(skips)
+
+	return gin.H{
+		"id":          template.ID,
+		"fleet_id":    template.FleetID,
+		"customer_id": template.CustomerID,
+		"name":        template.Name,
+		"schedule":    template.Schedule.String(),
+		"time_zone":   template.Location.String(),
+		"pickup":      gin.H{"lat": template.Pickup.Lat, "lng": template.Pickup.Lng},
+		"dropoff":     gin.H{"lat": template.Dropoff.Lat, "lng": template.Dropoff.Lng},
+		"vehicle":     template.Vehicle,
+		"notes":       template.Notes,
+		"paused":      template.Paused,
+		"skip_dates":  skips,
+	}
+}
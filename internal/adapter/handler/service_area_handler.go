@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/pkg/geo"
+)
+
+type ServiceAreaHandler struct {
+	store postgres.Store
+}
+
+func NewServiceAreaHandler(store postgres.Store) *ServiceAreaHandler {
+	return &ServiceAreaHandler{store: store}
+}
+
+type serviceAreaProperties struct {
+	Name string `json:"name"`
+}
+
+// UploadServiceAreas creates or replaces (by name) the fleet's service areas
+// from a GeoJSON FeatureCollection of Polygon/MultiPolygon features. Once a
+// fleet has any, orders must start and end inside one of them.
+func (h *ServiceAreaHandler) UploadServiceAreas(c *gin.Context) {
+	fleetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+
+	var fc geo.FeatureCollection
+	if err := c.ShouldBindJSON(&fc); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if fc.Type != geo.TypeFeatureCollection || len(fc.Features) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expected a non-empty FeatureCollection"})
+		return
+	}
+
+	params := make([]postgres.UpsertServiceAreaParams, 0, len(fc.Features))
+	for i, f := range fc.Features {
+		var props serviceAreaProperties
+		if err := json.Unmarshal(f.Properties, &props); err != nil || props.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "feature properties must include a name", "feature": i})
+			return
+		}
+		if !geo.IsPolygonal(f.Geometry) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "geometry must be a Polygon or MultiPolygon", "feature": i})
+			return
+		}
+
+		params = append(params, postgres.UpsertServiceAreaParams{
+			FleetID:           fleetUUID,
+			Name:              props.Name,
+			StGeomfromgeojson: string(f.Geometry),
+		})
+	}
+
+	areas := make([]gin.H, 0, len(params))
+	if err := h.store.ExecTx(c.Request.Context(), func(q postgres.Querier) error {
+		for _, p := range params {
+			area, err := q.UpsertServiceArea(c.Request.Context(), p)
+			if err != nil {
+				return err
+			}
+			areas = append(areas, gin.H{"id": area.ID, "name": p.Name})
+		}
+		return nil
+	}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to save service areas, check that geometries are valid"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"service_areas": areas})
+}
+
+func (h *ServiceAreaHandler) ListServiceAreas(c *gin.Context) {
+	fleetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+
+	rows, err := h.store.ListServiceAreas(c.Request.Context(), fleetUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list service areas"})
+		return
+	}
+
+	features := make([]geo.Feature, 0, len(rows))
+	for _, row := range rows {
+		geometry, _ := row.Geojson.(string)
+		props, _ := json.Marshal(gin.H{"id": row.ID, "name": row.Name})
+		features = append(features, geo.Feature{
+			Type:       geo.TypeFeature,
+			Properties: props,
+			Geometry:   json.RawMessage(geometry),
+		})
+	}
+
+	c.JSON(http.StatusOK, geo.NewFeatureCollection(features))
+}
+
+func (h *ServiceAreaHandler) DeleteServiceArea(c *gin.Context) {
+	fleetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+	areaUUID, err := uuid.Parse(c.Param("area_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid service area id"})
+		return
+	}
+
+	rows, err := h.store.DeleteServiceArea(c.Request.Context(), postgres.DeleteServiceAreaParams{
+		ID:      areaUUID,
+		FleetID: fleetUUID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete service area"})
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "service area not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/core/service"
+)
+
+type TrackingHandler struct {
+	svc *service.DispatchService
+	hub *websocket.Hub
+}
+
+func NewTrackingHandler(svc *service.DispatchService, hub *websocket.Hub) *TrackingHandler {
+	return &TrackingHandler{svc: svc, hub: hub}
+}
+
+// TrackOrder upgrades to a websocket streaming the order's status changes,
+// ETA and driver position. Browsers cannot set headers on websocket requests,
+// so the tracking token is also accepted as the token query parameter.
+func (h *TrackingHandler) TrackOrder(c *gin.Context) {
+	orderUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	token := c.GetHeader("X-Tracking-Token")
+	if token == "" {
+		token = c.Query("token")
+	}
+
+	order, err := h.svc.AuthorizeTracking(c.Request.Context(), orderUUID, token)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrOrderNotFound), errors.Is(err, domain.ErrInvalidTrackingToken):
+			// unknown orders and bad tokens look the same to the caller
+			c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrOrderNotFound.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if !order.Status.IsActive() {
+		c.JSON(http.StatusGone, gin.H{"error": "order is no longer active", "status": order.Status})
+		return
+	}
+
+	snapshot := gin.H{
+		"event":      "ORDER_SNAPSHOT",
+		"order_id":   order.ID,
+		"status":     order.Status,
+		"pickup":     gin.H{"lat": order.Pickup.Lat, "lng": order.Pickup.Lng},
+		"dropoff":    gin.H{"lat": order.Dropoff.Lat, "lng": order.Dropoff.Lng},
+		"eta":        order.ETA,
+		"has_driver": order.DriverID != "",
+	}
+
+	websocket.ServeTracking(h.hub, c, order.ID, snapshot, func() bool {
+		current, err := h.svc.AuthorizeTracking(c.Request.Context(), orderUUID, token)
+		return err == nil && current.Status.IsActive()
+	})
+}
+
+type CreateTrackingLinkRequest struct {
+	TTLMinutes int `json:"ttl_minutes" binding:"min=0"`
+}
+
+func (h *TrackingHandler) CreateTrackingLink(c *gin.Context) {
+	orderUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	if !authorizeOrder(c, h.svc, orderUUID, domain.RoleDispatcher, false) {
+		return
+	}
+
+	var req CreateTrackingLinkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	link, token, err := h.svc.CreateTrackingLink(c.Request.Context(), orderUUID, time.Duration(req.TTLMinutes)*time.Minute)
+	if err != nil {
+		if errors.Is(err, domain.ErrOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":         link.ID,
+		"token":      token,
+		"path":       "/api/v1/track/" + token,
+		"expires_at": link.ExpiresAt,
+	})
+}
+
+func (h *TrackingHandler) RevokeTrackingLink(c *gin.Context) {
+	orderUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+	linkUUID, err := uuid.Parse(c.Param("link_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid link id"})
+		return
+	}
+
+	if !authorizeOrder(c, h.svc, orderUUID, domain.RoleDispatcher, false) {
+		return
+	}
+
+	if err := h.svc.RevokeTrackingLink(c.Request.Context(), orderUUID, linkUUID); err != nil {
+		if errors.Is(err, domain.ErrTrackingLinkNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// PublicTracking is the unauthenticated view behind a tracking link.
+func (h *TrackingHandler) PublicTracking(c *gin.Context) {
+	view, err := h.svc.GetPublicTracking(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTrackingLinkNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrTrackingLinkExpired):
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, view)
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/pkg/geo"
+)
+
+type ZoneHandler struct {
+	store postgres.Store
+}
+
+func NewZoneHandler(store postgres.Store) *ZoneHandler {
+	return &ZoneHandler{store: store}
+}
+
+type zoneProperties struct {
+	Name           string `json:"name"`
+	SurchargeCents int32  `json:"surcharge_cents"`
+}
+
+// UploadZones creates or replaces (by name) the fleet's pricing zones from a
+// GeoJSON FeatureCollection of Polygon/MultiPolygon features.
+func (h *ZoneHandler) UploadZones(c *gin.Context) {
+	fleetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+
+	var fc geo.FeatureCollection
+	if err := c.ShouldBindJSON(&fc); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if fc.Type != geo.TypeFeatureCollection || len(fc.Features) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expected a non-empty FeatureCollection"})
+		return
+	}
+
+	params := make([]postgres.UpsertPricingZoneParams, 0, len(fc.Features))
+	for i, f := range fc.Features {
+		var props zoneProperties
+		if err := json.Unmarshal(f.Properties, &props); err != nil || props.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "feature properties must include a name", "feature": i})
+			return
+		}
+		if props.SurchargeCents < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "surcharge_cents must not be negative", "feature": i})
+			return
+		}
+		if !geo.IsPolygonal(f.Geometry) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "geometry must be a Polygon or MultiPolygon", "feature": i})
+			return
+		}
+
+		params = append(params, postgres.UpsertPricingZoneParams{
+			FleetID:           fleetUUID,
+			Name:              props.Name,
+			SurchargeCents:    props.SurchargeCents,
+			StGeomfromgeojson: string(f.Geometry),
+		})
+	}
+
+	zones := make([]gin.H, 0, len(params))
+	if err := h.store.ExecTx(c.Request.Context(), func(q postgres.Querier) error {
+		for _, p := range params {
+			zone, err := q.UpsertPricingZone(c.Request.Context(), p)
+			if err != nil {
+				return err
+			}
+			zones = append(zones, gin.H{"id": zone.ID, "name": p.Name})
+		}
+		return nil
+	}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to save zones, check that geometries are valid"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"zones": zones})
+}
+
+func (h *ZoneHandler) ListZones(c *gin.Context) {
+	fleetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+
+	rows, err := h.store.ListPricingZones(c.Request.Context(), fleetUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list zones"})
+		return
+	}
+
+	features := make([]geo.Feature, 0, len(rows))
+	for _, row := range rows {
+		geometry, _ := row.Geojson.(string)
+		props, _ := json.Marshal(gin.H{
+			"id":              row.ID,
+			"name":            row.Name,
+			"surcharge_cents": row.SurchargeCents,
+		})
+		features = append(features, geo.Feature{
+			Type:       geo.TypeFeature,
+			Properties: props,
+			Geometry:   json.RawMessage(geometry),
+		})
+	}
+
+	c.JSON(http.StatusOK, geo.NewFeatureCollection(features))
+}
+
+func (h *ZoneHandler) DeleteZone(c *gin.Context) {
+	fleetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+	zoneUUID, err := uuid.Parse(c.Param("zone_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid zone id"})
+		return
+	}
+
+	rows, err := h.store.DeletePricingZone(c.Request.Context(), postgres.DeletePricingZoneParams{
+		ID:      zoneUUID,
+		FleetID: fleetUUID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete zone"})
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "zone not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+type ZoneFareRequest struct {
+	FromZoneID  string `json:"from_zone_id" binding:"required,uuid"`
+	ToZoneID    string `json:"to_zone_id" binding:"required,uuid"`
+	VehicleType string `json:"vehicle_type" binding:"required,oneof=BIKE VAN TRUCK"`
+	FareCents   int32  `json:"fare_cents" binding:"min=0"`
+}
+
+type SetZoneFaresRequest struct {
+	Fares []ZoneFareRequest `json:"fares" binding:"required,min=1,dive"`
+}
+
+var errForeignZone = errors.New("zone does not belong to fleet")
+
+func (h *ZoneHandler) SetZoneFares(c *gin.Context) {
+	fleetUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fleet id"})
+		return
+	}
+
+	var req SetZoneFaresRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.store.ExecTx(c.Request.Context(), func(q postgres.Querier) error {
+		zones, err := q.ListPricingZones(c.Request.Context(), fleetUUID)
+		if err != nil {
+			return err
+		}
+		owned := make(map[uuid.UUID]bool, len(zones))
+		for _, z := range zones {
+			owned[z.ID] = true
+		}
+
+		for _, f := range req.Fares {
+			fromUUID, _ := uuid.Parse(f.FromZoneID)
+			toUUID, _ := uuid.Parse(f.ToZoneID)
+			if !owned[fromUUID] || !owned[toUUID] {
+				return errForeignZone
+			}
+
+			if err := q.UpsertZoneFare(c.Request.Context(), postgres.UpsertZoneFareParams{
+				FromZoneID:  fromUUID,
+				ToZoneID:    toUUID,
+				VehicleType: f.VehicleType,
+				FareCents:   f.FareCents,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		if errors.Is(err, errForeignZone) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save zone fares"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
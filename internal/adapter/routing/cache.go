@@ -0,0 +1,84 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/core/port"
+)
+
+type cacheEntry struct {
+	route     domain.Route
+	expiresAt time.Time
+}
+
+// CachedProvider memoizes routes in memory. Coordinates are rounded to five
+// decimals (about one meter) so repeated lookups for the same points hit.
+type CachedProvider struct {
+	next       port.RoutingProvider
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func NewCachedProvider(next port.RoutingProvider, ttl time.Duration, maxEntries int) *CachedProvider {
+	return &CachedProvider{
+		next:       next,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[string]cacheEntry),
+	}
+}
+
+func cacheKey(from, to domain.Location) string {
+	return fmt.Sprintf("%.5f,%.5f;%.5f,%.5f", from.Lat, from.Lng, to.Lat, to.Lng)
+}
+
+func (p *CachedProvider) Route(ctx context.Context, from, to domain.Location) (domain.Route, error) {
+	key := cacheKey(from, to)
+	now := p.now()
+
+	p.mu.Lock()
+	entry, ok := p.entries[key]
+	p.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.route, nil
+	}
+
+	route, err := p.next.Route(ctx, from, to)
+	if err != nil {
+		return domain.Route{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.maxEntries > 0 && len(p.entries) >= p.maxEntries {
+		p.evict(now)
+	}
+	p.entries[key] = cacheEntry{route: route, expiresAt: now.Add(p.ttl)}
+
+	return route, nil
+}
+
+// evict drops expired entries and, if the cache is still full, arbitrary ones
+// until there is room. Callers must hold mu.
+func (p *CachedProvider) evict(now time.Time) {
+	for k, e := range p.entries {
+		if !now.Before(e.expiresAt) {
+			delete(p.entries, k)
+		}
+	}
+	for k := range p.entries {
+		if len(p.entries) < p.maxEntries {
+			break
+		}
+		delete(p.entries, k)
+	}
+}
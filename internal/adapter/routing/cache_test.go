@@ -0,0 +1,60 @@
+package routing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+type countingProvider struct {
+	calls int
+}
+
+func (p *countingProvider) Route(ctx context.Context, from, to domain.Location) (domain.Route, error) {
+	p.calls++
+	return domain.Route{DistanceMeters: float64(p.calls)}, nil
+}
+
+func TestCachedProvider_Route(t *testing.T) {
+	next := &countingProvider{}
+	provider := NewCachedProvider(next, time.Minute, 2)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	provider.now = func() time.Time { return now }
+
+	a := domain.Location{Lat: 10.770001, Lng: 106.700001}
+	aNearby := domain.Location{Lat: 10.770002, Lng: 106.700002}
+	b := domain.Location{Lat: 10.81, Lng: 106.66}
+	c := domain.Location{Lat: 10.90, Lng: 106.50}
+
+	first, _ := provider.Route(context.Background(), a, b)
+	second, _ := provider.Route(context.Background(), aNearby, b)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, next.calls)
+
+	provider.Route(context.Background(), b, a)
+	assert.Equal(t, 2, next.calls, "direction is part of the key")
+
+	now = now.Add(2 * time.Minute)
+	expired, _ := provider.Route(context.Background(), a, b)
+	assert.Equal(t, 3, next.calls)
+	assert.NotEqual(t, first, expired)
+
+	provider.Route(context.Background(), a, c)
+	provider.Route(context.Background(), c, a)
+	assert.LessOrEqual(t, len(provider.entries), 2)
+}
+
+func TestHaversineProvider_Route(t *testing.T) {
+	route, err := NewHaversineProvider(1.5, 30).Route(context.Background(),
+		domain.Location{Lat: 0, Lng: 0},
+		domain.Location{Lat: 0, Lng: 0.1},
+	)
+
+	assert.NoError(t, err)
+	assert.InDelta(t, 11119.5*1.5, route.DistanceMeters, 1)
+	assert.InDelta(t, (33*time.Minute + 22*time.Second).Seconds(), route.Duration.Seconds(), 2)
+	assert.NotEmpty(t, route.Polyline)
+}
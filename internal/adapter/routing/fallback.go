@@ -0,0 +1,34 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/core/port"
+)
+
+// FallbackProvider uses secondary when primary fails for reasons other than
+// there being no road route at all.
+type FallbackProvider struct {
+	primary   port.RoutingProvider
+	secondary port.RoutingProvider
+}
+
+func NewFallbackProvider(primary, secondary port.RoutingProvider) *FallbackProvider {
+	return &FallbackProvider{
+		primary:   primary,
+		secondary: secondary,
+	}
+}
+
+func (p *FallbackProvider) Route(ctx context.Context, from, to domain.Location) (domain.Route, error) {
+	route, err := p.primary.Route(ctx, from, to)
+	if err == nil || errors.Is(err, domain.ErrNoRoute) {
+		return route, err
+	}
+
+	log.Printf("routing provider failed, falling back: %v", err)
+	return p.secondary.Route(ctx, from, to)
+}
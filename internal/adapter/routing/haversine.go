@@ -0,0 +1,45 @@
+package routing
+
+import (
+	"context"
+	"time"
+
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/pkg/geo"
+)
+
+const (
+	DefaultDetourFactor = 1.3
+	DefaultSpeedKmh     = 25.0
+)
+
+// HaversineProvider estimates road routes from the straight-line distance
+// stretched by a detour factor and a constant average speed.
+type HaversineProvider struct {
+	detourFactor float64
+	speedKmh     float64
+}
+
+func NewHaversineProvider(detourFactor, speedKmh float64) *HaversineProvider {
+	if detourFactor < 1 {
+		detourFactor = DefaultDetourFactor
+	}
+	if speedKmh <= 0 {
+		speedKmh = DefaultSpeedKmh
+	}
+	return &HaversineProvider{
+		detourFactor: detourFactor,
+		speedKmh:     speedKmh,
+	}
+}
+
+func (p *HaversineProvider) Route(ctx context.Context, from, to domain.Location) (domain.Route, error) {
+	distance := geo.CalculateDistance(from.Lat, from.Lng, to.Lat, to.Lng) * p.detourFactor
+	hours := distance / 1000.0 / p.speedKmh
+
+	return domain.Route{
+		DistanceMeters: distance,
+		Duration:       time.Duration(hours * float64(time.Hour)),
+		Polyline:       geo.EncodePolyline([][2]float64{{from.Lat, from.Lng}, {to.Lat, to.Lng}}),
+	}, nil
+}
@@ -0,0 +1,78 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+// OSRMProvider queries the route service of an OSRM-compatible HTTP API.
+type OSRMProvider struct {
+	baseURL string
+	profile string
+	client  *http.Client
+}
+
+func NewOSRMProvider(baseURL, profile string, client *http.Client) *OSRMProvider {
+	if profile == "" {
+		profile = "driving"
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	return &OSRMProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		profile: profile,
+		client:  client,
+	}
+}
+
+type osrmResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Routes  []struct {
+		Distance float64 `json:"distance"`
+		Duration float64 `json:"duration"`
+		Geometry string  `json:"geometry"`
+	} `json:"routes"`
+}
+
+func (p *OSRMProvider) Route(ctx context.Context, from, to domain.Location) (domain.Route, error) {
+	url := fmt.Sprintf("%s/route/v1/%s/%f,%f;%f,%f?overview=full&geometries=polyline",
+		p.baseURL, p.profile, from.Lng, from.Lat, to.Lng, to.Lat)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return domain.Route{}, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return domain.Route{}, fmt.Errorf("osrm request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body osrmResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return domain.Route{}, fmt.Errorf("osrm response (status %d): %w", resp.StatusCode, err)
+	}
+
+	if body.Code == "NoRoute" || (body.Code == "Ok" && len(body.Routes) == 0) {
+		return domain.Route{}, domain.ErrNoRoute
+	}
+	if body.Code != "Ok" {
+		return domain.Route{}, fmt.Errorf("osrm error %s: %s", body.Code, body.Message)
+	}
+
+	route := body.Routes[0]
+	return domain.Route{
+		DistanceMeters: route.Distance,
+		Duration:       time.Duration(route.Duration * float64(time.Second)),
+		Polyline:       route.Geometry,
+	}, nil
+}
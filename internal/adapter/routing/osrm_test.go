@@ -0,0 +1,89 @@
+package routing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+func TestOSRMProvider_Route(t *testing.T) {
+	var gotPath, gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":"Ok","routes":[{"distance":5230.4,"duration":612.5,"geometry":"_p~iF~ps|U_ulLnnqC"}]}`))
+	}))
+	defer srv.Close()
+
+	provider := NewOSRMProvider(srv.URL+"/", "bike", srv.Client())
+
+	route, err := provider.Route(context.Background(),
+		domain.Location{Lat: 10.77, Lng: 106.70},
+		domain.Location{Lat: 10.81, Lng: 106.66},
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, "/route/v1/bike/106.700000,10.770000;106.660000,10.810000", gotPath)
+	assert.Contains(t, gotQuery, "geometries=polyline")
+	assert.Equal(t, 5230.4, route.DistanceMeters)
+	assert.Equal(t, 612500*time.Millisecond, route.Duration)
+	assert.Equal(t, "_p~iF~ps|U_ulLnnqC", route.Polyline)
+}
+
+func TestOSRMProvider_Route_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{name: "No Route", status: http.StatusBadRequest, body: `{"code":"NoRoute","message":"Impossible route"}`, wantErr: domain.ErrNoRoute},
+		{name: "Empty Routes", status: http.StatusOK, body: `{"code":"Ok","routes":[]}`, wantErr: domain.ErrNoRoute},
+		{name: "Invalid Query", status: http.StatusBadRequest, body: `{"code":"InvalidQuery","message":"bad coordinates"}`},
+		{name: "Not JSON", status: http.StatusBadGateway, body: `<html>bad gateway</html>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := NewOSRMProvider(srv.URL, "", srv.Client()).Route(context.Background(), domain.Location{}, domain.Location{Lat: 1, Lng: 1})
+
+			assert.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NotErrorIs(t, err, domain.ErrNoRoute)
+			}
+		})
+	}
+}
+
+func TestFallbackProvider_Route(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	haversine := NewHaversineProvider(1.5, 30)
+	provider := NewFallbackProvider(NewOSRMProvider(srv.URL, "", srv.Client()), haversine)
+
+	from := domain.Location{Lat: 10.77, Lng: 106.70}
+	to := domain.Location{Lat: 10.81, Lng: 106.66}
+	route, err := provider.Route(context.Background(), from, to)
+	require.NoError(t, err)
+
+	expected, _ := haversine.Route(context.Background(), from, to)
+	assert.Equal(t, expected, route)
+}
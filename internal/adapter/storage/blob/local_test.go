@@ -0,0 +1,43 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vantutran2k1/flowfleet/internal/core/port"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	t.Run("Round Trip", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "proofs/order/photo.jpg", strings.NewReader("first")))
+		require.NoError(t, store.Put(ctx, "proofs/order/photo.jpg", strings.NewReader("second")))
+
+		body, err := store.Get(ctx, "proofs/order/photo.jpg")
+		require.NoError(t, err)
+		defer body.Close()
+
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, "second", string(data))
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		_, err := store.Get(ctx, "proofs/other/photo.jpg")
+		assert.ErrorIs(t, err, port.ErrBlobNotFound)
+	})
+
+	t.Run("Escaping Key", func(t *testing.T) {
+		assert.Error(t, store.Put(ctx, "../outside", strings.NewReader("x")))
+		assert.Error(t, store.Put(ctx, "/etc/passwd", strings.NewReader("x")))
+		_, err := store.Get(ctx, "proofs/../../outside")
+		assert.Error(t, err)
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createDriver = `-- name: CreateDriver :one
@@ -123,17 +124,60 @@ func (q *Queries) GetDriver(ctx context.Context, id uuid.UUID) (GetDriverRow, er
 	return i, err
 }
 
+const getDriverLoad = `-- name: GetDriverLoad :one
+SELECT d.capacity_parcels, d.capacity_weight_grams, d.capacity_volume_cm3, d.cold_chain, d.max_item_length_cm,
+       COUNT(o.id)::int4 AS active_orders,
+       COALESCE(SUM(o.parcel_count), 0)::int4 AS parcels,
+       COALESCE(SUM(o.weight_grams), 0)::int4 AS weight_grams,
+       COALESCE(SUM(o.volume_cm3), 0)::int4 AS volume_cm3
+FROM drivers d
+LEFT JOIN orders o ON o.driver_id = d.id AND o.status IN ('assigned', 'arrived', 'picked_up', 'delivery_failed')
+WHERE d.id = $1
+GROUP BY d.id
+`
+
+type GetDriverLoadRow struct {
+	CapacityParcels     pgtype.Int4
+	CapacityWeightGrams pgtype.Int4
+	CapacityVolumeCm3   pgtype.Int4
+	ColdChain           bool
+	MaxItemLengthCm     pgtype.Int4
+	ActiveOrders        int32
+	Parcels             int32
+	WeightGrams         int32
+	VolumeCm3           int32
+}
+
+func (q *Queries) GetDriverLoad(ctx context.Context, id uuid.UUID) (GetDriverLoadRow, error) {
+	row := q.db.QueryRow(ctx, getDriverLoad, id)
+	var i GetDriverLoadRow
+	err := row.Scan(
+		&i.CapacityParcels,
+		&i.CapacityWeightGrams,
+		&i.CapacityVolumeCm3,
+		&i.ColdChain,
+		&i.MaxItemLengthCm,
+		&i.ActiveOrders,
+		&i.Parcels,
+		&i.WeightGrams,
+		&i.VolumeCm3,
+	)
+	return i, err
+}
+
 const getDriverByEmail = `-- name: GetDriverByEmail :one
-SELECT id, password_hash, name, status
+SELECT id, fleet_id, password_hash, name, status, role
 FROM drivers
 WHERE email = $1 LIMIT 1
 `
 
 type GetDriverByEmailRow struct {
 	ID           uuid.UUID
+	FleetID      uuid.UUID
 	PasswordHash string
 	Name         string
 	Status       DriverStatus
+	Role         AccountRole
 }
 
 func (q *Queries) GetDriverByEmail(ctx context.Context, email string) (GetDriverByEmailRow, error) {
@@ -141,9 +185,11 @@ func (q *Queries) GetDriverByEmail(ctx context.Context, email string) (GetDriver
 	var i GetDriverByEmailRow
 	err := row.Scan(
 		&i.ID,
+		&i.FleetID,
 		&i.PasswordHash,
 		&i.Name,
 		&i.Status,
+		&i.Role,
 	)
 	return i, err
 }
@@ -186,3 +232,47 @@ func (q *Queries) ListDriversByFleet(ctx context.Context, fleetID uuid.UUID) ([]
 	}
 	return items, nil
 }
+
+const lockDriverStatus = `-- name: LockDriverStatus :one
+SELECT status FROM drivers
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) LockDriverStatus(ctx context.Context, id uuid.UUID) (DriverStatus, error) {
+	row := q.db.QueryRow(ctx, lockDriverStatus, id)
+	var status DriverStatus
+	err := row.Scan(&status)
+	return status, err
+}
+
+const updateDriverCapacity = `-- name: UpdateDriverCapacity :execrows
+UPDATE drivers
+SET capacity_parcels = $2, capacity_weight_grams = $3, capacity_volume_cm3 = $4,
+    cold_chain = $5, max_item_length_cm = $6, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateDriverCapacityParams struct {
+	ID                  uuid.UUID
+	CapacityParcels     pgtype.Int4
+	CapacityWeightGrams pgtype.Int4
+	CapacityVolumeCm3   pgtype.Int4
+	ColdChain           bool
+	MaxItemLengthCm     pgtype.Int4
+}
+
+func (q *Queries) UpdateDriverCapacity(ctx context.Context, arg UpdateDriverCapacityParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateDriverCapacity,
+		arg.ID,
+		arg.CapacityParcels,
+		arg.CapacityWeightGrams,
+		arg.CapacityVolumeCm3,
+		arg.ColdChain,
+		arg.MaxItemLengthCm,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
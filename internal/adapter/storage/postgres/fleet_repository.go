@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+type FleetRepository struct {
+	q Querier
+}
+
+func NewFleetRepository(q Querier) *FleetRepository {
+	return &FleetRepository{q: q}
+}
+
+func (r *FleetRepository) GetPricingPolicy(ctx context.Context, fleetID string) (domain.FleetPricingPolicy, error) {
+	fleetUUID, err := uuid.Parse(fleetID)
+	if err != nil {
+		return domain.FleetPricingPolicy{}, domain.ErrFleetNotFound
+	}
+
+	row, err := r.q.GetFleetPricingPolicy(ctx, fleetUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.FleetPricingPolicy{}, domain.ErrFleetNotFound
+		}
+		return domain.FleetPricingPolicy{}, err
+	}
+
+	return PricingPolicyFromRow(row), nil
+}
+
+func PricingPolicyFromRow(row GetFleetPricingPolicyRow) domain.FleetPricingPolicy {
+	return domain.FleetPricingPolicy{
+		Currency: row.Currency,
+		Rounding: domain.RoundingRule{
+			Increment: int64(row.RoundingIncrement),
+			Mode:      domain.RoundingMode(row.RoundingMode),
+		},
+		MinimumFareCents:   int(row.MinimumFareCents),
+		DriverSharePercent: int(row.DriverSharePercent),
+	}
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+type locationPoint struct {
+	driverID uuid.UUID
+	loc      domain.Location
+	at       time.Time
+}
+
+// LocationWriter buffers driver positions and writes them in batches: every
+// point is appended to the driver_locations history and drivers.current_location
+// is moved to each driver's latest point.
+type LocationWriter struct {
+	store         Store
+	points        chan locationPoint
+	batchSize     int
+	flushInterval time.Duration
+
+	// partitionsUntil is the first month without a known partition.
+	partitionsUntil time.Time
+}
+
+func NewLocationWriter(store Store, batchSize int, flushInterval time.Duration) *LocationWriter {
+	return &LocationWriter{
+		store:         store,
+		points:        make(chan locationPoint, batchSize*4),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+	}
+}
+
+// Record queues a position without blocking. When the writer falls behind,
+// positions are dropped rather than stalling the caller.
+func (w *LocationWriter) Record(driverID string, loc domain.Location, at time.Time) {
+	driverUUID, err := uuid.Parse(driverID)
+	if err != nil {
+		return
+	}
+
+	select {
+	case w.points <- locationPoint{driverID: driverUUID, loc: loc, at: at}:
+	default:
+		log.Printf("location history buffer full, dropping position of driver %s", driverID)
+	}
+}
+
+// Run writes batches until ctx is cancelled, then writes what is buffered.
+func (w *LocationWriter) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]locationPoint, 0, w.batchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := w.flush(ctx, batch); err != nil {
+			log.Printf("failed to write %d driver locations: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case p := <-w.points:
+			batch = append(batch, p)
+			if len(batch) >= w.batchSize {
+				flush(ctx)
+			}
+		case <-ticker.C:
+			flush(ctx)
+		case <-ctx.Done():
+			for {
+				select {
+				case p := <-w.points:
+					batch = append(batch, p)
+				default:
+					flush(context.Background())
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *LocationWriter) flush(ctx context.Context, batch []locationPoint) error {
+	history := InsertDriverLocationsParams{
+		DriverIds:   make([]uuid.UUID, len(batch)),
+		RecordedAts: make([]time.Time, len(batch)),
+		Lngs:        make([]float64, len(batch)),
+		Lats:        make([]float64, len(batch)),
+	}
+	latest := make(map[uuid.UUID]locationPoint)
+	var newest time.Time
+	for i, p := range batch {
+		history.DriverIds[i] = p.driverID
+		history.RecordedAts[i] = p.at
+		history.Lngs[i] = p.loc.Lng
+		history.Lats[i] = p.loc.Lat
+
+		if prev, ok := latest[p.driverID]; !ok || !p.at.Before(prev.at) {
+			latest[p.driverID] = p
+		}
+		if p.at.After(newest) {
+			newest = p.at
+		}
+	}
+
+	current := UpdateDriverCurrentLocationsParams{}
+	for driverID, p := range latest {
+		current.DriverIds = append(current.DriverIds, driverID)
+		current.Lngs = append(current.Lngs, p.loc.Lng)
+		current.Lats = append(current.Lats, p.loc.Lat)
+	}
+
+	if err := w.ensurePartitions(ctx, newest); err != nil {
+		return err
+	}
+
+	return w.store.ExecTx(ctx, func(q Querier) error {
+		if err := q.InsertDriverLocations(ctx, history); err != nil {
+			return err
+		}
+		return q.UpdateDriverCurrentLocations(ctx, current)
+	})
+}
+
+// ensurePartitions makes sure the partitions for the month of t and the
+// month after exist, so writes never hit a missing range.
+func (w *LocationWriter) ensurePartitions(ctx context.Context, t time.Time) error {
+	month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if month.Before(w.partitionsUntil) {
+		return nil
+	}
+
+	for _, m := range []time.Time{month, month.AddDate(0, 1, 0)} {
+		if err := w.store.EnsureDriverLocationsPartition(ctx, pgtype.Date{Time: m, Valid: true}); err != nil {
+			return err
+		}
+	}
+	w.partitionsUntil = month.AddDate(0, 1, 0)
+
+	return nil
+}
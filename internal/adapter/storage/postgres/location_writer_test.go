@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+// fakeLocationStore records what a LocationWriter writes. Other queries are
+// left to the nil Querier and panic if called.
+type fakeLocationStore struct {
+	Querier
+
+	mu         sync.Mutex
+	history    []InsertDriverLocationsParams
+	current    []UpdateDriverCurrentLocationsParams
+	partitions []time.Time
+}
+
+func (s *fakeLocationStore) ExecTx(ctx context.Context, fn func(Querier) error) error {
+	return fn(s)
+}
+
+func (s *fakeLocationStore) InsertDriverLocations(ctx context.Context, arg InsertDriverLocationsParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = append(s.history, arg)
+	return nil
+}
+
+func (s *fakeLocationStore) UpdateDriverCurrentLocations(ctx context.Context, arg UpdateDriverCurrentLocationsParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current = append(s.current, arg)
+	return nil
+}
+
+func (s *fakeLocationStore) EnsureDriverLocationsPartition(ctx context.Context, month pgtype.Date) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.partitions = append(s.partitions, month.Time)
+	return nil
+}
+
+func (s *fakeLocationStore) written() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, batch := range s.history {
+		n += len(batch.DriverIds)
+	}
+	return n
+}
+
+func TestLocationWriter_WritesFullBatches(t *testing.T) {
+	store := &fakeLocationStore{}
+	writer := NewLocationWriter(store, 3, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		writer.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	driverID := uuid.New()
+	at := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		writer.Record(driverID.String(), domain.Location{Lat: 10 + float64(i), Lng: 106}, at.Add(time.Duration(i)*time.Second))
+	}
+
+	require.Eventually(t, func() bool { return store.written() == 3 }, time.Second, 10*time.Millisecond)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	require.Len(t, store.history, 1, "a full batch is written without waiting for the flush interval")
+	assert.Equal(t, at.Add(2*time.Second), store.history[0].RecordedAts[2])
+	require.Len(t, store.current, 1)
+	assert.Equal(t, []uuid.UUID{driverID}, store.current[0].DriverIds)
+	assert.Equal(t, []float64{12}, store.current[0].Lats, "the current location is the driver's latest point")
+}
+
+func TestLocationWriter_LatestPointByRecordedTime(t *testing.T) {
+	store := &fakeLocationStore{}
+	writer := NewLocationWriter(store, 10, time.Hour)
+
+	driverID := uuid.New()
+	at := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	err := writer.flush(context.Background(), []locationPoint{
+		{driverID: driverID, loc: domain.Location{Lat: 11, Lng: 106}, at: at.Add(time.Second)},
+		{driverID: driverID, loc: domain.Location{Lat: 10, Lng: 106}, at: at},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, store.current, 1)
+	assert.Equal(t, []float64{11}, store.current[0].Lats, "a late-arriving older point does not move the driver back")
+}
+
+func TestLocationWriter_CreatesPartitionsOncePerMonth(t *testing.T) {
+	store := &fakeLocationStore{}
+	writer := NewLocationWriter(store, 10, time.Hour)
+	driverID := uuid.New()
+
+	write := func(at time.Time) {
+		t.Helper()
+		require.NoError(t, writer.flush(context.Background(), []locationPoint{
+			{driverID: driverID, loc: domain.Location{Lat: 10, Lng: 106}, at: at},
+		}))
+	}
+
+	write(time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC))
+	write(time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC))
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+	}, store.partitions, "the month and the next are created once")
+
+	write(time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+	}, store.partitions, "crossing into a new month creates the one after it")
+}
+
+func TestLocationWriter_FlushesBufferOnShutdown(t *testing.T) {
+	store := &fakeLocationStore{}
+	writer := NewLocationWriter(store, 100, time.Hour)
+
+	at := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	for range 5 {
+		writer.Record(uuid.NewString(), domain.Location{Lat: 10, Lng: 106}, at)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	writer.Run(ctx)
+
+	assert.Equal(t, 5, store.written(), "points queued before shutdown are drained and written")
+}
+
+func TestLocationWriter_DropsWhenBufferFull(t *testing.T) {
+	store := &fakeLocationStore{}
+	writer := NewLocationWriter(store, 1, time.Hour)
+
+	at := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	for range 10 {
+		writer.Record(uuid.NewString(), domain.Location{Lat: 10, Lng: 106}, at)
+	}
+	writer.Record("not-a-uuid", domain.Location{Lat: 10, Lng: 106}, at)
+
+	assert.Len(t, writer.points, 4, "Record never blocks on a full buffer")
+}
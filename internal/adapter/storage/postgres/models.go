@@ -13,6 +13,95 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AccountRole string
+
+const (
+	AccountRoleDriver     AccountRole = "driver"
+	AccountRoleDispatcher AccountRole = "dispatcher"
+	AccountRoleAdmin      AccountRole = "admin"
+)
+
+func (e *AccountRole) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AccountRole(s)
+	case string:
+		*e = AccountRole(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AccountRole: %T", src)
+	}
+	return nil
+}
+
+type NullAccountRole struct {
+	AccountRole AccountRole
+	Valid       bool // Valid is true if AccountRole is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAccountRole) Scan(value interface{}) error {
+	if value == nil {
+		ns.AccountRole, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AccountRole.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAccountRole) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AccountRole), nil
+}
+
+type DeliveryFailureReason string
+
+const (
+	DeliveryFailureReasonRecipientAbsent DeliveryFailureReason = "recipient_absent"
+	DeliveryFailureReasonAddressNotFound DeliveryFailureReason = "address_not_found"
+	DeliveryFailureReasonRefused         DeliveryFailureReason = "refused"
+	DeliveryFailureReasonAccessDenied    DeliveryFailureReason = "access_denied"
+	DeliveryFailureReasonDamaged         DeliveryFailureReason = "damaged"
+	DeliveryFailureReasonOther           DeliveryFailureReason = "other"
+)
+
+func (e *DeliveryFailureReason) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DeliveryFailureReason(s)
+	case string:
+		*e = DeliveryFailureReason(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DeliveryFailureReason: %T", src)
+	}
+	return nil
+}
+
+type NullDeliveryFailureReason struct {
+	DeliveryFailureReason DeliveryFailureReason
+	Valid                 bool // Valid is true if DeliveryFailureReason is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDeliveryFailureReason) Scan(value interface{}) error {
+	if value == nil {
+		ns.DeliveryFailureReason, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DeliveryFailureReason.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDeliveryFailureReason) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DeliveryFailureReason), nil
+}
+
 type DriverStatus string
 
 const (
@@ -56,15 +145,61 @@ func (ns NullDriverStatus) Value() (driver.Value, error) {
 	return string(ns.DriverStatus), nil
 }
 
+type OrderPriority string
+
+const (
+	OrderPriorityStandard OrderPriority = "standard"
+	OrderPriorityExpress  OrderPriority = "express"
+	OrderPriorityCritical OrderPriority = "critical"
+)
+
+func (e *OrderPriority) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrderPriority(s)
+	case string:
+		*e = OrderPriority(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrderPriority: %T", src)
+	}
+	return nil
+}
+
+type NullOrderPriority struct {
+	OrderPriority OrderPriority
+	Valid         bool // Valid is true if OrderPriority is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrderPriority) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrderPriority, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrderPriority.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrderPriority) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrderPriority), nil
+}
+
 type OrderStatus string
 
 const (
-	OrderStatusPending   OrderStatus = "pending"
-	OrderStatusAssigned  OrderStatus = "assigned"
-	OrderStatusPickedUp  OrderStatus = "picked_up"
-	OrderStatusDelivered OrderStatus = "delivered"
-	OrderStatusCancelled OrderStatus = "cancelled"
-	OrderStatusArrived   OrderStatus = "arrived"
+	OrderStatusPending        OrderStatus = "pending"
+	OrderStatusAssigned       OrderStatus = "assigned"
+	OrderStatusPickedUp       OrderStatus = "picked_up"
+	OrderStatusDelivered      OrderStatus = "delivered"
+	OrderStatusCancelled      OrderStatus = "cancelled"
+	OrderStatusArrived        OrderStatus = "arrived"
+	OrderStatusScheduled      OrderStatus = "scheduled"
+	OrderStatusDeliveryFailed OrderStatus = "delivery_failed"
+	OrderStatusReturned       OrderStatus = "returned"
 )
 
 func (e *OrderStatus) Scan(src interface{}) error {
@@ -102,35 +237,442 @@ func (ns NullOrderStatus) Value() (driver.Value, error) {
 	return string(ns.OrderStatus), nil
 }
 
+type OrderStopKind string
+
+const (
+	OrderStopKindPickup  OrderStopKind = "pickup"
+	OrderStopKindDropoff OrderStopKind = "dropoff"
+	OrderStopKindReturn  OrderStopKind = "return"
+)
+
+func (e *OrderStopKind) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrderStopKind(s)
+	case string:
+		*e = OrderStopKind(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrderStopKind: %T", src)
+	}
+	return nil
+}
+
+type NullOrderStopKind struct {
+	OrderStopKind OrderStopKind
+	Valid         bool // Valid is true if OrderStopKind is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrderStopKind) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrderStopKind, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrderStopKind.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrderStopKind) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrderStopKind), nil
+}
+
+type OrderStopStatus string
+
+const (
+	OrderStopStatusPending   OrderStopStatus = "pending"
+	OrderStopStatusArrived   OrderStopStatus = "arrived"
+	OrderStopStatusCompleted OrderStopStatus = "completed"
+	OrderStopStatusFailed    OrderStopStatus = "failed"
+)
+
+func (e *OrderStopStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrderStopStatus(s)
+	case string:
+		*e = OrderStopStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrderStopStatus: %T", src)
+	}
+	return nil
+}
+
+type NullOrderStopStatus struct {
+	OrderStopStatus OrderStopStatus
+	Valid           bool // Valid is true if OrderStopStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrderStopStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrderStopStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrderStopStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrderStopStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrderStopStatus), nil
+}
+
+type PromoKind string
+
+const (
+	PromoKindPercentage PromoKind = "percentage"
+	PromoKindFixed      PromoKind = "fixed"
+)
+
+func (e *PromoKind) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PromoKind(s)
+	case string:
+		*e = PromoKind(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PromoKind: %T", src)
+	}
+	return nil
+}
+
+type NullPromoKind struct {
+	PromoKind PromoKind
+	Valid     bool // Valid is true if PromoKind is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPromoKind) Scan(value interface{}) error {
+	if value == nil {
+		ns.PromoKind, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PromoKind.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPromoKind) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PromoKind), nil
+}
+
+type RoundingMode string
+
+const (
+	RoundingModeNearest RoundingMode = "nearest"
+	RoundingModeUp      RoundingMode = "up"
+	RoundingModeDown    RoundingMode = "down"
+)
+
+func (e *RoundingMode) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RoundingMode(s)
+	case string:
+		*e = RoundingMode(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RoundingMode: %T", src)
+	}
+	return nil
+}
+
+type NullRoundingMode struct {
+	RoundingMode RoundingMode
+	Valid        bool // Valid is true if RoundingMode is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullRoundingMode) Scan(value interface{}) error {
+	if value == nil {
+		ns.RoundingMode, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.RoundingMode.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullRoundingMode) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.RoundingMode), nil
+}
+
+type DeliveryAttempt struct {
+	ID        uuid.UUID
+	OrderID   uuid.UUID
+	Position  int32
+	DriverID  uuid.UUID
+	Reason    DeliveryFailureReason
+	Notes     string
+	Retried   bool
+	CreatedAt time.Time
+}
+
+type DeliveryProof struct {
+	OrderID       uuid.UUID
+	PhotoKey      string
+	SignatureKey  string
+	RecipientName string
+	PinVerifiedAt pgtype.Timestamptz
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
 type Driver struct {
-	ID              uuid.UUID
-	FleetID         uuid.UUID
-	Name            string
-	Phone           string
-	Status          DriverStatus
-	CurrentLocation interface{}
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
-	Email           string
-	PasswordHash    string
+	ID                  uuid.UUID
+	FleetID             uuid.UUID
+	Name                string
+	Phone               string
+	Status              DriverStatus
+	CurrentLocation     interface{}
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Email               string
+	PasswordHash        string
+	CapacityParcels     pgtype.Int4
+	CapacityWeightGrams pgtype.Int4
+	CapacityVolumeCm3   pgtype.Int4
+	ColdChain           bool
+	MaxItemLengthCm     pgtype.Int4
+	Role                AccountRole
+}
+
+type DriverEarning struct {
+	ID          uuid.UUID
+	DriverID    uuid.UUID
+	OrderID     uuid.UUID
+	AmountCents int32
+	Currency    string
+	CreatedAt   time.Time
+}
+
+type DriverLocation struct {
+	DriverID   uuid.UUID
+	RecordedAt time.Time
+	Location   interface{}
 }
 
 type Fleet struct {
+	ID                 uuid.UUID
+	Name               string
+	Slug               string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	Currency           string
+	RoundingIncrement  int32
+	RoundingMode       RoundingMode
+	MinimumFareCents   int32
+	DriverSharePercent int32
+	PodPhoto           bool
+	PodSignature       bool
+	PodRecipientName   bool
+	PodPin             bool
+}
+
+type Order struct {
+	ID                  uuid.UUID
+	FleetID             uuid.UUID
+	DriverID            pgtype.UUID
+	AmountCents         int32
+	Status              OrderStatus
+	PickupLocation      interface{}
+	DropoffLocation     interface{}
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Currency            string
+	PromisedPickupAt    pgtype.Timestamptz
+	PromisedDropoffAt   pgtype.Timestamptz
+	PickupEtaAt         pgtype.Timestamptz
+	DropoffEtaAt        pgtype.Timestamptz
+	EtaUpdatedAt        pgtype.Timestamptz
+	LateSince           pgtype.Timestamptz
+	TrackingTokenHash   []byte
+	ParcelCount         int32
+	WeightGrams         int32
+	VolumeCm3           int32
+	ScheduledPickupAt   pgtype.Timestamptz
+	RecurringOrderID    pgtype.UUID
+	VehicleType         string
+	Notes               string
+	Priority            OrderPriority
+	DeliverBy           pgtype.Timestamptz
+	SlaAtRiskSince      pgtype.Timestamptz
+	SlaBreachedAt       pgtype.Timestamptz
+	DeliveryPinHash     []byte
+	DeliveryPinAttempts int32
+	Fragile             bool
+	ColdChain           bool
+	LongestItemCm       int32
+}
+
+type OrderFareLine struct {
+	ID          uuid.UUID
+	OrderID     uuid.UUID
+	Position    int32
+	Kind        string
+	Description string
+	AmountCents int32
+}
+
+type OrderItem struct {
+	OrderID            uuid.UUID
+	Position           int32
+	Description        string
+	Quantity           int32
+	WeightGrams        int32
+	LengthCm           int32
+	WidthCm            int32
+	HeightCm           int32
+	Fragile            bool
+	ColdChain          bool
+	DeclaredValueCents int32
+}
+
+type OrderStop struct {
+	ID           uuid.UUID
+	OrderID      uuid.UUID
+	Position     int32
+	Kind         OrderStopKind
+	Location     interface{}
+	Status       OrderStopStatus
+	ArrivedAt    pgtype.Timestamptz
+	CompletedAt  pgtype.Timestamptz
+	CreatedAt    time.Time
+	Sequence     pgtype.Int4
+	WindowStart  pgtype.Timestamptz
+	WindowEnd    pgtype.Timestamptz
+	Late         bool
+	AddressLine1 string
+	AddressLine2 string
+	City         string
+	Postcode     string
+	ContactName  string
+	ContactPhone string
+	AccessNotes  string
+	DoorCode     string
+}
+
+type PricingZone struct {
+	ID             uuid.UUID
+	FleetID        uuid.UUID
+	Name           string
+	SurchargeCents int32
+	Area           interface{}
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type PromoCode struct {
+	ID               uuid.UUID
+	Code             string
+	FleetID          pgtype.UUID
+	Kind             PromoKind
+	Value            int32
+	MinFareCents     int32
+	MaxDiscountCents pgtype.Int4
+	MaxRedemptions   pgtype.Int4
+	MaxPerCustomer   pgtype.Int4
+	RedemptionCount  int32
+	StartsAt         time.Time
+	EndsAt           pgtype.Timestamptz
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Currency         pgtype.Text
+}
+
+type PromoRedemption struct {
+	ID            uuid.UUID
+	PromoID       uuid.UUID
+	OrderID       uuid.UUID
+	CustomerID    pgtype.Text
+	DiscountCents int32
+	CreatedAt     time.Time
+}
+
+type RecurringOrder struct {
+	ID                uuid.UUID
+	FleetID           uuid.UUID
+	CustomerID        string
+	Name              string
+	Schedule          string
+	TimeZone          string
+	PickupLocation    interface{}
+	DropoffLocation   interface{}
+	VehicleType       string
+	Notes             string
+	Paused            bool
+	MaterializedUntil pgtype.Timestamptz
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+type RecurringOrderSkip struct {
+	RecurringOrderID uuid.UUID
+	SkipDate         pgtype.Date
+}
+
+type ServiceArea struct {
 	ID        uuid.UUID
+	FleetID   uuid.UUID
 	Name      string
-	Slug      string
+	Area      interface{}
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
-type Order struct {
-	ID              uuid.UUID
-	FleetID         uuid.UUID
-	DriverID        pgtype.UUID
-	AmountCents     int32
-	Status          OrderStatus
-	PickupLocation  interface{}
-	DropoffLocation interface{}
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
+type TariffHoliday struct {
+	ScheduleID  uuid.UUID
+	HolidayDate pgtype.Date
+	Name        string
+}
+
+type TariffRule struct {
+	ID           uuid.UUID
+	ScheduleID   uuid.UUID
+	Name         string
+	VehicleType  string
+	DaysOfWeek   int16
+	HolidaysOnly bool
+	StartMinute  int32
+	EndMinute    int32
+	Priority     int32
+	BaseCents    int32
+	PerKmCents   int32
+	CreatedAt    time.Time
+}
+
+type TariffSchedule struct {
+	ID        uuid.UUID
+	FleetID   uuid.UUID
+	TimeZone  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type TrackingLink struct {
+	ID        uuid.UUID
+	OrderID   uuid.UUID
+	TokenHash []byte
+	ExpiresAt time.Time
+	RevokedAt pgtype.Timestamptz
+	CreatedAt time.Time
+}
+
+type ZoneFare struct {
+	FromZoneID  uuid.UUID
+	ToZoneID    uuid.UUID
+	VehicleType string
+	FareCents   int32
 }
@@ -13,22 +13,67 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const assignDriverToOrder = `-- name: AssignDriverToOrder :exec
+const addOrderCharge = `-- name: AddOrderCharge :exec
 UPDATE orders
-SET driver_id = $1, status = 'assigned', updated_at = NOW()
-WHERE id = $2
+SET amount_cents = amount_cents + $2, updated_at = NOW()
+WHERE id = $1
 `
 
-type AssignDriverToOrderParams struct {
-	DriverID pgtype.UUID
-	ID       uuid.UUID
+type AddOrderChargeParams struct {
+	ID          uuid.UUID
+	AmountCents int32
 }
 
-func (q *Queries) AssignDriverToOrder(ctx context.Context, arg AssignDriverToOrderParams) error {
-	_, err := q.db.Exec(ctx, assignDriverToOrder, arg.DriverID, arg.ID)
+func (q *Queries) AddOrderCharge(ctx context.Context, arg AddOrderChargeParams) error {
+	_, err := q.db.Exec(ctx, addOrderCharge, arg.ID, arg.AmountCents)
 	return err
 }
 
+const assignDriverToOrder = `-- name: AssignDriverToOrder :execrows
+UPDATE orders
+SET driver_id = $1, status = 'assigned',
+    promised_pickup_at = $3, promised_dropoff_at = $4,
+    pickup_eta_at = $3, dropoff_eta_at = $4, eta_updated_at = NOW(), late_since = NULL,
+    sla_at_risk_since = $5, updated_at = NOW()
+WHERE id = $2 AND status IN ('pending', 'scheduled')
+`
+
+type AssignDriverToOrderParams struct {
+	DriverID          pgtype.UUID
+	ID                uuid.UUID
+	PromisedPickupAt  pgtype.Timestamptz
+	PromisedDropoffAt pgtype.Timestamptz
+	SlaAtRiskSince    pgtype.Timestamptz
+}
+
+func (q *Queries) AssignDriverToOrder(ctx context.Context, arg AssignDriverToOrderParams) (int64, error) {
+	result, err := q.db.Exec(ctx, assignDriverToOrder,
+		arg.DriverID,
+		arg.ID,
+		arg.PromisedPickupAt,
+		arg.PromisedDropoffAt,
+		arg.SlaAtRiskSince,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const cancelScheduledOrder = `-- name: CancelScheduledOrder :execrows
+UPDATE orders
+SET status = 'cancelled', updated_at = NOW()
+WHERE id = $1 AND status = 'scheduled'
+`
+
+func (q *Queries) CancelScheduledOrder(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelScheduledOrder, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const confirmOrderAcceptance = `-- name: ConfirmOrderAcceptance :exec
 UPDATE drivers
 SET status = 'en_route', updated_at = NOW()
@@ -40,19 +85,51 @@ func (q *Queries) ConfirmOrderAcceptance(ctx context.Context, id uuid.UUID) erro
 	return err
 }
 
+const countActiveOrdersByDriver = `-- name: CountActiveOrdersByDriver :one
+SELECT COUNT(*)
+FROM orders
+WHERE driver_id = $1 AND status IN ('assigned', 'arrived', 'picked_up', 'delivery_failed')
+`
+
+func (q *Queries) CountActiveOrdersByDriver(ctx context.Context, driverID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countActiveOrdersByDriver, driverID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createOrder = `-- name: CreateOrder :one
-INSERT INTO orders (fleet_id, amount_cents, status, pickup_location, dropoff_location)
-VALUES ($1, $2, 'pending', ST_SetSRID(ST_MakePoint($3, $4), 4326), ST_SetSRID(ST_MakePoint($5, $6), 4326))
+INSERT INTO orders (fleet_id, amount_cents, currency, status, pickup_location, dropoff_location, tracking_token_hash,
+                    parcel_count, weight_grams, volume_cm3, scheduled_pickup_at, recurring_order_id, vehicle_type, notes,
+                    priority, deliver_by, delivery_pin_hash, fragile, cold_chain, longest_item_cm)
+VALUES ($1, $2, $3, $4, ST_SetSRID(ST_MakePoint($5, $6), 4326), ST_SetSRID(ST_MakePoint($7, $8), 4326), $9,
+        $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
 RETURNING id, created_at
 `
 
 type CreateOrderParams struct {
-	FleetID       uuid.UUID
-	AmountCents   int32
-	StMakepoint   interface{}
-	StMakepoint_2 interface{}
-	StMakepoint_3 interface{}
-	StMakepoint_4 interface{}
+	FleetID           uuid.UUID
+	AmountCents       int32
+	Currency          string
+	Status            OrderStatus
+	StMakepoint       interface{}
+	StMakepoint_2     interface{}
+	StMakepoint_3     interface{}
+	StMakepoint_4     interface{}
+	TrackingTokenHash []byte
+	ParcelCount       int32
+	WeightGrams       int32
+	VolumeCm3         int32
+	ScheduledPickupAt pgtype.Timestamptz
+	RecurringOrderID  pgtype.UUID
+	VehicleType       string
+	Notes             string
+	Priority          OrderPriority
+	DeliverBy         pgtype.Timestamptz
+	DeliveryPinHash   []byte
+	Fragile           bool
+	ColdChain         bool
+	LongestItemCm     int32
 }
 
 type CreateOrderRow struct {
@@ -64,16 +141,464 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Creat
 	row := q.db.QueryRow(ctx, createOrder,
 		arg.FleetID,
 		arg.AmountCents,
+		arg.Currency,
+		arg.Status,
 		arg.StMakepoint,
 		arg.StMakepoint_2,
 		arg.StMakepoint_3,
 		arg.StMakepoint_4,
+		arg.TrackingTokenHash,
+		arg.ParcelCount,
+		arg.WeightGrams,
+		arg.VolumeCm3,
+		arg.ScheduledPickupAt,
+		arg.RecurringOrderID,
+		arg.VehicleType,
+		arg.Notes,
+		arg.Priority,
+		arg.DeliverBy,
+		arg.DeliveryPinHash,
+		arg.Fragile,
+		arg.ColdChain,
+		arg.LongestItemCm,
 	)
 	var i CreateOrderRow
 	err := row.Scan(&i.ID, &i.CreatedAt)
 	return i, err
 }
 
+const createOrderFareLine = `-- name: CreateOrderFareLine :exec
+INSERT INTO order_fare_lines (order_id, position, kind, description, amount_cents)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreateOrderFareLineParams struct {
+	OrderID     uuid.UUID
+	Position    int32
+	Kind        string
+	Description string
+	AmountCents int32
+}
+
+func (q *Queries) CreateOrderFareLine(ctx context.Context, arg CreateOrderFareLineParams) error {
+	_, err := q.db.Exec(ctx, createOrderFareLine,
+		arg.OrderID,
+		arg.Position,
+		arg.Kind,
+		arg.Description,
+		arg.AmountCents,
+	)
+	return err
+}
+
+const getOrder = `-- name: GetOrder :one
+SELECT id, fleet_id, driver_id, amount_cents, currency, status,
+       ST_Y(pickup_location)::float8 as pickup_lat, ST_X(pickup_location)::float8 as pickup_lng,
+       ST_Y(dropoff_location)::float8 as dropoff_lat, ST_X(dropoff_location)::float8 as dropoff_lng,
+       created_at, updated_at,
+       promised_pickup_at, promised_dropoff_at, pickup_eta_at, dropoff_eta_at, eta_updated_at, late_since,
+       parcel_count, weight_grams, volume_cm3, scheduled_pickup_at, vehicle_type, notes, recurring_order_id,
+       priority, deliver_by, sla_at_risk_since, sla_breached_at, fragile, cold_chain, longest_item_cm
+FROM orders
+WHERE id = $1 LIMIT 1
+`
+
+type GetOrderRow struct {
+	ID                uuid.UUID
+	FleetID           uuid.UUID
+	DriverID          pgtype.UUID
+	AmountCents       int32
+	Currency          string
+	Status            OrderStatus
+	PickupLat         float64
+	PickupLng         float64
+	DropoffLat        float64
+	DropoffLng        float64
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	PromisedPickupAt  pgtype.Timestamptz
+	PromisedDropoffAt pgtype.Timestamptz
+	PickupEtaAt       pgtype.Timestamptz
+	DropoffEtaAt      pgtype.Timestamptz
+	EtaUpdatedAt      pgtype.Timestamptz
+	LateSince         pgtype.Timestamptz
+	ParcelCount       int32
+	WeightGrams       int32
+	VolumeCm3         int32
+	ScheduledPickupAt pgtype.Timestamptz
+	VehicleType       string
+	Notes             string
+	RecurringOrderID  pgtype.UUID
+	Priority          OrderPriority
+	DeliverBy         pgtype.Timestamptz
+	SlaAtRiskSince    pgtype.Timestamptz
+	SlaBreachedAt     pgtype.Timestamptz
+	Fragile           bool
+	ColdChain         bool
+	LongestItemCm     int32
+}
+
+func (q *Queries) GetOrder(ctx context.Context, id uuid.UUID) (GetOrderRow, error) {
+	row := q.db.QueryRow(ctx, getOrder, id)
+	var i GetOrderRow
+	err := row.Scan(
+		&i.ID,
+		&i.FleetID,
+		&i.DriverID,
+		&i.AmountCents,
+		&i.Currency,
+		&i.Status,
+		&i.PickupLat,
+		&i.PickupLng,
+		&i.DropoffLat,
+		&i.DropoffLng,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PromisedPickupAt,
+		&i.PromisedDropoffAt,
+		&i.PickupEtaAt,
+		&i.DropoffEtaAt,
+		&i.EtaUpdatedAt,
+		&i.LateSince,
+		&i.ParcelCount,
+		&i.WeightGrams,
+		&i.VolumeCm3,
+		&i.ScheduledPickupAt,
+		&i.VehicleType,
+		&i.Notes,
+		&i.RecurringOrderID,
+		&i.Priority,
+		&i.DeliverBy,
+		&i.SlaAtRiskSince,
+		&i.SlaBreachedAt,
+		&i.Fragile,
+		&i.ColdChain,
+		&i.LongestItemCm,
+	)
+	return i, err
+}
+
+const getOrderDeliveryPIN = `-- name: GetOrderDeliveryPIN :one
+SELECT delivery_pin_hash, delivery_pin_attempts FROM orders
+WHERE id = $1 LIMIT 1
+`
+
+type GetOrderDeliveryPINRow struct {
+	DeliveryPinHash     []byte
+	DeliveryPinAttempts int32
+}
+
+func (q *Queries) GetOrderDeliveryPIN(ctx context.Context, id uuid.UUID) (GetOrderDeliveryPINRow, error) {
+	row := q.db.QueryRow(ctx, getOrderDeliveryPIN, id)
+	var i GetOrderDeliveryPINRow
+	err := row.Scan(&i.DeliveryPinHash, &i.DeliveryPinAttempts)
+	return i, err
+}
+
+const getOrderTrackingTokenHash = `-- name: GetOrderTrackingTokenHash :one
+SELECT tracking_token_hash FROM orders
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetOrderTrackingTokenHash(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getOrderTrackingTokenHash, id)
+	var tracking_token_hash []byte
+	err := row.Scan(&tracking_token_hash)
+	return tracking_token_hash, err
+}
+
+const incrementDeliveryPINAttempts = `-- name: IncrementDeliveryPINAttempts :exec
+UPDATE orders
+SET delivery_pin_attempts = delivery_pin_attempts + 1
+WHERE id = $1
+`
+
+func (q *Queries) IncrementDeliveryPINAttempts(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, incrementDeliveryPINAttempts, id)
+	return err
+}
+
+const listActiveOrdersByDriver = `-- name: ListActiveOrdersByDriver :many
+SELECT id, status,
+       ST_Y(pickup_location)::float8 as pickup_lat, ST_X(pickup_location)::float8 as pickup_lng,
+       ST_Y(dropoff_location)::float8 as dropoff_lat, ST_X(dropoff_location)::float8 as dropoff_lng,
+       promised_pickup_at, promised_dropoff_at, pickup_eta_at, eta_updated_at, late_since,
+       deliver_by, sla_at_risk_since
+FROM orders
+WHERE driver_id = $1 AND status IN ('assigned', 'arrived', 'picked_up', 'delivery_failed')
+ORDER BY created_at
+`
+
+type ListActiveOrdersByDriverRow struct {
+	ID                uuid.UUID
+	Status            OrderStatus
+	PickupLat         float64
+	PickupLng         float64
+	DropoffLat        float64
+	DropoffLng        float64
+	PromisedPickupAt  pgtype.Timestamptz
+	PromisedDropoffAt pgtype.Timestamptz
+	PickupEtaAt       pgtype.Timestamptz
+	EtaUpdatedAt      pgtype.Timestamptz
+	LateSince         pgtype.Timestamptz
+	DeliverBy         pgtype.Timestamptz
+	SlaAtRiskSince    pgtype.Timestamptz
+}
+
+func (q *Queries) ListActiveOrdersByDriver(ctx context.Context, driverID pgtype.UUID) ([]ListActiveOrdersByDriverRow, error) {
+	rows, err := q.db.Query(ctx, listActiveOrdersByDriver, driverID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListActiveOrdersByDriverRow
+	for rows.Next() {
+		var i ListActiveOrdersByDriverRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.PickupLat,
+			&i.PickupLng,
+			&i.DropoffLat,
+			&i.DropoffLng,
+			&i.PromisedPickupAt,
+			&i.PromisedDropoffAt,
+			&i.PickupEtaAt,
+			&i.EtaUpdatedAt,
+			&i.LateSince,
+			&i.DeliverBy,
+			&i.SlaAtRiskSince,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listActiveOrdersByFleet = `-- name: ListActiveOrdersByFleet :many
+SELECT id, driver_id, status,
+       ST_Y(pickup_location)::float8 as pickup_lat, ST_X(pickup_location)::float8 as pickup_lng,
+       ST_Y(dropoff_location)::float8 as dropoff_lat, ST_X(dropoff_location)::float8 as dropoff_lng,
+       late_since, created_at, priority, deliver_by, sla_at_risk_since, sla_breached_at
+FROM orders
+WHERE fleet_id = $1 AND status IN ('pending', 'assigned', 'arrived', 'picked_up', 'delivery_failed')
+ORDER BY created_at
+`
+
+type ListActiveOrdersByFleetRow struct {
+	ID             uuid.UUID
+	DriverID       pgtype.UUID
+	Status         OrderStatus
+	PickupLat      float64
+	PickupLng      float64
+	DropoffLat     float64
+	DropoffLng     float64
+	LateSince      pgtype.Timestamptz
+	CreatedAt      time.Time
+	Priority       OrderPriority
+	DeliverBy      pgtype.Timestamptz
+	SlaAtRiskSince pgtype.Timestamptz
+	SlaBreachedAt  pgtype.Timestamptz
+}
+
+func (q *Queries) ListActiveOrdersByFleet(ctx context.Context, fleetID uuid.UUID) ([]ListActiveOrdersByFleetRow, error) {
+	rows, err := q.db.Query(ctx, listActiveOrdersByFleet, fleetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListActiveOrdersByFleetRow
+	for rows.Next() {
+		var i ListActiveOrdersByFleetRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.DriverID,
+			&i.Status,
+			&i.PickupLat,
+			&i.PickupLng,
+			&i.DropoffLat,
+			&i.DropoffLng,
+			&i.LateSince,
+			&i.CreatedAt,
+			&i.Priority,
+			&i.DeliverBy,
+			&i.SlaAtRiskSince,
+			&i.SlaBreachedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueScheduledOrders = `-- name: ListDueScheduledOrders :many
+SELECT id FROM orders
+WHERE status = 'scheduled' AND scheduled_pickup_at <= $1
+ORDER BY scheduled_pickup_at
+LIMIT $2
+`
+
+type ListDueScheduledOrdersParams struct {
+	ScheduledPickupAt pgtype.Timestamptz
+	Limit             int32
+}
+
+func (q *Queries) ListDueScheduledOrders(ctx context.Context, arg ListDueScheduledOrdersParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listDueScheduledOrders, arg.ScheduledPickupAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderFareLines = `-- name: ListOrderFareLines :many
+SELECT kind, description, amount_cents
+FROM order_fare_lines
+WHERE order_id = $1
+ORDER BY position
+`
+
+type ListOrderFareLinesRow struct {
+	Kind        string
+	Description string
+	AmountCents int32
+}
+
+func (q *Queries) ListOrderFareLines(ctx context.Context, orderID uuid.UUID) ([]ListOrderFareLinesRow, error) {
+	rows, err := q.db.Query(ctx, listOrderFareLines, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOrderFareLinesRow
+	for rows.Next() {
+		var i ListOrderFareLinesRow
+		if err := rows.Scan(&i.Kind, &i.Description, &i.AmountCents); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingOrders = `-- name: ListPendingOrders :many
+SELECT id FROM orders
+WHERE status = 'pending' AND driver_id IS NULL AND updated_at <= $1
+ORDER BY priority DESC, deliver_by NULLS LAST, created_at
+LIMIT $2
+`
+
+type ListPendingOrdersParams struct {
+	UpdatedAt time.Time
+	Limit     int32
+}
+
+func (q *Queries) ListPendingOrders(ctx context.Context, arg ListPendingOrdersParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listPendingOrders, arg.UpdatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSLABreachesByFleet = `-- name: ListSLABreachesByFleet :many
+SELECT id, driver_id, status, priority, deliver_by, sla_breached_at
+FROM orders
+WHERE fleet_id = $1 AND sla_breached_at >= $2::timestamptz AND sla_breached_at < $3::timestamptz
+ORDER BY sla_breached_at
+`
+
+type ListSLABreachesByFleetParams struct {
+	FleetID  uuid.UUID
+	FromTime time.Time
+	ToTime   time.Time
+}
+
+type ListSLABreachesByFleetRow struct {
+	ID            uuid.UUID
+	DriverID      pgtype.UUID
+	Status        OrderStatus
+	Priority      OrderPriority
+	DeliverBy     pgtype.Timestamptz
+	SlaBreachedAt pgtype.Timestamptz
+}
+
+func (q *Queries) ListSLABreachesByFleet(ctx context.Context, arg ListSLABreachesByFleetParams) ([]ListSLABreachesByFleetRow, error) {
+	rows, err := q.db.Query(ctx, listSLABreachesByFleet, arg.FleetID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSLABreachesByFleetRow
+	for rows.Next() {
+		var i ListSLABreachesByFleetRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.DriverID,
+			&i.Status,
+			&i.Priority,
+			&i.DeliverBy,
+			&i.SlaBreachedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markLateDelivery = `-- name: MarkLateDelivery :one
+UPDATE orders
+SET sla_breached_at = NOW()
+WHERE id = $1 AND status = 'delivered' AND deliver_by < NOW() AND sla_breached_at IS NULL
+RETURNING deliver_by
+`
+
+func (q *Queries) MarkLateDelivery(ctx context.Context, id uuid.UUID) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, markLateDelivery, id)
+	var deliver_by pgtype.Timestamptz
+	err := row.Scan(&deliver_by)
+	return deliver_by, err
+}
+
 const markOrderArrived = `-- name: MarkOrderArrived :execrows
 UPDATE orders
 SET status = 'arrived', updated_at = NOW()
@@ -112,6 +637,25 @@ func (q *Queries) MarkOrderDelivered(ctx context.Context, arg MarkOrderDelivered
 	return result.RowsAffected(), nil
 }
 
+const markOrderDeliveryFailed = `-- name: MarkOrderDeliveryFailed :execrows
+UPDATE orders
+SET status = 'delivery_failed', updated_at = NOW()
+WHERE id = $1 AND driver_id = $2 AND status = 'picked_up'
+`
+
+type MarkOrderDeliveryFailedParams struct {
+	ID       uuid.UUID
+	DriverID pgtype.UUID
+}
+
+func (q *Queries) MarkOrderDeliveryFailed(ctx context.Context, arg MarkOrderDeliveryFailedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markOrderDeliveryFailed, arg.ID, arg.DriverID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const markOrderPickedUp = `-- name: MarkOrderPickedUp :execrows
 UPDATE orders
 SET status = 'picked_up', updated_at = NOW()
@@ -131,6 +675,80 @@ func (q *Queries) MarkOrderPickedUp(ctx context.Context, arg MarkOrderPickedUpPa
 	return result.RowsAffected(), nil
 }
 
+const markOrderReturned = `-- name: MarkOrderReturned :execrows
+UPDATE orders
+SET status = 'returned', updated_at = NOW()
+WHERE id = $1 AND driver_id = $2 AND status = 'delivery_failed'
+`
+
+type MarkOrderReturnedParams struct {
+	ID       uuid.UUID
+	DriverID pgtype.UUID
+}
+
+func (q *Queries) MarkOrderReturned(ctx context.Context, arg MarkOrderReturnedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markOrderReturned, arg.ID, arg.DriverID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markSLABreaches = `-- name: MarkSLABreaches :many
+UPDATE orders
+SET sla_breached_at = NOW()
+WHERE deliver_by < NOW() AND sla_breached_at IS NULL
+  AND status IN ('pending', 'assigned', 'arrived', 'picked_up')
+RETURNING id, fleet_id, driver_id, status, priority, deliver_by
+`
+
+type MarkSLABreachesRow struct {
+	ID        uuid.UUID
+	FleetID   uuid.UUID
+	DriverID  pgtype.UUID
+	Status    OrderStatus
+	Priority  OrderPriority
+	DeliverBy pgtype.Timestamptz
+}
+
+func (q *Queries) MarkSLABreaches(ctx context.Context) ([]MarkSLABreachesRow, error) {
+	rows, err := q.db.Query(ctx, markSLABreaches)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MarkSLABreachesRow
+	for rows.Next() {
+		var i MarkSLABreachesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FleetID,
+			&i.DriverID,
+			&i.Status,
+			&i.Priority,
+			&i.DeliverBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordDispatchAttempt = `-- name: RecordDispatchAttempt :exec
+UPDATE orders
+SET updated_at = NOW()
+WHERE id = $1 AND status = 'pending'
+`
+
+func (q *Queries) RecordDispatchAttempt(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, recordDispatchAttempt, id)
+	return err
+}
+
 const rejectOrderAssignment = `-- name: RejectOrderAssignment :exec
 UPDATE orders
 SET driver_id = NULL,
@@ -149,6 +767,48 @@ func (q *Queries) RejectOrderAssignment(ctx context.Context, arg RejectOrderAssi
 	return err
 }
 
+const releaseScheduledOrder = `-- name: ReleaseScheduledOrder :execrows
+UPDATE orders
+SET status = 'pending', updated_at = NOW()
+WHERE id = $1 AND status = 'scheduled'
+`
+
+func (q *Queries) ReleaseScheduledOrder(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseScheduledOrder, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rescheduleOrder = `-- name: RescheduleOrder :execrows
+UPDATE orders
+SET scheduled_pickup_at = $2, deliver_by = $3, priority = $4, notes = $5, updated_at = NOW()
+WHERE id = $1 AND status = 'scheduled'
+`
+
+type RescheduleOrderParams struct {
+	ID                uuid.UUID
+	ScheduledPickupAt pgtype.Timestamptz
+	DeliverBy         pgtype.Timestamptz
+	Priority          OrderPriority
+	Notes             string
+}
+
+func (q *Queries) RescheduleOrder(ctx context.Context, arg RescheduleOrderParams) (int64, error) {
+	result, err := q.db.Exec(ctx, rescheduleOrder,
+		arg.ID,
+		arg.ScheduledPickupAt,
+		arg.DeliverBy,
+		arg.Priority,
+		arg.Notes,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const setDriverStatus = `-- name: SetDriverStatus :exec
 UPDATE drivers
 SET status = $2, updated_at = NOW()
@@ -164,3 +824,28 @@ func (q *Queries) SetDriverStatus(ctx context.Context, arg SetDriverStatusParams
 	_, err := q.db.Exec(ctx, setDriverStatus, arg.ID, arg.Status)
 	return err
 }
+
+const updateOrderETA = `-- name: UpdateOrderETA :exec
+UPDATE orders
+SET pickup_eta_at = $2, dropoff_eta_at = $3, late_since = $4, sla_at_risk_since = $5, eta_updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateOrderETAParams struct {
+	ID             uuid.UUID
+	PickupEtaAt    pgtype.Timestamptz
+	DropoffEtaAt   pgtype.Timestamptz
+	LateSince      pgtype.Timestamptz
+	SlaAtRiskSince pgtype.Timestamptz
+}
+
+func (q *Queries) UpdateOrderETA(ctx context.Context, arg UpdateOrderETAParams) error {
+	_, err := q.db.Exec(ctx, updateOrderETA,
+		arg.ID,
+		arg.PickupEtaAt,
+		arg.DropoffEtaAt,
+		arg.LateSince,
+		arg.SlaAtRiskSince,
+	)
+	return err
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+type PromoRepository struct {
+	q Querier
+}
+
+func NewPromoRepository(q Querier) *PromoRepository {
+	return &PromoRepository{q: q}
+}
+
+func (r *PromoRepository) GetPromoByCode(ctx context.Context, code string) (*domain.Promo, error) {
+	row, err := r.q.GetPromoCodeByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrPromoNotFound
+		}
+		return nil, err
+	}
+
+	promo := &domain.Promo{
+		ID:               row.ID.String(),
+		Code:             row.Code,
+		Kind:             domain.PromoKind(row.Kind),
+		Value:            int(row.Value),
+		MinFareCents:     int(row.MinFareCents),
+		MaxDiscountCents: int(row.MaxDiscountCents.Int32),
+		MaxRedemptions:   int(row.MaxRedemptions.Int32),
+		MaxPerCustomer:   int(row.MaxPerCustomer.Int32),
+		RedemptionCount:  int(row.RedemptionCount),
+		Currency:         row.Currency.String,
+		StartsAt:         row.StartsAt,
+	}
+	if row.FleetID.Valid {
+		promo.FleetID = uuid.UUID(row.FleetID.Bytes).String()
+	}
+	if row.EndsAt.Valid {
+		promo.EndsAt = row.EndsAt.Time
+	}
+
+	return promo, nil
+}
+
+func (r *PromoRepository) CountCustomerRedemptions(ctx context.Context, promoID, customerID string) (int, error) {
+	promoUUID, err := uuid.Parse(promoID)
+	if err != nil {
+		return 0, domain.ErrPromoNotFound
+	}
+
+	count, err := r.q.CountPromoRedemptionsByCustomer(ctx, CountPromoRedemptionsByCustomerParams{
+		PromoID:    promoUUID,
+		CustomerID: pgtype.Text{String: customerID, Valid: true},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
@@ -8,22 +8,107 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
-	AssignDriverToOrder(ctx context.Context, arg AssignDriverToOrderParams) error
+	AddOrderCharge(ctx context.Context, arg AddOrderChargeParams) error
+	AddRecurringOrderSkip(ctx context.Context, arg AddRecurringOrderSkipParams) error
+	AssignDriverToOrder(ctx context.Context, arg AssignDriverToOrderParams) (int64, error)
+	CancelRecurringOccurrences(ctx context.Context, arg CancelRecurringOccurrencesParams) ([]uuid.UUID, error)
+	CancelScheduledOrder(ctx context.Context, id uuid.UUID) (int64, error)
+	CheckServiceArea(ctx context.Context, arg CheckServiceAreaParams) (CheckServiceAreaRow, error)
+	ClaimPromoRedemption(ctx context.Context, id uuid.UUID) (int64, error)
 	ConfirmOrderAcceptance(ctx context.Context, id uuid.UUID) error
+	CountActiveOrdersByDriver(ctx context.Context, driverID pgtype.UUID) (int64, error)
+	CountDeliveryAttempts(ctx context.Context, arg CountDeliveryAttemptsParams) (int64, error)
+	CountPromoRedemptionsByCustomer(ctx context.Context, arg CountPromoRedemptionsByCustomerParams) (int64, error)
+	CreateDeliveryAttempt(ctx context.Context, arg CreateDeliveryAttemptParams) error
 	CreateDriver(ctx context.Context, arg CreateDriverParams) (CreateDriverRow, error)
+	CreateDriverEarning(ctx context.Context, arg CreateDriverEarningParams) error
 	CreateOrder(ctx context.Context, arg CreateOrderParams) (CreateOrderRow, error)
+	CreateOrderFareLine(ctx context.Context, arg CreateOrderFareLineParams) error
+	CreateOrderItem(ctx context.Context, arg CreateOrderItemParams) error
+	CreateOrderStop(ctx context.Context, arg CreateOrderStopParams) error
+	CreatePromoCode(ctx context.Context, arg CreatePromoCodeParams) (CreatePromoCodeRow, error)
+	CreatePromoRedemption(ctx context.Context, arg CreatePromoRedemptionParams) error
+	CreateRecurringOrder(ctx context.Context, arg CreateRecurringOrderParams) (CreateRecurringOrderRow, error)
+	CreateTrackingLink(ctx context.Context, arg CreateTrackingLinkParams) (CreateTrackingLinkRow, error)
+	DeletePricingZone(ctx context.Context, arg DeletePricingZoneParams) (int64, error)
+	DeletePromoRedemptionByOrder(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error)
+	DeleteRecurringOrderSkip(ctx context.Context, arg DeleteRecurringOrderSkipParams) (int64, error)
+	DeleteServiceArea(ctx context.Context, arg DeleteServiceAreaParams) (int64, error)
+	EnsureDriverLocationsPartition(ctx context.Context, month pgtype.Date) error
 	FindNearestDrivers(ctx context.Context, arg FindNearestDriversParams) ([]FindNearestDriversRow, error)
+	FindPricingZone(ctx context.Context, arg FindPricingZoneParams) (FindPricingZoneRow, error)
+	GetDeliveryProof(ctx context.Context, orderID uuid.UUID) (GetDeliveryProofRow, error)
+	GetDeliveryProofCheck(ctx context.Context, id uuid.UUID) (GetDeliveryProofCheckRow, error)
 	GetDriver(ctx context.Context, id uuid.UUID) (GetDriverRow, error)
 	GetDriverByEmail(ctx context.Context, email string) (GetDriverByEmailRow, error)
+	GetDriverLoad(ctx context.Context, id uuid.UUID) (GetDriverLoadRow, error)
+	GetFleetPricingPolicy(ctx context.Context, id uuid.UUID) (GetFleetPricingPolicyRow, error)
+	GetFleetProofPolicy(ctx context.Context, id uuid.UUID) (GetFleetProofPolicyRow, error)
+	GetOrder(ctx context.Context, id uuid.UUID) (GetOrderRow, error)
+	GetOrderDeliveryPIN(ctx context.Context, id uuid.UUID) (GetOrderDeliveryPINRow, error)
+	GetOrderTrackingTokenHash(ctx context.Context, id uuid.UUID) ([]byte, error)
+	GetPromoCodeByCode(ctx context.Context, code string) (GetPromoCodeByCodeRow, error)
+	GetRecurringOrder(ctx context.Context, id uuid.UUID) (GetRecurringOrderRow, error)
+	GetTariffScheduleByFleet(ctx context.Context, fleetID uuid.UUID) (GetTariffScheduleByFleetRow, error)
+	GetTrackingLinkByTokenHash(ctx context.Context, tokenHash []byte) (GetTrackingLinkByTokenHashRow, error)
+	GetZoneFare(ctx context.Context, arg GetZoneFareParams) (int32, error)
+	IncrementDeliveryPINAttempts(ctx context.Context, id uuid.UUID) error
+	InsertDriverLocations(ctx context.Context, arg InsertDriverLocationsParams) error
+	ListActiveOrdersByDriver(ctx context.Context, driverID pgtype.UUID) ([]ListActiveOrdersByDriverRow, error)
+	ListActiveOrdersByFleet(ctx context.Context, fleetID uuid.UUID) ([]ListActiveOrdersByFleetRow, error)
+	ListDeliveryAttempts(ctx context.Context, orderID uuid.UUID) ([]ListDeliveryAttemptsRow, error)
+	ListDriverLocations(ctx context.Context, arg ListDriverLocationsParams) ([]ListDriverLocationsRow, error)
+	ListDriverPendingStops(ctx context.Context, driverID pgtype.UUID) ([]ListDriverPendingStopsRow, error)
 	ListDriversByFleet(ctx context.Context, fleetID uuid.UUID) ([]ListDriversByFleetRow, error)
+	ListDueRecurringOrders(ctx context.Context, arg ListDueRecurringOrdersParams) ([]ListDueRecurringOrdersRow, error)
+	ListDueScheduledOrders(ctx context.Context, arg ListDueScheduledOrdersParams) ([]uuid.UUID, error)
+	ListOrderFareLines(ctx context.Context, orderID uuid.UUID) ([]ListOrderFareLinesRow, error)
+	ListOrderItems(ctx context.Context, orderID uuid.UUID) ([]ListOrderItemsRow, error)
+	ListOrderStops(ctx context.Context, orderID uuid.UUID) ([]ListOrderStopsRow, error)
+	ListPendingOrders(ctx context.Context, arg ListPendingOrdersParams) ([]uuid.UUID, error)
+	ListPricingZones(ctx context.Context, fleetID uuid.UUID) ([]ListPricingZonesRow, error)
+	ListRecurringOccurrences(ctx context.Context, arg ListRecurringOccurrencesParams) ([]pgtype.Timestamptz, error)
+	ListRecurringOrders(ctx context.Context, fleetID uuid.UUID) ([]ListRecurringOrdersRow, error)
+	ListSLABreachesByFleet(ctx context.Context, arg ListSLABreachesByFleetParams) ([]ListSLABreachesByFleetRow, error)
+	ListServiceAreas(ctx context.Context, fleetID uuid.UUID) ([]ListServiceAreasRow, error)
+	ListTariffHolidays(ctx context.Context, scheduleID uuid.UUID) ([]ListTariffHolidaysRow, error)
+	ListTariffRules(ctx context.Context, scheduleID uuid.UUID) ([]ListTariffRulesRow, error)
+	LockDriverStatus(ctx context.Context, id uuid.UUID) (DriverStatus, error)
+	MarkLateDelivery(ctx context.Context, id uuid.UUID) (pgtype.Timestamptz, error)
 	MarkOrderArrived(ctx context.Context, arg MarkOrderArrivedParams) (int64, error)
 	MarkOrderDelivered(ctx context.Context, arg MarkOrderDeliveredParams) (int64, error)
+	MarkOrderDeliveryFailed(ctx context.Context, arg MarkOrderDeliveryFailedParams) (int64, error)
 	MarkOrderPickedUp(ctx context.Context, arg MarkOrderPickedUpParams) (int64, error)
+	MarkOrderReturned(ctx context.Context, arg MarkOrderReturnedParams) (int64, error)
+	MarkOrderStopArrived(ctx context.Context, arg MarkOrderStopArrivedParams) (int64, error)
+	MarkOrderStopCompleted(ctx context.Context, arg MarkOrderStopCompletedParams) (int64, error)
+	MarkOrderStopFailed(ctx context.Context, arg MarkOrderStopFailedParams) (int64, error)
+	MarkSLABreaches(ctx context.Context) ([]MarkSLABreachesRow, error)
+	RecordDispatchAttempt(ctx context.Context, id uuid.UUID) error
 	RejectOrderAssignment(ctx context.Context, arg RejectOrderAssignmentParams) error
+	ReleaseScheduledOrder(ctx context.Context, id uuid.UUID) (int64, error)
+	RescheduleOrder(ctx context.Context, arg RescheduleOrderParams) (int64, error)
+	ResetOrderStop(ctx context.Context, arg ResetOrderStopParams) (int64, error)
+	RevokeTrackingLink(ctx context.Context, arg RevokeTrackingLinkParams) (int64, error)
 	SetDriverStatus(ctx context.Context, arg SetDriverStatusParams) error
+	SetRecurringOrderMaterializedUntil(ctx context.Context, arg SetRecurringOrderMaterializedUntilParams) error
+	SetRecurringOrderPaused(ctx context.Context, arg SetRecurringOrderPausedParams) (int64, error)
+	SumDriverEarnings(ctx context.Context, driverID uuid.UUID) ([]SumDriverEarningsRow, error)
+	UnclaimPromoRedemption(ctx context.Context, id uuid.UUID) error
+	UpdateDriverCapacity(ctx context.Context, arg UpdateDriverCapacityParams) (int64, error)
+	UpdateDriverCurrentLocations(ctx context.Context, arg UpdateDriverCurrentLocationsParams) error
+	UpdateFleetPricingPolicy(ctx context.Context, arg UpdateFleetPricingPolicyParams) (int64, error)
+	UpdateFleetProofPolicy(ctx context.Context, arg UpdateFleetProofPolicyParams) (int64, error)
+	UpdateOrderETA(ctx context.Context, arg UpdateOrderETAParams) error
+	UpdateOrderStopSequences(ctx context.Context, arg UpdateOrderStopSequencesParams) error
+	UpsertDeliveryProof(ctx context.Context, arg UpsertDeliveryProofParams) error
+	UpsertPricingZone(ctx context.Context, arg UpsertPricingZoneParams) (UpsertPricingZoneRow, error)
+	UpsertServiceArea(ctx context.Context, arg UpsertServiceAreaParams) (UpsertServiceAreaRow, error)
+	UpsertZoneFare(ctx context.Context, arg UpsertZoneFareParams) error
 }
 
 var _ Querier = (*Queries)(nil)
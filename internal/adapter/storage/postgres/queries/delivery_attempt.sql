@@ -0,0 +1,14 @@
+-- name: CountDeliveryAttempts :one
+SELECT COUNT(*)
+FROM delivery_attempts
+WHERE order_id = $1 AND position = $2;
+
+-- name: CreateDeliveryAttempt :exec
+INSERT INTO delivery_attempts (order_id, position, driver_id, reason, notes, retried)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListDeliveryAttempts :many
+SELECT position, driver_id, reason, notes, retried, created_at
+FROM delivery_attempts
+WHERE order_id = $1
+ORDER BY created_at;
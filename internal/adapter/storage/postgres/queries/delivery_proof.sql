@@ -0,0 +1,23 @@
+-- name: GetDeliveryProof :one
+SELECT order_id, photo_key, signature_key, recipient_name, pin_verified_at, updated_at
+FROM delivery_proofs
+WHERE order_id = $1 LIMIT 1;
+
+-- name: GetDeliveryProofCheck :one
+SELECT f.pod_photo, f.pod_signature, f.pod_recipient_name, f.pod_pin,
+       COALESCE(p.photo_key, '')::text as photo_key, COALESCE(p.signature_key, '')::text as signature_key,
+       COALESCE(p.recipient_name, '')::text as recipient_name, p.pin_verified_at
+FROM orders o
+JOIN fleets f ON f.id = o.fleet_id
+LEFT JOIN delivery_proofs p ON p.order_id = o.id
+WHERE o.id = $1 LIMIT 1;
+
+-- name: UpsertDeliveryProof :exec
+INSERT INTO delivery_proofs (order_id, photo_key, signature_key, recipient_name, pin_verified_at)
+VALUES (@order_id, @photo_key, @signature_key, @recipient_name, @pin_verified_at)
+ON CONFLICT (order_id) DO UPDATE
+SET photo_key = COALESCE(NULLIF(EXCLUDED.photo_key, ''), delivery_proofs.photo_key),
+    signature_key = COALESCE(NULLIF(EXCLUDED.signature_key, ''), delivery_proofs.signature_key),
+    recipient_name = COALESCE(NULLIF(EXCLUDED.recipient_name, ''), delivery_proofs.recipient_name),
+    pin_verified_at = COALESCE(delivery_proofs.pin_verified_at, EXCLUDED.pin_verified_at),
+    updated_at = NOW();
@@ -24,6 +24,28 @@ ORDER BY current_location <-> ST_SetSRID(ST_MakePoint($2, $3), 4326)
 LIMIT 10;
 
 -- name: GetDriverByEmail :one
-SELECT id, password_hash, name, status
+SELECT id, fleet_id, password_hash, name, status, role
 FROM drivers
-WHERE email = $1 LIMIT 1;
\ No newline at end of file
+WHERE email = $1 LIMIT 1;
+
+-- name: GetDriverLoad :one
+SELECT d.capacity_parcels, d.capacity_weight_grams, d.capacity_volume_cm3, d.cold_chain, d.max_item_length_cm,
+       COUNT(o.id)::int4 AS active_orders,
+       COALESCE(SUM(o.parcel_count), 0)::int4 AS parcels,
+       COALESCE(SUM(o.weight_grams), 0)::int4 AS weight_grams,
+       COALESCE(SUM(o.volume_cm3), 0)::int4 AS volume_cm3
+FROM drivers d
+LEFT JOIN orders o ON o.driver_id = d.id AND o.status IN ('assigned', 'arrived', 'picked_up', 'delivery_failed')
+WHERE d.id = $1
+GROUP BY d.id;
+
+-- name: LockDriverStatus :one
+SELECT status FROM drivers
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdateDriverCapacity :execrows
+UPDATE drivers
+SET capacity_parcels = $2, capacity_weight_grams = $3, capacity_volume_cm3 = $4,
+    cold_chain = $5, max_item_length_cm = $6, updated_at = NOW()
+WHERE id = $1;
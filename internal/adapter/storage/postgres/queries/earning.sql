@@ -0,0 +1,10 @@
+-- name: CreateDriverEarning :exec
+INSERT INTO driver_earnings (driver_id, order_id, amount_cents, currency)
+VALUES ($1, $2, $3, $4);
+
+-- name: SumDriverEarnings :many
+SELECT currency, SUM(amount_cents)::bigint as total_cents, COUNT(*) as order_count
+FROM driver_earnings
+WHERE driver_id = $1
+GROUP BY currency
+ORDER BY currency;
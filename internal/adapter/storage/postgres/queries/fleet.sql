@@ -0,0 +1,28 @@
+-- name: GetFleetPricingPolicy :one
+SELECT currency, rounding_increment, rounding_mode, minimum_fare_cents, driver_share_percent
+FROM fleets
+WHERE id = $1 LIMIT 1;
+
+-- name: GetFleetProofPolicy :one
+SELECT pod_photo, pod_signature, pod_recipient_name, pod_pin
+FROM fleets
+WHERE id = $1 LIMIT 1;
+
+-- name: UpdateFleetPricingPolicy :execrows
+UPDATE fleets
+SET currency = $2,
+    rounding_increment = $3,
+    rounding_mode = $4,
+    minimum_fare_cents = $5,
+    driver_share_percent = $6,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateFleetProofPolicy :execrows
+UPDATE fleets
+SET pod_photo = $2,
+    pod_signature = $3,
+    pod_recipient_name = $4,
+    pod_pin = $5,
+    updated_at = NOW()
+WHERE id = $1;
@@ -0,0 +1,21 @@
+-- name: EnsureDriverLocationsPartition :exec
+SELECT ensure_driver_locations_partition(@month::date);
+
+-- name: InsertDriverLocations :exec
+INSERT INTO driver_locations (driver_id, recorded_at, location)
+SELECT u.driver_id, u.recorded_at, ST_SetSRID(ST_MakePoint(u.lng, u.lat), 4326)
+FROM unnest(@driver_ids::uuid[], @recorded_ats::timestamptz[], @lngs::float8[], @lats::float8[])
+    AS u(driver_id, recorded_at, lng, lat);
+
+-- name: ListDriverLocations :many
+SELECT ST_Y(location)::float8 as lat, ST_X(location)::float8 as lng, recorded_at
+FROM driver_locations
+WHERE driver_id = $1 AND recorded_at >= $2 AND recorded_at < $3
+ORDER BY recorded_at
+LIMIT $4;
+
+-- name: UpdateDriverCurrentLocations :exec
+UPDATE drivers d
+SET current_location = ST_SetSRID(ST_MakePoint(u.lng, u.lat), 4326), updated_at = NOW()
+FROM unnest(@driver_ids::uuid[], @lngs::float8[], @lats::float8[]) AS u(driver_id, lng, lat)
+WHERE d.id = u.driver_id;
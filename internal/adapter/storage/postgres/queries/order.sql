@@ -1,12 +1,76 @@
 -- name: CreateOrder :one
-INSERT INTO orders (fleet_id, amount_cents, status, pickup_location, dropoff_location)
-VALUES ($1, $2, 'pending', ST_SetSRID(ST_MakePoint($3, $4), 4326), ST_SetSRID(ST_MakePoint($5, $6), 4326))
+INSERT INTO orders (fleet_id, amount_cents, currency, status, pickup_location, dropoff_location, tracking_token_hash,
+                    parcel_count, weight_grams, volume_cm3, scheduled_pickup_at, recurring_order_id, vehicle_type, notes,
+                    priority, deliver_by, delivery_pin_hash, fragile, cold_chain, longest_item_cm)
+VALUES ($1, $2, $3, $4, ST_SetSRID(ST_MakePoint($5, $6), 4326), ST_SetSRID(ST_MakePoint($7, $8), 4326), $9,
+        $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
 RETURNING id, created_at;
 
--- name: AssignDriverToOrder :exec
+-- name: GetOrder :one
+SELECT id, fleet_id, driver_id, amount_cents, currency, status,
+       ST_Y(pickup_location)::float8 as pickup_lat, ST_X(pickup_location)::float8 as pickup_lng,
+       ST_Y(dropoff_location)::float8 as dropoff_lat, ST_X(dropoff_location)::float8 as dropoff_lng,
+       created_at, updated_at,
+       promised_pickup_at, promised_dropoff_at, pickup_eta_at, dropoff_eta_at, eta_updated_at, late_since,
+       parcel_count, weight_grams, volume_cm3, scheduled_pickup_at, vehicle_type, notes, recurring_order_id,
+       priority, deliver_by, sla_at_risk_since, sla_breached_at, fragile, cold_chain, longest_item_cm
+FROM orders
+WHERE id = $1 LIMIT 1;
+
+-- name: GetOrderTrackingTokenHash :one
+SELECT tracking_token_hash FROM orders
+WHERE id = $1 LIMIT 1;
+
+-- name: GetOrderDeliveryPIN :one
+SELECT delivery_pin_hash, delivery_pin_attempts FROM orders
+WHERE id = $1 LIMIT 1;
+
+-- name: IncrementDeliveryPINAttempts :exec
+UPDATE orders
+SET delivery_pin_attempts = delivery_pin_attempts + 1
+WHERE id = $1;
+
+-- name: ListActiveOrdersByDriver :many
+SELECT id, status,
+       ST_Y(pickup_location)::float8 as pickup_lat, ST_X(pickup_location)::float8 as pickup_lng,
+       ST_Y(dropoff_location)::float8 as dropoff_lat, ST_X(dropoff_location)::float8 as dropoff_lng,
+       promised_pickup_at, promised_dropoff_at, pickup_eta_at, eta_updated_at, late_since,
+       deliver_by, sla_at_risk_since
+FROM orders
+WHERE driver_id = $1 AND status IN ('assigned', 'arrived', 'picked_up', 'delivery_failed')
+ORDER BY created_at;
+
+-- name: ListActiveOrdersByFleet :many
+SELECT id, driver_id, status,
+       ST_Y(pickup_location)::float8 as pickup_lat, ST_X(pickup_location)::float8 as pickup_lng,
+       ST_Y(dropoff_location)::float8 as dropoff_lat, ST_X(dropoff_location)::float8 as dropoff_lng,
+       late_since, created_at, priority, deliver_by, sla_at_risk_since, sla_breached_at
+FROM orders
+WHERE fleet_id = $1 AND status IN ('pending', 'assigned', 'arrived', 'picked_up', 'delivery_failed')
+ORDER BY created_at;
+
+-- name: UpdateOrderETA :exec
+UPDATE orders
+SET pickup_eta_at = $2, dropoff_eta_at = $3, late_since = $4, sla_at_risk_since = $5, eta_updated_at = NOW()
+WHERE id = $1;
+
+-- name: CreateOrderFareLine :exec
+INSERT INTO order_fare_lines (order_id, position, kind, description, amount_cents)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListOrderFareLines :many
+SELECT kind, description, amount_cents
+FROM order_fare_lines
+WHERE order_id = $1
+ORDER BY position;
+
+-- name: AssignDriverToOrder :execrows
 UPDATE orders
-SET driver_id = $1, status = 'assigned', updated_at = NOW()
-WHERE id = $2;
+SET driver_id = $1, status = 'assigned',
+    promised_pickup_at = $3, promised_dropoff_at = $4,
+    pickup_eta_at = $3, dropoff_eta_at = $4, eta_updated_at = NOW(), late_since = NULL,
+    sla_at_risk_since = $5, updated_at = NOW()
+WHERE id = $2 AND status IN ('pending', 'scheduled');
 
 -- name: SetDriverStatus :exec
 UPDATE drivers
@@ -38,4 +102,75 @@ WHERE id = $1 AND driver_id = $2 AND status = 'arrived';
 -- name: MarkOrderDelivered :execrows
 UPDATE orders
 SET status = 'delivered', updated_at = NOW()
-WHERE id = $1 AND driver_id = $2 AND status = 'picked_up';
\ No newline at end of file
+WHERE id = $1 AND driver_id = $2 AND status = 'picked_up';
+
+-- name: MarkOrderDeliveryFailed :execrows
+UPDATE orders
+SET status = 'delivery_failed', updated_at = NOW()
+WHERE id = $1 AND driver_id = $2 AND status = 'picked_up';
+
+-- name: MarkOrderReturned :execrows
+UPDATE orders
+SET status = 'returned', updated_at = NOW()
+WHERE id = $1 AND driver_id = $2 AND status = 'delivery_failed';
+
+-- name: AddOrderCharge :exec
+UPDATE orders
+SET amount_cents = amount_cents + $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: CountActiveOrdersByDriver :one
+SELECT COUNT(*)
+FROM orders
+WHERE driver_id = $1 AND status IN ('assigned', 'arrived', 'picked_up', 'delivery_failed');
+
+-- name: ListDueScheduledOrders :many
+SELECT id FROM orders
+WHERE status = 'scheduled' AND scheduled_pickup_at <= $1
+ORDER BY scheduled_pickup_at
+LIMIT $2;
+
+-- name: ReleaseScheduledOrder :execrows
+UPDATE orders
+SET status = 'pending', updated_at = NOW()
+WHERE id = $1 AND status = 'scheduled';
+
+-- name: RescheduleOrder :execrows
+UPDATE orders
+SET scheduled_pickup_at = $2, deliver_by = $3, priority = $4, notes = $5, updated_at = NOW()
+WHERE id = $1 AND status = 'scheduled';
+
+-- name: CancelScheduledOrder :execrows
+UPDATE orders
+SET status = 'cancelled', updated_at = NOW()
+WHERE id = $1 AND status = 'scheduled';
+
+-- name: ListPendingOrders :many
+SELECT id FROM orders
+WHERE status = 'pending' AND driver_id IS NULL AND updated_at <= $1
+ORDER BY priority DESC, deliver_by NULLS LAST, created_at
+LIMIT $2;
+
+-- name: RecordDispatchAttempt :exec
+UPDATE orders
+SET updated_at = NOW()
+WHERE id = $1 AND status = 'pending';
+
+-- name: MarkSLABreaches :many
+UPDATE orders
+SET sla_breached_at = NOW()
+WHERE deliver_by < NOW() AND sla_breached_at IS NULL
+  AND status IN ('pending', 'assigned', 'arrived', 'picked_up')
+RETURNING id, fleet_id, driver_id, status, priority, deliver_by;
+
+-- name: MarkLateDelivery :one
+UPDATE orders
+SET sla_breached_at = NOW()
+WHERE id = $1 AND status = 'delivered' AND deliver_by < NOW() AND sla_breached_at IS NULL
+RETURNING deliver_by;
+
+-- name: ListSLABreachesByFleet :many
+SELECT id, driver_id, status, priority, deliver_by, sla_breached_at
+FROM orders
+WHERE fleet_id = @fleet_id AND sla_breached_at >= @from_time::timestamptz AND sla_breached_at < @to_time::timestamptz
+ORDER BY sla_breached_at;
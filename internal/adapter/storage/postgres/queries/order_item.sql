@@ -0,0 +1,11 @@
+-- name: CreateOrderItem :exec
+INSERT INTO order_items (order_id, position, description, quantity, weight_grams, length_cm, width_cm, height_cm,
+                         fragile, cold_chain, declared_value_cents)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+
+-- name: ListOrderItems :many
+SELECT position, description, quantity, weight_grams, length_cm, width_cm, height_cm,
+       fragile, cold_chain, declared_value_cents
+FROM order_items
+WHERE order_id = $1
+ORDER BY position;
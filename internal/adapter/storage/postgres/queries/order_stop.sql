@@ -0,0 +1,62 @@
+-- name: CreateOrderStop :exec
+INSERT INTO order_stops (order_id, position, kind, location, window_start, window_end,
+                         address_line1, address_line2, city, postcode, contact_name, contact_phone, access_notes, door_code)
+VALUES (@order_id, @position, @kind, ST_SetSRID(ST_MakePoint(@lng::float8, @lat::float8), 4326), @window_start, @window_end,
+        @address_line1, @address_line2, @city, @postcode, @contact_name, @contact_phone, @access_notes, @door_code);
+
+-- name: ListOrderStops :many
+SELECT position, kind, ST_Y(location)::float8 as lat, ST_X(location)::float8 as lng,
+       status, arrived_at, completed_at, window_start, window_end, late,
+       address_line1, address_line2, city, postcode, contact_name, contact_phone, access_notes, door_code
+FROM order_stops
+WHERE order_id = $1
+ORDER BY position;
+
+-- name: ListDriverPendingStops :many
+SELECT s.order_id, s.position, s.kind, ST_Y(s.location)::float8 as lat, ST_X(s.location)::float8 as lng,
+       s.status, s.sequence, s.window_start, s.window_end
+FROM order_stops s
+JOIN orders o ON o.id = s.order_id
+WHERE o.driver_id = $1 AND o.status IN ('assigned', 'arrived', 'picked_up', 'delivery_failed')
+  AND s.status NOT IN ('completed', 'failed')
+ORDER BY s.sequence NULLS LAST, o.created_at, s.position;
+
+-- name: MarkOrderStopArrived :execrows
+UPDATE order_stops s
+SET status = 'arrived', arrived_at = NOW(), late = COALESCE(NOW() > s.window_end, FALSE)
+FROM orders o
+WHERE s.order_id = @order_id AND s.position = @position AND s.status = 'pending'
+  AND o.id = s.order_id AND o.driver_id = @driver_id
+  AND o.status IN ('assigned', 'arrived', 'picked_up', 'delivery_failed');
+
+-- name: MarkOrderStopCompleted :execrows
+UPDATE order_stops s
+SET status = 'completed', arrived_at = COALESCE(s.arrived_at, NOW()), completed_at = NOW(),
+    late = COALESCE(COALESCE(s.arrived_at, NOW()) > s.window_end, FALSE)
+FROM orders o
+WHERE s.order_id = @order_id AND s.position = @position AND s.status IN ('pending', 'arrived')
+  AND o.id = s.order_id AND o.driver_id = @driver_id
+  AND o.status IN ('assigned', 'arrived', 'picked_up', 'delivery_failed');
+
+-- name: MarkOrderStopFailed :execrows
+UPDATE order_stops s
+SET status = 'failed', arrived_at = COALESCE(s.arrived_at, NOW()), completed_at = NOW(),
+    late = COALESCE(COALESCE(s.arrived_at, NOW()) > s.window_end, FALSE)
+FROM orders o
+WHERE s.order_id = @order_id AND s.position = @position AND s.status IN ('pending', 'arrived')
+  AND o.id = s.order_id AND o.driver_id = @driver_id
+  AND o.status IN ('picked_up', 'delivery_failed');
+
+-- name: ResetOrderStop :execrows
+UPDATE order_stops s
+SET status = 'pending', arrived_at = NULL
+FROM orders o
+WHERE s.order_id = @order_id AND s.position = @position AND s.status IN ('pending', 'arrived')
+  AND o.id = s.order_id AND o.driver_id = @driver_id
+  AND o.status IN ('picked_up', 'delivery_failed');
+
+-- name: UpdateOrderStopSequences :exec
+UPDATE order_stops s
+SET sequence = u.sequence
+FROM unnest(@order_ids::uuid[], @positions::int4[], @sequences::int4[]) AS u(order_id, position, sequence)
+WHERE s.order_id = u.order_id AND s.position = u.position;
@@ -0,0 +1,34 @@
+-- name: CreatePromoCode :one
+INSERT INTO promo_codes (code, fleet_id, kind, value, min_fare_cents, max_discount_cents, max_redemptions, max_per_customer, starts_at, ends_at, currency)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, created_at;
+
+-- name: GetPromoCodeByCode :one
+SELECT id, code, fleet_id, kind, value, min_fare_cents, max_discount_cents, max_redemptions, max_per_customer, redemption_count, starts_at, ends_at, currency
+FROM promo_codes
+WHERE code = $1 LIMIT 1;
+
+-- name: ClaimPromoRedemption :execrows
+UPDATE promo_codes
+SET redemption_count = redemption_count + 1, updated_at = NOW()
+WHERE id = $1
+  AND (max_redemptions IS NULL OR redemption_count < max_redemptions);
+
+-- name: CountPromoRedemptionsByCustomer :one
+SELECT COUNT(*)
+FROM promo_redemptions
+WHERE promo_id = $1 AND customer_id = $2;
+
+-- name: CreatePromoRedemption :exec
+INSERT INTO promo_redemptions (promo_id, order_id, customer_id, discount_cents)
+VALUES ($1, $2, $3, $4);
+
+-- name: DeletePromoRedemptionByOrder :one
+DELETE FROM promo_redemptions
+WHERE order_id = $1
+RETURNING promo_id;
+
+-- name: UnclaimPromoRedemption :exec
+UPDATE promo_codes
+SET redemption_count = redemption_count - 1, updated_at = NOW()
+WHERE id = $1 AND redemption_count > 0;
@@ -0,0 +1,66 @@
+-- name: CreateRecurringOrder :one
+INSERT INTO recurring_orders (fleet_id, customer_id, name, schedule, time_zone, pickup_location, dropoff_location, vehicle_type, notes)
+VALUES ($1, $2, $3, $4, $5, ST_SetSRID(ST_MakePoint($6, $7), 4326), ST_SetSRID(ST_MakePoint($8, $9), 4326), $10, $11)
+RETURNING id, created_at;
+
+-- name: GetRecurringOrder :one
+SELECT id, fleet_id, customer_id, name, schedule, time_zone,
+       ST_Y(pickup_location)::float8 as pickup_lat, ST_X(pickup_location)::float8 as pickup_lng,
+       ST_Y(dropoff_location)::float8 as dropoff_lat, ST_X(dropoff_location)::float8 as dropoff_lng,
+       vehicle_type, notes, paused, materialized_until,
+       ARRAY(SELECT skip_date FROM recurring_order_skips s WHERE s.recurring_order_id = r.id ORDER BY skip_date)::date[] AS skip_dates
+FROM recurring_orders r
+WHERE id = $1 LIMIT 1;
+
+-- name: ListRecurringOrders :many
+SELECT id, fleet_id, customer_id, name, schedule, time_zone,
+       ST_Y(pickup_location)::float8 as pickup_lat, ST_X(pickup_location)::float8 as pickup_lng,
+       ST_Y(dropoff_location)::float8 as dropoff_lat, ST_X(dropoff_location)::float8 as dropoff_lng,
+       vehicle_type, notes, paused, materialized_until,
+       ARRAY(SELECT skip_date FROM recurring_order_skips s WHERE s.recurring_order_id = r.id ORDER BY skip_date)::date[] AS skip_dates
+FROM recurring_orders r
+WHERE fleet_id = $1
+ORDER BY name;
+
+-- name: ListDueRecurringOrders :many
+SELECT id, fleet_id, customer_id, name, schedule, time_zone,
+       ST_Y(pickup_location)::float8 as pickup_lat, ST_X(pickup_location)::float8 as pickup_lng,
+       ST_Y(dropoff_location)::float8 as dropoff_lat, ST_X(dropoff_location)::float8 as dropoff_lng,
+       vehicle_type, notes, paused, materialized_until,
+       ARRAY(SELECT skip_date FROM recurring_order_skips s WHERE s.recurring_order_id = r.id ORDER BY skip_date)::date[] AS skip_dates
+FROM recurring_orders r
+WHERE paused = FALSE AND (materialized_until IS NULL OR materialized_until < $1)
+ORDER BY materialized_until NULLS FIRST
+LIMIT $2;
+
+-- name: SetRecurringOrderPaused :execrows
+UPDATE recurring_orders
+SET paused = $2, materialized_until = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetRecurringOrderMaterializedUntil :exec
+UPDATE recurring_orders
+SET materialized_until = $2
+WHERE id = $1;
+
+-- name: AddRecurringOrderSkip :exec
+INSERT INTO recurring_order_skips (recurring_order_id, skip_date)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteRecurringOrderSkip :execrows
+DELETE FROM recurring_order_skips
+WHERE recurring_order_id = $1 AND skip_date = $2;
+
+-- name: ListRecurringOccurrences :many
+SELECT scheduled_pickup_at
+FROM orders
+WHERE recurring_order_id = @recurring_order_id AND status <> 'cancelled'
+  AND scheduled_pickup_at > @from_time::timestamptz AND scheduled_pickup_at <= @to_time::timestamptz;
+
+-- name: CancelRecurringOccurrences :many
+UPDATE orders
+SET status = 'cancelled', updated_at = NOW()
+WHERE recurring_order_id = @recurring_order_id AND status = 'scheduled'
+  AND scheduled_pickup_at >= @from_time::timestamptz AND scheduled_pickup_at < @to_time::timestamptz
+RETURNING id;
@@ -0,0 +1,30 @@
+-- name: CheckServiceArea :one
+SELECT
+    EXISTS (SELECT 1 FROM service_areas WHERE fleet_id = @fleet_id::uuid)::bool AS has_areas,
+    COALESCE((
+        SELECT array_agg(p.idx - 1 ORDER BY p.idx)
+        FROM unnest(@lngs::float8[], @lats::float8[]) WITH ORDINALITY AS p(lng, lat, idx)
+        WHERE NOT EXISTS (
+            SELECT 1 FROM service_areas
+            WHERE fleet_id = @fleet_id::uuid
+              AND ST_Covers(area, ST_SetSRID(ST_MakePoint(p.lng, p.lat), 4326))
+        )
+    ), '{}')::int4[] AS uncovered;
+
+-- name: DeleteServiceArea :execrows
+DELETE FROM service_areas
+WHERE id = $1 AND fleet_id = $2;
+
+-- name: ListServiceAreas :many
+SELECT id, name, ST_AsGeoJSON(area) as geojson
+FROM service_areas
+WHERE fleet_id = $1
+ORDER BY name;
+
+-- name: UpsertServiceArea :one
+INSERT INTO service_areas (fleet_id, name, area)
+VALUES ($1, $2, ST_Multi(ST_SetSRID(ST_GeomFromGeoJSON($3), 4326)))
+ON CONFLICT (fleet_id, name) DO UPDATE
+SET area = EXCLUDED.area,
+    updated_at = NOW()
+RETURNING id, created_at;
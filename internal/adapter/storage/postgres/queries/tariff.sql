@@ -0,0 +1,16 @@
+-- name: GetTariffScheduleByFleet :one
+SELECT id, fleet_id, time_zone
+FROM tariff_schedules
+WHERE fleet_id = $1 LIMIT 1;
+
+-- name: ListTariffRules :many
+SELECT id, name, vehicle_type, days_of_week, holidays_only, start_minute, end_minute, priority, base_cents, per_km_cents
+FROM tariff_rules
+WHERE schedule_id = $1
+ORDER BY priority DESC, name;
+
+-- name: ListTariffHolidays :many
+SELECT holiday_date, name
+FROM tariff_holidays
+WHERE schedule_id = $1
+ORDER BY holiday_date;
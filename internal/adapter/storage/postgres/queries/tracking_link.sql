@@ -0,0 +1,14 @@
+-- name: CreateTrackingLink :one
+INSERT INTO tracking_links (order_id, token_hash, expires_at)
+VALUES ($1, $2, $3)
+RETURNING id, created_at;
+
+-- name: GetTrackingLinkByTokenHash :one
+SELECT id, order_id, expires_at, revoked_at
+FROM tracking_links
+WHERE token_hash = $1 LIMIT 1;
+
+-- name: RevokeTrackingLink :execrows
+UPDATE tracking_links
+SET revoked_at = NOW()
+WHERE id = $1 AND order_id = $2 AND revoked_at IS NULL;
@@ -0,0 +1,38 @@
+-- name: FindPricingZone :one
+SELECT id, name, surcharge_cents
+FROM pricing_zones
+WHERE fleet_id = $1
+  AND ST_Contains(area, ST_SetSRID(ST_MakePoint($2, $3), 4326))
+ORDER BY ST_Area(area) ASC
+LIMIT 1;
+
+-- name: ListPricingZones :many
+SELECT id, name, surcharge_cents, ST_AsGeoJSON(area) as geojson
+FROM pricing_zones
+WHERE fleet_id = $1
+ORDER BY name;
+
+-- name: UpsertPricingZone :one
+INSERT INTO pricing_zones (fleet_id, name, surcharge_cents, area)
+VALUES ($1, $2, $3, ST_Multi(ST_SetSRID(ST_GeomFromGeoJSON($4), 4326)))
+ON CONFLICT (fleet_id, name) DO UPDATE
+SET surcharge_cents = EXCLUDED.surcharge_cents,
+    area = EXCLUDED.area,
+    updated_at = NOW()
+RETURNING id, created_at;
+
+-- name: DeletePricingZone :execrows
+DELETE FROM pricing_zones
+WHERE id = $1 AND fleet_id = $2;
+
+-- name: GetZoneFare :one
+SELECT fare_cents
+FROM zone_fares
+WHERE from_zone_id = $1 AND to_zone_id = $2 AND vehicle_type = $3
+LIMIT 1;
+
+-- name: UpsertZoneFare :exec
+INSERT INTO zone_fares (from_zone_id, to_zone_id, vehicle_type, fare_cents)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (from_zone_id, to_zone_id, vehicle_type) DO UPDATE
+SET fare_cents = EXCLUDED.fare_cents;
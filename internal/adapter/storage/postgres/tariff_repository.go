@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+type TariffRepository struct {
+	q Querier
+}
+
+func NewTariffRepository(q Querier) *TariffRepository {
+	return &TariffRepository{q: q}
+}
+
+func (r *TariffRepository) GetTariffSchedule(ctx context.Context, fleetID string) (*domain.TariffSchedule, error) {
+	fleetUUID, err := uuid.Parse(fleetID)
+	if err != nil {
+		return nil, domain.ErrTariffNotFound
+	}
+
+	schedule, err := r.q.GetTariffScheduleByFleet(ctx, fleetUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTariffNotFound
+		}
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(schedule.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("tariff schedule %s: %w", schedule.ID, err)
+	}
+
+	rules, err := r.q.ListTariffRules(ctx, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := r.q.ListTariffHolidays(ctx, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.TariffSchedule{
+		FleetID:  fleetID,
+		Location: loc,
+		Holidays: make(map[string]bool, len(holidays)),
+		Rules:    make([]domain.TariffRule, 0, len(rules)),
+	}
+
+	for _, h := range holidays {
+		if h.HolidayDate.Valid {
+			result.Holidays[h.HolidayDate.Time.Format(time.DateOnly)] = true
+		}
+	}
+
+	for _, rule := range rules {
+		result.Rules = append(result.Rules, domain.TariffRule{
+			Name:         rule.Name,
+			Vehicle:      domain.VehicleType(rule.VehicleType),
+			DaysOfWeek:   uint8(rule.DaysOfWeek),
+			HolidaysOnly: rule.HolidaysOnly,
+			StartMinute:  int(rule.StartMinute),
+			EndMinute:    int(rule.EndMinute),
+			Priority:     int(rule.Priority),
+			BaseCents:    int(rule.BaseCents),
+			PerKmCents:   int(rule.PerKmCents),
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+type ZoneRepository struct {
+	q Querier
+}
+
+func NewZoneRepository(q Querier) *ZoneRepository {
+	return &ZoneRepository{q: q}
+}
+
+func (r *ZoneRepository) FindZone(ctx context.Context, fleetID string, loc domain.Location) (*domain.PricingZone, error) {
+	fleetUUID, err := uuid.Parse(fleetID)
+	if err != nil {
+		return nil, domain.ErrZoneNotFound
+	}
+
+	zone, err := r.q.FindPricingZone(ctx, FindPricingZoneParams{
+		FleetID:       fleetUUID,
+		StMakepoint:   loc.Lng,
+		StMakepoint_2: loc.Lat,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrZoneNotFound
+		}
+		return nil, err
+	}
+
+	return &domain.PricingZone{
+		ID:             zone.ID.String(),
+		FleetID:        fleetID,
+		Name:           zone.Name,
+		SurchargeCents: int(zone.SurchargeCents),
+	}, nil
+}
+
+func (r *ZoneRepository) GetZoneFare(ctx context.Context, fromZoneID, toZoneID string, vehicle domain.VehicleType) (int, error) {
+	fromUUID, err := uuid.Parse(fromZoneID)
+	if err != nil {
+		return 0, domain.ErrZoneFareNotFound
+	}
+	toUUID, err := uuid.Parse(toZoneID)
+	if err != nil {
+		return 0, domain.ErrZoneFareNotFound
+	}
+
+	fare, err := r.q.GetZoneFare(ctx, GetZoneFareParams{
+		FromZoneID:  fromUUID,
+		ToZoneID:    toUUID,
+		VehicleType: string(vehicle),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrZoneFareNotFound
+		}
+		return 0, err
+	}
+
+	return int(fare), nil
+}
@@ -2,35 +2,144 @@ package redis
 
 import (
 	"context"
+	"log"
+	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
 )
 
+const (
+	// nearestDriversLimit is the number of drivers a proximity search
+	// returns. More candidates are fetched so that stale entries filtered
+	// out afterwards do not shrink the result.
+	nearestDriversLimit = 10
+	nearestDriversFetch = nearestDriversLimit * 3
+)
+
+// evictStaleScript removes, in one step, the drivers last seen at or before
+// ARGV[1] along with index entries that have no last-seen time at all, and
+// returns their ids. Running it atomically keeps a driver that reports in
+// mid-sweep from being evicted.
+var evictStaleScript = redis.NewScript(`
+local stale = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+for _, id in ipairs(redis.call('ZDIFF', 2, KEYS[1], KEYS[2])) do
+	table.insert(stale, id)
+end
+for _, id in ipairs(stale) do
+	redis.call('ZREM', KEYS[1], id)
+	redis.call('ZREM', KEYS[2], id)
+end
+return stale
+`)
+
+// GeoStore is the live driver index: positions in the active_drivers geo
+// set, and the time each driver last reported in active_drivers:last_seen,
+// scored in unix milliseconds.
 type GeoStore struct {
-	client *redis.Client
+	client     *redis.Client
+	staleAfter time.Duration
 }
 
-func NewGeoStore(client *redis.Client) *GeoStore {
-	return &GeoStore{client: client}
+func NewGeoStore(client *redis.Client, staleAfter time.Duration) *GeoStore {
+	return &GeoStore{client: client, staleAfter: staleAfter}
 }
 
-func (r *GeoStore) FindNearestDrivers(ctx context.Context, lat, lng float64, radiusKm float64) ([]string, error) {
-	locations, err := r.client.GeoSearch(ctx, "active_drivers", &redis.GeoSearchQuery{
-		Longitude:  lng,
-		Latitude:   lat,
-		Radius:     radiusKm,
-		RadiusUnit: "km",
-		Sort:       "ASC",
-		Count:      10,
+func (r *GeoStore) FindNearestDrivers(ctx context.Context, lat, lng float64, radiusKm float64) ([]domain.NearbyDriver, error) {
+	locations, err := r.client.GeoSearchLocation(ctx, "active_drivers", &redis.GeoSearchLocationQuery{
+		GeoSearchQuery: redis.GeoSearchQuery{
+			Longitude:  lng,
+			Latitude:   lat,
+			Radius:     radiusKm,
+			RadiusUnit: "km",
+			Sort:       "ASC",
+			Count:      nearestDriversFetch,
+		},
+		WithCoord: true,
+		WithDist:  true,
 	}).Result()
 	if err != nil {
 		return nil, err
 	}
+	if len(locations) == 0 {
+		return []domain.NearbyDriver{}, nil
+	}
+
+	ids := make([]string, len(locations))
+	for i, loc := range locations {
+		ids[i] = loc.Name
+	}
+	lastSeen, err := r.client.ZMScore(ctx, "active_drivers:last_seen", ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return freshDrivers(locations, lastSeen, time.Now().Add(-r.staleAfter), nearestDriversLimit), nil
+}
 
-	drivers := make([]string, len(locations))
+// freshDrivers keeps the first limit locations whose driver was seen after
+// cutoff. lastSeen holds the unix millisecond score of each location, zero
+// when the driver has none.
+func freshDrivers(locations []redis.GeoLocation, lastSeen []float64, cutoff time.Time, limit int) []domain.NearbyDriver {
+	drivers := make([]domain.NearbyDriver, 0, min(len(locations), limit))
 	for i, loc := range locations {
-		drivers[i] = loc
+		if len(drivers) == limit {
+			break
+		}
+		if int64(lastSeen[i]) <= cutoff.UnixMilli() {
+			continue
+		}
+		drivers = append(drivers, domain.NearbyDriver{
+			ID:         loc.Name,
+			Location:   domain.Location{Lat: loc.Latitude, Lng: loc.Longitude},
+			DistanceKm: loc.Dist,
+		})
 	}
 
-	return drivers, nil
+	return drivers
+}
+
+func (r *GeoStore) GetDriverLocation(ctx context.Context, driverID string) (domain.Location, error) {
+	positions, err := r.client.GeoPos(ctx, "active_drivers", driverID).Result()
+	if err != nil {
+		return domain.Location{}, err
+	}
+	if len(positions) == 0 || positions[0] == nil {
+		return domain.Location{}, domain.ErrDriverLocationUnknown
+	}
+
+	return domain.Location{Lat: positions[0].Latitude, Lng: positions[0].Longitude}, nil
+}
+
+// EvictStaleDrivers removes from the index the drivers that have not
+// reported a location since before, and returns their ids.
+func (r *GeoStore) EvictStaleDrivers(ctx context.Context, before time.Time) ([]string, error) {
+	return evictStaleScript.Run(ctx, r.client,
+		[]string{"active_drivers", "active_drivers:last_seen"},
+		before.UnixMilli(),
+	).StringSlice()
+}
+
+// RunSweeper evicts stale drivers every interval until ctx is cancelled,
+// passing the ids of the evicted ones to onEvict.
+func (r *GeoStore) RunSweeper(ctx context.Context, interval time.Duration, onEvict func(driverIDs []string)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			evicted, err := r.EvictStaleDrivers(ctx, time.Now().Add(-r.staleAfter))
+			if err != nil {
+				log.Printf("failed to evict stale drivers: %v", err)
+				continue
+			}
+			if len(evicted) > 0 {
+				log.Printf("evicted %d stale drivers from the geo index", len(evicted))
+				onEvict(evicted)
+			}
+		}
+	}
 }
@@ -0,0 +1,47 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreshDrivers(t *testing.T) {
+	now := time.Now()
+	cutoff := now.Add(-2 * time.Minute)
+
+	locations := []redis.GeoLocation{
+		{Name: "fresh-1", Latitude: 10.77, Longitude: 106.70, Dist: 0.1},
+		{Name: "ghost", Latitude: 10.77, Longitude: 106.70, Dist: 0.2},
+		{Name: "never-seen", Latitude: 10.77, Longitude: 106.70, Dist: 0.3},
+		{Name: "fresh-2", Latitude: 10.78, Longitude: 106.71, Dist: 0.4},
+		{Name: "fresh-3", Latitude: 10.79, Longitude: 106.72, Dist: 0.5},
+	}
+	lastSeen := []float64{
+		float64(now.UnixMilli()),
+		float64(now.Add(-time.Hour).UnixMilli()),
+		0,
+		float64(now.Add(-time.Minute).UnixMilli()),
+		float64(now.UnixMilli()),
+	}
+
+	t.Run("Drops Stale", func(t *testing.T) {
+		drivers := freshDrivers(locations, lastSeen, cutoff, 10)
+
+		ids := make([]string, len(drivers))
+		for i, d := range drivers {
+			ids[i] = d.ID
+		}
+		assert.Equal(t, []string{"fresh-1", "fresh-2", "fresh-3"}, ids)
+		assert.Equal(t, 0.4, drivers[1].DistanceKm)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		drivers := freshDrivers(locations, lastSeen, cutoff, 2)
+
+		assert.Len(t, drivers, 2)
+		assert.Equal(t, "fresh-2", drivers[1].ID)
+	})
+}
@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	"encoding/json"
 	"log"
 	"net/http"
 	"time"
@@ -13,6 +14,8 @@ const (
 	writeWait  = 10 * time.Second
 	pongWait   = 60 * time.Second
 	pingPeriod = (pongWait * 9) / 10
+
+	trackingPositionInterval = 5 * time.Second
 )
 
 var upgrader = websocket.Upgrader{
@@ -28,6 +31,8 @@ type Client struct {
 	conn     *websocket.Conn
 	send     chan []byte
 	driverID string
+	orderID  string
+	ops      *opsSubscription
 }
 
 func (c *Client) readPump() {
@@ -52,6 +57,12 @@ func (c *Client) readPump() {
 			break
 		}
 
+		// trackers and dispatchers are read-only; their reads only serve to
+		// detect closes
+		if c.orderID != "" || c.ops != nil {
+			continue
+		}
+
 		c.hub.HandleMessage(c, message)
 	}
 }
@@ -116,3 +127,33 @@ func ServeWs(hub *Hub, c *gin.Context) {
 	go client.writePump()
 	go client.readPump()
 }
+
+// ServeTracking upgrades a customer connection that has already been
+// authorized for orderID and sends snapshot as its first message. active is
+// asked again once the tracker is registered, since the order may have ended
+// after it was authorized and its trackers closed before this one joined; if
+// it has, the connection is closed after the snapshot.
+func ServeTracking(hub *Hub, c *gin.Context, orderID string, snapshot any, active func() bool) {
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	client := &Client{
+		hub:     hub,
+		conn:    conn,
+		send:    make(chan []byte, 16),
+		orderID: orderID,
+	}
+	if msgBytes, err := json.Marshal(snapshot); err == nil {
+		client.send <- msgBytes
+	}
+	hub.addTracker(client)
+	if !active() {
+		hub.removeTracker(client)
+	}
+
+	go client.writePump()
+	go client.readPump()
+}
@@ -0,0 +1,57 @@
+package websocket
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dialTracking(t *testing.T, hub *Hub, active func() bool) *websocket.Conn {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/track", func(c *gin.Context) {
+		ServeTracking(hub, c, "order-1", gin.H{"event": "ORDER_SNAPSHOT"}, active)
+	})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/track", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestServeTracking_OrderEndedBeforeRegistering(t *testing.T) {
+	hub := &Hub{}
+	conn := dialTracking(t, hub, func() bool { return false })
+
+	var snapshot map[string]any
+	require.NoError(t, conn.ReadJSON(&snapshot))
+	assert.Equal(t, "ORDER_SNAPSHOT", snapshot["event"])
+
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNoStatusReceived), "the tracker is closed after its snapshot, got %v", err)
+
+	hub.trackMu.Lock()
+	defer hub.trackMu.Unlock()
+	assert.Empty(t, hub.trackers["order-1"])
+}
+
+func TestServeTracking_ActiveOrder(t *testing.T) {
+	hub := &Hub{}
+	conn := dialTracking(t, hub, func() bool { return true })
+
+	var snapshot map[string]any
+	require.NoError(t, conn.ReadJSON(&snapshot))
+
+	hub.PublishOrderEvent("order-1", map[string]any{"event": "ORDER_STATUS"})
+	var event map[string]any
+	require.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, "ORDER_STATUS", event["event"])
+}
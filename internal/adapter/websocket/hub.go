@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
 )
 
 type TelemetryData struct {
@@ -18,6 +21,8 @@ type TelemetryData struct {
 type DispatchLogic interface {
 	AcceptAssignment(ctx context.Context, driverID uuid.UUID, orderID uuid.UUID) error
 	RejectAssignment(ctx context.Context, driverID uuid.UUID, orderID uuid.UUID) error
+	CheckLocationFix(ctx context.Context, driverID uuid.UUID, fix domain.LocationFix) error
+	UpdateDriverLocation(ctx context.Context, driverID uuid.UUID, fix domain.LocationFix) error
 }
 
 type Hub struct {
@@ -27,6 +32,16 @@ type Hub struct {
 	unregister  chan *Client
 	redisClient *redis.Client
 	svc         DispatchLogic
+
+	// trackers are customer connections watching a single order, keyed by
+	// order id. They are only written to, so they are kept out of clients.
+	trackMu      sync.Mutex
+	trackers     map[string]map[*Client]bool
+	lastPosition map[string]time.Time
+
+	// opsClients are dispatcher connections keyed by fleet id.
+	opsMu      sync.Mutex
+	opsClients map[string]map[*Client]bool
 }
 
 func NewHub(rdb *redis.Client, svc DispatchLogic) *Hub {
@@ -37,6 +52,10 @@ func NewHub(rdb *redis.Client, svc DispatchLogic) *Hub {
 		clients:     make(map[*Client]bool),
 		redisClient: rdb,
 		svc:         svc,
+
+		trackers:     make(map[string]map[*Client]bool),
+		lastPosition: make(map[string]time.Time),
+		opsClients:   make(map[string]map[*Client]bool),
 	}
 }
 
@@ -54,14 +73,44 @@ func (h *Hub) HandleMessage(client *Client, message []byte) {
 			return
 		}
 
-		if err := h.redisClient.GeoAdd(context.Background(), "active_drivers", &redis.GeoLocation{
-			Name:      client.driverID,
-			Longitude: loc.Lng,
-			Latitude:  loc.Lat,
-		}).Err(); err != nil {
+		driverUUID, err := uuid.Parse(client.driverID)
+		if err != nil {
+			return
+		}
+
+		fix := domain.LocationFix{
+			Location:   domain.Location{Lat: loc.Lat, Lng: loc.Lng},
+			RecordedAt: loc.Timestamp,
+			AccuracyM:  loc.Accuracy,
+		}
+		if fix.RecordedAt.IsZero() {
+			fix.RecordedAt = time.Now()
+		}
+		if err := h.svc.CheckLocationFix(context.Background(), driverUUID, fix); err != nil {
+			return
+		}
+
+		// the position and last-seen time are written together so the
+		// stale driver sweeper never sees one without the other
+		if _, err := h.redisClient.TxPipelined(context.Background(), func(pipe redis.Pipeliner) error {
+			pipe.GeoAdd(context.Background(), "active_drivers", &redis.GeoLocation{
+				Name:      client.driverID,
+				Longitude: loc.Lng,
+				Latitude:  loc.Lat,
+			})
+			pipe.ZAdd(context.Background(), "active_drivers:last_seen", redis.Z{
+				Score:  float64(time.Now().UnixMilli()),
+				Member: client.driverID,
+			})
+			return nil
+		}); err != nil {
 			log.Printf("failed to update location for driver %s", client.driverID)
 		}
 		log.Printf("driver %s moved to [%f, %f]", client.driverID, loc.Lat, loc.Lng)
+
+		if err := h.svc.UpdateDriverLocation(context.Background(), driverUUID, fix); err != nil {
+			log.Printf("failed to refresh eta for driver %s: %v", client.driverID, err)
+		}
 	case MsgOrderResponse:
 		var resp OrderResponsePayload
 		if err := json.Unmarshal(env.Payload, &resp); err != nil {
@@ -94,7 +143,11 @@ func (h *Hub) Run() {
 		case client := <-h.register:
 			h.clients[client] = true
 		case client := <-h.unregister:
-			if _, ok := h.clients[client]; ok {
+			if client.orderID != "" {
+				h.removeTracker(client)
+			} else if client.ops != nil {
+				h.removeOpsClient(client)
+			} else if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
 				close(client.send)
 			}
@@ -125,3 +178,82 @@ func (h *Hub) SendToDriver(driverID string, message any) {
 func (h *Hub) SetService(svc DispatchLogic) {
 	h.svc = svc
 }
+
+func (h *Hub) addTracker(client *Client) {
+	h.trackMu.Lock()
+	defer h.trackMu.Unlock()
+
+	if h.trackers == nil {
+		h.trackers = make(map[string]map[*Client]bool)
+	}
+	if h.trackers[client.orderID] == nil {
+		h.trackers[client.orderID] = make(map[*Client]bool)
+	}
+	h.trackers[client.orderID][client] = true
+}
+
+func (h *Hub) removeTracker(client *Client) {
+	h.trackMu.Lock()
+	defer h.trackMu.Unlock()
+
+	watchers := h.trackers[client.orderID]
+	if _, ok := watchers[client]; !ok {
+		return
+	}
+	delete(watchers, client)
+	close(client.send)
+	if len(watchers) == 0 {
+		delete(h.trackers, client.orderID)
+		delete(h.lastPosition, client.orderID)
+	}
+}
+
+// PublishOrderEvent sends message to every customer tracking the order. Slow
+// trackers miss messages rather than block the publisher.
+func (h *Hub) PublishOrderEvent(orderID string, message any) {
+	msgBytes, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+
+	h.trackMu.Lock()
+	defer h.trackMu.Unlock()
+
+	for client := range h.trackers[orderID] {
+		select {
+		case client.send <- msgBytes:
+		default:
+		}
+	}
+}
+
+// PublishDriverPosition forwards the driver's position to the order's
+// trackers, at most once per trackingPositionInterval.
+func (h *Hub) PublishDriverPosition(orderID string, lat, lng float64) {
+	h.trackMu.Lock()
+	if len(h.trackers[orderID]) == 0 || time.Since(h.lastPosition[orderID]) < trackingPositionInterval {
+		h.trackMu.Unlock()
+		return
+	}
+	h.lastPosition[orderID] = time.Now()
+	h.trackMu.Unlock()
+
+	h.PublishOrderEvent(orderID, map[string]any{
+		"event":    "DRIVER_LOCATION",
+		"order_id": orderID,
+		"lat":      lat,
+		"lng":      lng,
+	})
+}
+
+// CloseOrderTracking disconnects every customer tracking the order.
+func (h *Hub) CloseOrderTracking(orderID string) {
+	h.trackMu.Lock()
+	defer h.trackMu.Unlock()
+
+	for client := range h.trackers[orderID] {
+		close(client.send)
+	}
+	delete(h.trackers, orderID)
+	delete(h.lastPosition, orderID)
+}
@@ -0,0 +1,257 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+const (
+	EventSnapshot        = "OPS_SNAPSHOT"
+	EventDriverLocation  = "DRIVER_LOCATION"
+	EventDriverStatus    = "DRIVER_STATUS"
+	EventDriverAnomaly   = "DRIVER_LOCATION_ANOMALY"
+	EventOrderCreated    = "ORDER_CREATED"
+	EventOrderStatus     = "ORDER_STATUS"
+	EventOrderLate       = "ORDER_LATE"
+	EventOrderStopLate   = "ORDER_STOP_LATE"
+	EventOrderUnassigned = "ORDER_UNASSIGNED"
+	EventOrderSLAAtRisk  = "ORDER_SLA_AT_RISK"
+	EventOrderSLABreach  = "ORDER_SLA_BREACHED"
+
+	EventOrderDeliveryFailed = "ORDER_DELIVERY_FAILED"
+
+	// maxOpsBacklog bounds the deltas held for a dispatcher while its
+	// snapshot is being built.
+	maxOpsBacklog = 1024
+)
+
+// OpsEvent is a delta on a fleet's dispatcher feed. Events with an OrderID
+// are filtered as orders, the rest as drivers.
+type OpsEvent struct {
+	Event    string           `json:"event"`
+	FleetID  string           `json:"fleet_id"`
+	DriverID string           `json:"driver_id,omitempty"`
+	OrderID  string           `json:"order_id,omitempty"`
+	Status   string           `json:"status,omitempty"`
+	Location *domain.Location `json:"location,omitempty"`
+	Data     any              `json:"data,omitempty"`
+}
+
+type opsSubscription struct {
+	fleetID string
+	filter  domain.OpsFilter
+
+	// ready is set once the snapshot has been queued; deltas published
+	// before that are kept in backlog so none are lost or sent first.
+	ready   bool
+	backlog [][]byte
+
+	// last known statuses and locations let the filter apply to events that
+	// carry neither. Orders are located by the first location seen for them,
+	// their pickup point.
+	driverStatus   map[string]string
+	orderStatus    map[string]string
+	driverLocation map[string]domain.Location
+	orderLocation  map[string]domain.Location
+
+	// visible holds the drivers and orders the dispatcher last saw matching,
+	// so the event that takes one out of the filter is still delivered.
+	driverVisible map[string]bool
+	orderVisible  map[string]bool
+}
+
+func newOpsSubscription(fleetID string, filter domain.OpsFilter) *opsSubscription {
+	return &opsSubscription{
+		fleetID:        fleetID,
+		filter:         filter,
+		driverStatus:   make(map[string]string),
+		orderStatus:    make(map[string]string),
+		driverLocation: make(map[string]domain.Location),
+		orderLocation:  make(map[string]domain.Location),
+		driverVisible:  make(map[string]bool),
+		orderVisible:   make(map[string]bool),
+	}
+}
+
+func (s *opsSubscription) match(event OpsEvent) bool {
+	if event.OrderID != "" {
+		id := event.OrderID
+		if event.Status != "" {
+			s.orderStatus[id] = event.Status
+		}
+		if _, ok := s.orderLocation[id]; !ok && event.Location != nil {
+			s.orderLocation[id] = *event.Location
+		}
+		return s.show(s.orderVisible, id, s.filter.MatchOrder(domain.OrderStatus(s.orderStatus[id]), located(s.orderLocation, id)))
+	}
+
+	id := event.DriverID
+	if event.Status != "" {
+		s.driverStatus[id] = event.Status
+	}
+	if event.Location != nil {
+		s.driverLocation[id] = *event.Location
+	}
+	return s.show(s.driverVisible, id, s.filter.MatchDriver(s.driverStatus[id], located(s.driverLocation, id)))
+}
+
+// show records whether item id now matches and reports whether its event
+// should be delivered: when it matches, or when it just stopped matching.
+func (s *opsSubscription) show(visible map[string]bool, id string, matches bool) bool {
+	was := visible[id]
+	if matches {
+		visible[id] = true
+	} else {
+		delete(visible, id)
+	}
+	return matches || was
+}
+
+func located(locations map[string]domain.Location, id string) *domain.Location {
+	if loc, ok := locations[id]; ok {
+		return &loc
+	}
+	return nil
+}
+
+// PublishOps sends event to the dispatchers of its fleet whose filter
+// matches it.
+func (h *Hub) PublishOps(event OpsEvent) {
+	msgBytes, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	h.opsMu.Lock()
+	defer h.opsMu.Unlock()
+
+	for client := range h.opsClients[event.FleetID] {
+		sub := client.ops
+		if !sub.match(event) {
+			continue
+		}
+		if !sub.ready {
+			if len(sub.backlog) < maxOpsBacklog {
+				sub.backlog = append(sub.backlog, msgBytes)
+			}
+			continue
+		}
+		select {
+		case client.send <- msgBytes:
+		default:
+		}
+	}
+}
+
+func (h *Hub) addOpsClient(client *Client) {
+	h.opsMu.Lock()
+	defer h.opsMu.Unlock()
+
+	if h.opsClients == nil {
+		h.opsClients = make(map[string]map[*Client]bool)
+	}
+	fleetID := client.ops.fleetID
+	if h.opsClients[fleetID] == nil {
+		h.opsClients[fleetID] = make(map[*Client]bool)
+	}
+	h.opsClients[fleetID][client] = true
+}
+
+func (h *Hub) removeOpsClient(client *Client) {
+	h.opsMu.Lock()
+	defer h.opsMu.Unlock()
+
+	fleetID := client.ops.fleetID
+	if _, ok := h.opsClients[fleetID][client]; !ok {
+		return
+	}
+	delete(h.opsClients[fleetID], client)
+	close(client.send)
+	if len(h.opsClients[fleetID]) == 0 {
+		delete(h.opsClients, fleetID)
+	}
+}
+
+// sendOpsSnapshot queues the filtered snapshot followed by any backlogged
+// deltas, and switches the client to live delivery.
+func (h *Hub) sendOpsSnapshot(client *Client, snap domain.OpsSnapshot) {
+	h.opsMu.Lock()
+	defer h.opsMu.Unlock()
+
+	// deltas already backlogged are newer than the snapshot
+	sub := client.ops
+	for _, d := range snap.Drivers {
+		if _, ok := sub.driverStatus[d.ID]; !ok {
+			sub.driverStatus[d.ID] = d.Status
+		}
+		if _, ok := sub.driverLocation[d.ID]; !ok && d.Location != nil {
+			sub.driverLocation[d.ID] = *d.Location
+		}
+	}
+	for _, o := range snap.Orders {
+		if _, ok := sub.orderStatus[o.ID]; !ok {
+			sub.orderStatus[o.ID] = string(o.Status)
+		}
+		sub.orderLocation[o.ID] = o.Pickup
+	}
+
+	filtered := sub.filter.Apply(snap)
+	for _, d := range filtered.Drivers {
+		sub.driverVisible[d.ID] = true
+	}
+	for _, o := range filtered.Orders {
+		sub.orderVisible[o.ID] = true
+	}
+
+	msgBytes, err := json.Marshal(struct {
+		Event string `json:"event"`
+		domain.OpsSnapshot
+	}{Event: EventSnapshot, OpsSnapshot: filtered})
+	if err != nil {
+		return
+	}
+
+	client.send <- msgBytes
+	for _, delta := range sub.backlog {
+		select {
+		case client.send <- delta:
+		default:
+		}
+	}
+	sub.backlog = nil
+	sub.ready = true
+}
+
+// ServeOps upgrades a dispatcher connection to the feed of fleetID. The
+// subscription starts before snapshot is called, so deltas that happen while
+// it runs are delivered after it.
+func ServeOps(hub *Hub, c *gin.Context, fleetID string, filter domain.OpsFilter, snapshot func() (domain.OpsSnapshot, error)) {
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	client := &Client{
+		hub:  hub,
+		conn: conn,
+		send: make(chan []byte, maxOpsBacklog+1),
+		ops:  newOpsSubscription(fleetID, filter),
+	}
+	hub.addOpsClient(client)
+
+	snap, err := snapshot()
+	if err != nil {
+		log.Printf("failed to build ops snapshot for fleet %s: %v", fleetID, err)
+		hub.removeOpsClient(client)
+		conn.Close()
+		return
+	}
+	hub.sendOpsSnapshot(client, snap)
+
+	go client.writePump()
+	go client.readPump()
+}
@@ -0,0 +1,124 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+func newOpsTestClient(hub *Hub, filter domain.OpsFilter) *Client {
+	client := &Client{
+		hub:  hub,
+		send: make(chan []byte, 16),
+		ops:  newOpsSubscription("fleet-1", filter),
+	}
+	hub.addOpsClient(client)
+	return client
+}
+
+func drainEvents(t *testing.T, client *Client) []map[string]any {
+	var events []map[string]any
+	for {
+		select {
+		case msg := <-client.send:
+			var event map[string]any
+			require.NoError(t, json.Unmarshal(msg, &event))
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestHub_PublishOps_SnapshotBeforeDeltas(t *testing.T) {
+	hub := &Hub{}
+	client := newOpsTestClient(hub, domain.OpsFilter{})
+
+	hub.PublishOps(OpsEvent{Event: EventDriverStatus, FleetID: "fleet-1", DriverID: "d1", Status: "en_route"})
+	hub.PublishOps(OpsEvent{Event: EventDriverStatus, FleetID: "fleet-2", DriverID: "d9", Status: "idle"})
+	assert.Empty(t, drainEvents(t, client), "deltas are held until the snapshot is sent")
+
+	hub.sendOpsSnapshot(client, domain.OpsSnapshot{
+		FleetID: "fleet-1",
+		Drivers: []domain.OpsDriver{{ID: "d1", Status: "idle"}},
+	})
+	hub.PublishOps(OpsEvent{Event: EventOrderCreated, FleetID: "fleet-1", OrderID: "o1", Status: "pending"})
+
+	events := drainEvents(t, client)
+	require.Len(t, events, 3)
+	assert.Equal(t, EventSnapshot, events[0]["event"])
+	assert.Equal(t, EventDriverStatus, events[1]["event"])
+	assert.Equal(t, EventOrderCreated, events[2]["event"])
+}
+
+func TestHub_PublishOps_Filters(t *testing.T) {
+	hub := &Hub{}
+	client := newOpsTestClient(hub, domain.OpsFilter{
+		BBox:           &domain.BoundingBox{MinLat: 10, MinLng: 106, MaxLat: 11, MaxLng: 107},
+		DriverStatuses: map[string]bool{"idle": true},
+	})
+	hub.sendOpsSnapshot(client, domain.OpsSnapshot{
+		FleetID: "fleet-1",
+		Drivers: []domain.OpsDriver{
+			{ID: "d1", Status: "idle", Location: &domain.Location{Lat: 10.5, Lng: 106.5}},
+			{ID: "d2", Status: "en_route", Location: &domain.Location{Lat: 10.5, Lng: 106.5}},
+			{ID: "d3", Status: "idle", Location: &domain.Location{Lat: 21, Lng: 105.8}},
+		},
+	})
+
+	snapshot := drainEvents(t, client)
+	require.Len(t, snapshot, 1)
+	drivers := snapshot[0]["drivers"].([]any)
+	require.Len(t, drivers, 1)
+	assert.Equal(t, "d1", drivers[0].(map[string]any)["id"])
+
+	inside := &domain.Location{Lat: 10.6, Lng: 106.6}
+	outside := &domain.Location{Lat: 21, Lng: 105.8}
+
+	hub.PublishOps(OpsEvent{Event: EventDriverLocation, FleetID: "fleet-1", DriverID: "d1", Location: inside})
+	hub.PublishOps(OpsEvent{Event: EventDriverLocation, FleetID: "fleet-1", DriverID: "d2", Location: inside})
+	hub.PublishOps(OpsEvent{Event: EventDriverStatus, FleetID: "fleet-1", DriverID: "d1", Status: "en_route"})
+	hub.PublishOps(OpsEvent{Event: EventDriverLocation, FleetID: "fleet-1", DriverID: "d1", Location: inside})
+	hub.PublishOps(OpsEvent{Event: EventDriverLocation, FleetID: "fleet-1", DriverID: "d3", Location: inside})
+	hub.PublishOps(OpsEvent{Event: EventDriverLocation, FleetID: "fleet-1", DriverID: "d3", Location: outside})
+	hub.PublishOps(OpsEvent{Event: EventDriverStatus, FleetID: "fleet-1", DriverID: "d3", Status: "idle"})
+
+	events := drainEvents(t, client)
+	require.Len(t, events, 4)
+	assert.Equal(t, "d1", events[0]["driver_id"])
+	assert.Equal(t, EventDriverStatus, events[1]["event"], "a status change leaving the filter is still delivered")
+	assert.Equal(t, "d3", events[2]["driver_id"])
+	assert.Equal(t, "d3", events[3]["driver_id"], "a move leaving the box is still delivered")
+	assert.Equal(t, EventDriverLocation, events[3]["event"])
+}
+
+func TestHub_PublishOps_LocatesOrdersByPickup(t *testing.T) {
+	hub := &Hub{}
+	client := newOpsTestClient(hub, domain.OpsFilter{
+		BBox: &domain.BoundingBox{MinLat: 10, MinLng: 106, MaxLat: 11, MaxLng: 107},
+	})
+	hub.sendOpsSnapshot(client, domain.OpsSnapshot{
+		FleetID: "fleet-1",
+		Orders: []domain.OpsOrder{
+			{ID: "o1", Status: domain.OrderStatusAssigned, Pickup: domain.Location{Lat: 10.5, Lng: 106.5}},
+			{ID: "o2", Status: domain.OrderStatusAssigned, Pickup: domain.Location{Lat: 21, Lng: 105.8}},
+		},
+	})
+	require.Len(t, drainEvents(t, client), 1)
+
+	hub.PublishOps(OpsEvent{Event: EventOrderStatus, FleetID: "fleet-1", OrderID: "o1", Status: "picked_up"})
+	hub.PublishOps(OpsEvent{Event: EventOrderStatus, FleetID: "fleet-1", OrderID: "o2", Status: "picked_up"})
+	hub.PublishOps(OpsEvent{Event: EventOrderSLABreach, FleetID: "fleet-1", OrderID: "o2"})
+	hub.PublishOps(OpsEvent{Event: EventOrderStatus, FleetID: "fleet-1", OrderID: "o3", Status: "assigned"})
+	hub.PublishOps(OpsEvent{Event: EventOrderCreated, FleetID: "fleet-1", OrderID: "o4", Status: "pending", Location: &domain.Location{Lat: 10.2, Lng: 106.2}})
+	hub.PublishOps(OpsEvent{Event: EventOrderSLAAtRisk, FleetID: "fleet-1", OrderID: "o4"})
+
+	events := drainEvents(t, client)
+	require.Len(t, events, 3)
+	assert.Equal(t, "o1", events[0]["order_id"])
+	assert.Equal(t, "o4", events[1]["order_id"])
+	assert.Equal(t, EventOrderSLAAtRisk, events[2]["event"])
+}
@@ -1,6 +1,9 @@
 package websocket
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 type MessageType string
 
@@ -19,7 +22,12 @@ type OrderResponsePayload struct {
 	Action  string `json:"action"`
 }
 
+// LocationPayload is a driver's position. Timestamp is when the device took
+// the fix and Accuracy its radius in meters; older clients send neither, in
+// which case the fix is taken as recorded on receipt with unknown accuracy.
 type LocationPayload struct {
-	Lat float64 `json:"lat"`
-	Lng float64 `json:"lng"`
+	Lat       float64   `json:"lat"`
+	Lng       float64   `json:"lng"`
+	Accuracy  float64   `json:"accuracy,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitzero"`
 }
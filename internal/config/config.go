@@ -1,11 +1,59 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 type Config struct {
 	DBUrl      string `mapstructure:"DB_URL"`
 	ServerPort string `mapstructure:"SERVER_PORT"`
 	Env        string `mapstructure:"ENV"`
+
+	RoutingOSRMUrl      string        `mapstructure:"ROUTING_OSRM_URL"`
+	RoutingOSRMProfile  string        `mapstructure:"ROUTING_OSRM_PROFILE"`
+	RoutingDetourFactor float64       `mapstructure:"ROUTING_DETOUR_FACTOR"`
+	RoutingSpeedKmh     float64       `mapstructure:"ROUTING_SPEED_KMH"`
+	RoutingCacheTTL     time.Duration `mapstructure:"ROUTING_CACHE_TTL"`
+
+	ETARefreshInterval time.Duration `mapstructure:"ETA_REFRESH_INTERVAL"`
+	ETALateThreshold   time.Duration `mapstructure:"ETA_LATE_THRESHOLD"`
+	ETALookupInterval  time.Duration `mapstructure:"ETA_LOOKUP_INTERVAL"`
+
+	GeofenceArrivalRadiusM     float64 `mapstructure:"GEOFENCE_ARRIVAL_RADIUS_M"`
+	GeofenceAutoArrive         bool    `mapstructure:"GEOFENCE_AUTO_ARRIVE"`
+	GeofenceManualArrivalMaxM  float64 `mapstructure:"GEOFENCE_MANUAL_ARRIVAL_MAX_M"`
+	GeofenceManualArrivalCheck string  `mapstructure:"GEOFENCE_MANUAL_ARRIVAL_CHECK"`
+
+	LocationMaxSpeedKmh  float64       `mapstructure:"LOCATION_MAX_SPEED_KMH"`
+	LocationMaxAccuracyM float64       `mapstructure:"LOCATION_MAX_ACCURACY_M"`
+	LocationMaxClockSkew time.Duration `mapstructure:"LOCATION_MAX_CLOCK_SKEW"`
+
+	StackingEnabled   bool          `mapstructure:"STACKING_ENABLED"`
+	StackingMaxDetour time.Duration `mapstructure:"STACKING_MAX_DETOUR"`
+	StackingMaxOrders int           `mapstructure:"STACKING_MAX_ORDERS"`
+
+	ScheduleReleaseLead  time.Duration `mapstructure:"SCHEDULE_RELEASE_LEAD"`
+	ScheduleMaxAhead     time.Duration `mapstructure:"SCHEDULE_MAX_AHEAD"`
+	SchedulePollInterval time.Duration `mapstructure:"SCHEDULE_POLL_INTERVAL"`
+
+	RecurringHorizon time.Duration `mapstructure:"RECURRING_HORIZON"`
+
+	SLAAtRiskMargin time.Duration `mapstructure:"SLA_AT_RISK_MARGIN"`
+	RedispatchAfter time.Duration `mapstructure:"REDISPATCH_AFTER"`
+	ReservedDrivers int           `mapstructure:"RESERVED_DRIVERS"`
+
+	BlobDir string `mapstructure:"BLOB_DIR"`
+
+	DeliveryMaxAttempts int `mapstructure:"DELIVERY_MAX_ATTEMPTS"`
+	ReturnFeePercent    int `mapstructure:"RETURN_FEE_PERCENT"`
+
+	DriverStaleAfter    time.Duration `mapstructure:"DRIVER_STALE_AFTER"`
+	DriverSweepInterval time.Duration `mapstructure:"DRIVER_SWEEP_INTERVAL"`
+
+	LocationHistoryBatchSize     int           `mapstructure:"LOCATION_HISTORY_BATCH_SIZE"`
+	LocationHistoryFlushInterval time.Duration `mapstructure:"LOCATION_HISTORY_FLUSH_INTERVAL"`
 }
 
 func Load() (Config, error) {
@@ -14,6 +62,38 @@ func Load() (Config, error) {
 
 	viper.SetDefault("SERVER_PORT", "8080")
 	viper.SetDefault("ENV", "development")
+	viper.SetDefault("ROUTING_OSRM_URL", "")
+	viper.SetDefault("ROUTING_OSRM_PROFILE", "driving")
+	viper.SetDefault("ROUTING_DETOUR_FACTOR", 1.3)
+	viper.SetDefault("ROUTING_SPEED_KMH", 25.0)
+	viper.SetDefault("ROUTING_CACHE_TTL", "10m")
+	viper.SetDefault("ETA_REFRESH_INTERVAL", "15s")
+	viper.SetDefault("ETA_LATE_THRESHOLD", "5m")
+	viper.SetDefault("ETA_LOOKUP_INTERVAL", "5s")
+	viper.SetDefault("GEOFENCE_ARRIVAL_RADIUS_M", 100.0)
+	viper.SetDefault("GEOFENCE_AUTO_ARRIVE", true)
+	viper.SetDefault("GEOFENCE_MANUAL_ARRIVAL_MAX_M", 500.0)
+	viper.SetDefault("GEOFENCE_MANUAL_ARRIVAL_CHECK", "lenient")
+	viper.SetDefault("LOCATION_MAX_SPEED_KMH", 200.0)
+	viper.SetDefault("LOCATION_MAX_ACCURACY_M", 200.0)
+	viper.SetDefault("LOCATION_MAX_CLOCK_SKEW", "30s")
+	viper.SetDefault("STACKING_ENABLED", false)
+	viper.SetDefault("STACKING_MAX_DETOUR", "10m")
+	viper.SetDefault("STACKING_MAX_ORDERS", 3)
+	viper.SetDefault("SCHEDULE_RELEASE_LEAD", "15m")
+	viper.SetDefault("SCHEDULE_MAX_AHEAD", "720h")
+	viper.SetDefault("SCHEDULE_POLL_INTERVAL", "30s")
+	viper.SetDefault("RECURRING_HORIZON", "24h")
+	viper.SetDefault("SLA_AT_RISK_MARGIN", "5m")
+	viper.SetDefault("REDISPATCH_AFTER", "30s")
+	viper.SetDefault("RESERVED_DRIVERS", 0)
+	viper.SetDefault("BLOB_DIR", "./data/blobs")
+	viper.SetDefault("DELIVERY_MAX_ATTEMPTS", 2)
+	viper.SetDefault("RETURN_FEE_PERCENT", 50)
+	viper.SetDefault("DRIVER_STALE_AFTER", "2m")
+	viper.SetDefault("DRIVER_SWEEP_INTERVAL", "30s")
+	viper.SetDefault("LOCATION_HISTORY_BATCH_SIZE", 500)
+	viper.SetDefault("LOCATION_HISTORY_FLUSH_INTERVAL", "2s")
 
 	if err := viper.ReadInConfig(); err != nil {
 	}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidAddress = errors.New("an address needs at least its first line and city")
+
+// Address is where a stop is, who to meet there and how to get in. The
+// contact and access details are for the driver serving the stop only.
+type Address struct {
+	Line1        string `json:"line1"`
+	Line2        string `json:"line2,omitempty"`
+	City         string `json:"city"`
+	Postcode     string `json:"postcode,omitempty"`
+	ContactName  string `json:"contact_name,omitempty"`
+	ContactPhone string `json:"contact_phone,omitempty"`
+	AccessNotes  string `json:"access_notes,omitempty"`
+	DoorCode     string `json:"door_code,omitempty"`
+}
+
+func (a Address) Validate() error {
+	if strings.TrimSpace(a.Line1) == "" || strings.TrimSpace(a.City) == "" {
+		return ErrInvalidAddress
+	}
+	return nil
+}
+
+// Public returns the address without its contact and access details.
+func (a Address) Public() Address {
+	return Address{Line1: a.Line1, Line2: a.Line2, City: a.City, Postcode: a.Postcode}
+}
+
+// MaskPhone hides all but the last four digits of a phone number, keeping
+// its separators so it is still recognizable.
+func MaskPhone(phone string) string {
+	keep := 4
+	masked := []rune(phone)
+	for i := len(masked) - 1; i >= 0; i-- {
+		if masked[i] < '0' || masked[i] > '9' {
+			continue
+		}
+		if keep > 0 {
+			keep--
+			continue
+		}
+		masked[i] = '*'
+	}
+	return string(masked)
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskPhone(t *testing.T) {
+	assert.Equal(t, "+** *** **4567", MaskPhone("+84 912 344567"))
+	assert.Equal(t, "******4567", MaskPhone("0912344567"))
+	assert.Equal(t, "123", MaskPhone("123"))
+	assert.Equal(t, "", MaskPhone(""))
+}
+
+func TestOrder_Contacts(t *testing.T) {
+	address := func() *Address {
+		return &Address{
+			Line1: "12 Nguyen Hue", City: "Ho Chi Minh City",
+			ContactName: "Lan", ContactPhone: "0912344567", AccessNotes: "Use the side gate", DoorCode: "4821",
+		}
+	}
+	order := func() *Order {
+		return &Order{Stops: []Stop{
+			{Position: 0, Kind: StopKindPickup, Address: address()},
+			{Position: 1, Kind: StopKindDropoff},
+		}}
+	}
+
+	hidden := order()
+	hidden.HideContacts()
+	assert.Equal(t, &Address{Line1: "12 Nguyen Hue", City: "Ho Chi Minh City"}, hidden.Stops[0].Address)
+	assert.Nil(t, hidden.Stops[1].Address)
+
+	masked := order()
+	masked.MaskPhones()
+	assert.Equal(t, "******4567", masked.Stops[0].Address.ContactPhone)
+	assert.Equal(t, "4821", masked.Stops[0].Address.DoorCode)
+}
+
+func TestAddress_Validate(t *testing.T) {
+	assert.NoError(t, Address{Line1: "12 Nguyen Hue", City: "Ho Chi Minh City"}.Validate())
+	assert.ErrorIs(t, Address{Line1: "12 Nguyen Hue"}.Validate(), ErrInvalidAddress)
+	assert.ErrorIs(t, Address{City: "Hanoi", Line1: "  "}.Validate(), ErrInvalidAddress)
+}
@@ -0,0 +1,46 @@
+package domain
+
+// Load is what an order, or a driver's set of active orders, occupies in a
+// vehicle and how it must be handled. LongestCm is the longest side of any
+// of its items.
+type Load struct {
+	Parcels     int  `json:"parcels"`
+	WeightGrams int  `json:"weight_grams"`
+	VolumeCm3   int  `json:"volume_cm3"`
+	Fragile     bool `json:"fragile,omitempty"`
+	ColdChain   bool `json:"cold_chain,omitempty"`
+	LongestCm   int  `json:"longest_cm,omitempty"`
+}
+
+func (l Load) Add(other Load) Load {
+	return Load{
+		Parcels:     l.Parcels + other.Parcels,
+		WeightGrams: l.WeightGrams + other.WeightGrams,
+		VolumeCm3:   l.VolumeCm3 + other.VolumeCm3,
+		Fragile:     l.Fragile || other.Fragile,
+		ColdChain:   l.ColdChain || other.ColdChain,
+		LongestCm:   max(l.LongestCm, other.LongestCm),
+	}
+}
+
+// Capacity is the most a driver's vehicle can carry at once. Zero fields are
+// unlimited; a load needing the cold chain only fits a refrigerated vehicle.
+type Capacity struct {
+	Parcels     int  `json:"parcels,omitempty"`
+	WeightGrams int  `json:"weight_grams,omitempty"`
+	VolumeCm3   int  `json:"volume_cm3,omitempty"`
+	ColdChain   bool `json:"cold_chain,omitempty"`
+	MaxLengthCm int  `json:"max_length_cm,omitempty"`
+}
+
+func (c Capacity) Fits(load Load) bool {
+	return fits(c.Parcels, load.Parcels) &&
+		fits(c.WeightGrams, load.WeightGrams) &&
+		fits(c.VolumeCm3, load.VolumeCm3) &&
+		fits(c.MaxLengthCm, load.LongestCm) &&
+		(c.ColdChain || !load.ColdChain)
+}
+
+func fits(limit, value int) bool {
+	return limit == 0 || value <= limit
+}
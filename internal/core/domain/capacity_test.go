@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapacity_Fits(t *testing.T) {
+	current := Load{Parcels: 2, WeightGrams: 3000, VolumeCm3: 10000}
+
+	tests := []struct {
+		name     string
+		capacity Capacity
+		order    Load
+		want     bool
+	}{
+		{name: "Unlimited", capacity: Capacity{}, order: Load{Parcels: 100, WeightGrams: 1e6}, want: true},
+		{name: "Exactly Full", capacity: Capacity{Parcels: 3}, order: Load{Parcels: 1}, want: true},
+		{name: "Too Many Parcels", capacity: Capacity{Parcels: 3}, order: Load{Parcels: 2}, want: false},
+		{name: "Too Heavy", capacity: Capacity{WeightGrams: 5000}, order: Load{Parcels: 1, WeightGrams: 2500}, want: false},
+		{name: "Too Bulky", capacity: Capacity{VolumeCm3: 12000}, order: Load{Parcels: 1, VolumeCm3: 5000}, want: false},
+		{name: "Cold Chain Refrigerated", capacity: Capacity{ColdChain: true}, order: Load{Parcels: 1, ColdChain: true}, want: true},
+		{name: "Cold Chain Not Refrigerated", capacity: Capacity{}, order: Load{Parcels: 1, ColdChain: true}, want: false},
+		{name: "Item Too Long", capacity: Capacity{MaxLengthCm: 120}, order: Load{Parcels: 1, LongestCm: 150}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.capacity.Fits(current.Add(tt.order)))
+		})
+	}
+}
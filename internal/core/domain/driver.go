@@ -8,6 +8,8 @@ import (
 var (
 	ErrDriverNotFound = errors.New("driver not found")
 	ErrDriverOffline  = errors.New("driver is currently offline")
+
+	ErrDriverLocationUnknown = errors.New("driver location is unknown")
 )
 
 type DriverStatus string
@@ -31,3 +33,10 @@ type Driver struct {
 func (d *Driver) CanAcceptOrder() bool {
 	return d.Status == DriverStatusIdle
 }
+
+// NearbyDriver is a driver returned by a proximity search of the live index.
+type NearbyDriver struct {
+	ID         string
+	Location   Location
+	DistanceKm float64
+}
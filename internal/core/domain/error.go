@@ -5,4 +5,12 @@ import "errors"
 var (
 	ErrInvalidTransition = errors.New("invalid status transition: order condition not met")
 	ErrOrderNotFound     = errors.New("order not found")
+	ErrFleetNotFound     = errors.New("fleet not found")
+
+	ErrInvalidSchedule   = errors.New("scheduled pickup must be in the future and within the booking horizon")
+	ErrOrderNotScheduled = errors.New("order is no longer scheduled")
+
+	ErrTooFarFromStop = errors.New("driver is too far from the stop")
+
+	ErrInvalidTrackingToken = errors.New("invalid tracking token")
 )
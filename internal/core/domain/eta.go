@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// OrderETA is the arrival estimate of an active order. The promised times are
+// fixed when a driver is assigned; the estimates are refreshed from the
+// driver's live position.
+type OrderETA struct {
+	PromisedPickupAt  time.Time `json:"promised_pickup_at,omitzero"`
+	PromisedDropoffAt time.Time `json:"promised_dropoff_at,omitzero"`
+	PickupAt          time.Time `json:"pickup_eta_at,omitzero"`
+	DropoffAt         time.Time `json:"dropoff_eta_at,omitzero"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	LateSince         time.Time `json:"late_since,omitzero"`
+}
+
+// Delay returns how far the estimate for the order's next stop is behind its
+// promise. It is zero when there is no promise to compare against.
+func (e OrderETA) Delay(status OrderStatus) time.Duration {
+	promised, estimate := e.PromisedPickupAt, e.PickupAt
+	if status != OrderStatusAssigned {
+		promised, estimate = e.PromisedDropoffAt, e.DropoffAt
+	}
+	if promised.IsZero() || estimate.IsZero() {
+		return 0
+	}
+	return estimate.Sub(promised)
+}
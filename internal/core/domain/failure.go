@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidFailureReason = errors.New("invalid delivery failure reason")
+	ErrNotAtDropoff         = errors.New("the order's next stop is not a dropoff")
+)
+
+// FailureReason is why a driver could not complete a dropoff.
+type FailureReason string
+
+const (
+	FailureRecipientAbsent FailureReason = "recipient_absent"
+	FailureAddressNotFound FailureReason = "address_not_found"
+	FailureRefused         FailureReason = "refused"
+	FailureAccessDenied    FailureReason = "access_denied"
+	FailureDamaged         FailureReason = "damaged"
+	FailureOther           FailureReason = "other"
+)
+
+func (r FailureReason) Valid() bool {
+	switch r {
+	case FailureRecipientAbsent, FailureAddressNotFound, FailureRefused,
+		FailureAccessDenied, FailureDamaged, FailureOther:
+		return true
+	}
+	return false
+}
+
+// Retryable reports whether another attempt at the same dropoff may
+// succeed: the recipient may come back or let the driver in, but a refused,
+// damaged or unfindable delivery goes back to the sender.
+func (r FailureReason) Retryable() bool {
+	return r == FailureRecipientAbsent || r == FailureAccessDenied
+}
+
+// DeliveryAttempt is a dropoff the driver could not complete. Retried
+// records whether the stop was left for another attempt.
+type DeliveryAttempt struct {
+	Position  int           `json:"position"`
+	DriverID  string        `json:"driver_id"`
+	Reason    FailureReason `json:"reason"`
+	Notes     string        `json:"notes,omitempty"`
+	Retried   bool          `json:"retried"`
+	CreatedAt time.Time     `json:"created_at"`
+}
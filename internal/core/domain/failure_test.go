@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureReason(t *testing.T) {
+	tests := []struct {
+		reason    FailureReason
+		valid     bool
+		retryable bool
+	}{
+		{reason: FailureRecipientAbsent, valid: true, retryable: true},
+		{reason: FailureAccessDenied, valid: true, retryable: true},
+		{reason: FailureAddressNotFound, valid: true},
+		{reason: FailureRefused, valid: true},
+		{reason: FailureDamaged, valid: true},
+		{reason: FailureOther, valid: true},
+		{reason: "lost"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.reason), func(t *testing.T) {
+			assert.Equal(t, tt.valid, tt.reason.Valid())
+			assert.Equal(t, tt.retryable, tt.reason.Retryable())
+		})
+	}
+}
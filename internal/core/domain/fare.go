@@ -0,0 +1,64 @@
+package domain
+
+const DefaultCurrency = "USD"
+
+type FareLineKind string
+
+const (
+	FareLineBase      FareLineKind = "BASE"
+	FareLineDistance  FareLineKind = "DISTANCE"
+	FareLineTime      FareLineKind = "TIME"
+	FareLineSurcharge FareLineKind = "SURCHARGE"
+	FareLineSurge     FareLineKind = "SURGE"
+	FareLineDiscount  FareLineKind = "DISCOUNT"
+	FareLineTax       FareLineKind = "TAX"
+	FareLineMinimum   FareLineKind = "MINIMUM_FARE"
+	FareLineRounding  FareLineKind = "ROUNDING"
+	FareLineReturn    FareLineKind = "RETURN_FEE"
+)
+
+type FareLine struct {
+	Kind        FareLineKind `json:"kind"`
+	Description string       `json:"description"`
+	AmountCents int          `json:"amount_cents"`
+}
+
+// Fare is an itemized price in the minor unit of Currency. Discounts are
+// recorded as negative amounts so TotalCents is always the plain sum of the lines.
+type Fare struct {
+	Currency   string     `json:"currency"`
+	Lines      []FareLine `json:"lines"`
+	TotalCents int        `json:"total_cents"`
+	PromoCode  string     `json:"promo_code,omitempty"`
+}
+
+func NewFare(currency string) Fare {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return Fare{Currency: currency, Lines: []FareLine{}}
+}
+
+func (f *Fare) Add(kind FareLineKind, description string, amountCents int) {
+	f.Lines = append(f.Lines, FareLine{
+		Kind:        kind,
+		Description: description,
+		AmountCents: amountCents,
+	})
+	f.TotalCents += amountCents
+}
+
+// Subtotal sums the lines of the given kind.
+func (f Fare) Subtotal(kind FareLineKind) int {
+	total := 0
+	for _, l := range f.Lines {
+		if l.Kind == kind {
+			total += l.AmountCents
+		}
+	}
+	return total
+}
+
+func (f Fare) Total() Money {
+	return NewMoney(int64(f.TotalCents), f.Currency)
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"errors"
+	"math"
+)
+
+// MaxOrderItems bounds the items of a single order.
+const MaxOrderItems = 100
+
+var (
+	ErrInvalidItems  = errors.New("items need a description, a quantity of at least 1 and no negative measures or value")
+	ErrItemsTooLarge = errors.New("items exceed the largest weight, volume, parcel count or declared value an order can hold")
+)
+
+// Item is one line of what an order carries. WeightGrams, the dimensions and
+// DeclaredValueCents are per unit; zero measures are unknown.
+type Item struct {
+	Description        string `json:"description"`
+	Quantity           int    `json:"quantity"`
+	WeightGrams        int    `json:"weight_grams,omitempty"`
+	LengthCm           int    `json:"length_cm,omitempty"`
+	WidthCm            int    `json:"width_cm,omitempty"`
+	HeightCm           int    `json:"height_cm,omitempty"`
+	Fragile            bool   `json:"fragile,omitempty"`
+	ColdChain          bool   `json:"cold_chain,omitempty"`
+	DeclaredValueCents int    `json:"declared_value_cents,omitempty"`
+}
+
+// ValidateItems checks the items of a new order and that their totals fit
+// in an order.
+func ValidateItems(items []Item) error {
+	if len(items) > MaxOrderItems {
+		return ErrInvalidItems
+	}
+	for _, item := range items {
+		if item.Description == "" || item.Quantity < 1 {
+			return ErrInvalidItems
+		}
+		if item.WeightGrams < 0 || item.LengthCm < 0 || item.WidthCm < 0 || item.HeightCm < 0 || item.DeclaredValueCents < 0 {
+			return ErrInvalidItems
+		}
+	}
+
+	// the totals are stored as 32-bit integers, so orders whose load would
+	// not fit are rejected rather than truncated
+	var parcels, weight, volume, value int64
+	for _, item := range items {
+		itemVolume, ok := boundedProduct(item.LengthCm, item.WidthCm, item.HeightCm)
+		if !ok {
+			return ErrItemsTooLarge
+		}
+		itemWeight, ok := boundedProduct(item.Quantity, item.WeightGrams)
+		if !ok {
+			return ErrItemsTooLarge
+		}
+		itemValue, ok := boundedProduct(item.Quantity, item.DeclaredValueCents)
+		if !ok {
+			return ErrItemsTooLarge
+		}
+		if itemVolume, ok = boundedProduct(item.Quantity, int(itemVolume)); !ok {
+			return ErrItemsTooLarge
+		}
+
+		parcels += int64(item.Quantity)
+		weight += itemWeight
+		volume += itemVolume
+		value += itemValue
+		if max(parcels, weight, volume, value) > math.MaxInt32 {
+			return ErrItemsTooLarge
+		}
+	}
+	return nil
+}
+
+// boundedProduct multiplies non-negative factors, reporting false as soon
+// as the product exceeds what a 32-bit integer holds.
+func boundedProduct(factors ...int) (int64, bool) {
+	product := int64(1)
+	for _, f := range factors {
+		if f > math.MaxInt32 {
+			return 0, false
+		}
+		if product *= int64(f); product > math.MaxInt32 {
+			return 0, false
+		}
+	}
+	return product, true
+}
+
+// ItemsLoad returns what the items occupy in a vehicle: one parcel per unit,
+// their total weight and volume, whether any needs the cold chain or care
+// and the longest side among them.
+func ItemsLoad(items []Item) Load {
+	var load Load
+	for _, item := range items {
+		load = load.Add(Load{
+			Parcels:     item.Quantity,
+			WeightGrams: item.Quantity * item.WeightGrams,
+			VolumeCm3:   item.Quantity * item.LengthCm * item.WidthCm * item.HeightCm,
+			Fragile:     item.Fragile,
+			ColdChain:   item.ColdChain,
+			LongestCm:   max(item.LengthCm, item.WidthCm, item.HeightCm),
+		})
+	}
+	return load
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemsLoad(t *testing.T) {
+	items := []Item{
+		{Description: "Boxes", Quantity: 3, WeightGrams: 1000, LengthCm: 30, WidthCm: 20, HeightCm: 10},
+		{Description: "Vaccines", Quantity: 1, WeightGrams: 500, LengthCm: 40, WidthCm: 30, HeightCm: 30, ColdChain: true, Fragile: true},
+	}
+
+	assert.Equal(t, Load{
+		Parcels:     4,
+		WeightGrams: 3500,
+		VolumeCm3:   3*6000 + 36000,
+		Fragile:     true,
+		ColdChain:   true,
+		LongestCm:   40,
+	}, ItemsLoad(items))
+}
+
+func TestValidateItems(t *testing.T) {
+	assert.NoError(t, ValidateItems(nil))
+	assert.NoError(t, ValidateItems([]Item{{Description: "Letter", Quantity: 1}}))
+	assert.ErrorIs(t, ValidateItems([]Item{{Description: "Letter"}}), ErrInvalidItems)
+	assert.ErrorIs(t, ValidateItems([]Item{{Quantity: 1}}), ErrInvalidItems)
+	assert.ErrorIs(t, ValidateItems([]Item{{Description: "Box", Quantity: 1, WeightGrams: -1}}), ErrInvalidItems)
+	assert.ErrorIs(t, ValidateItems(make([]Item, MaxOrderItems+1)), ErrInvalidItems)
+}
+
+func TestValidateItems_TooLarge(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []Item
+	}{
+		{"Weight", []Item{{Description: "Steel", Quantity: 1000, WeightGrams: 3_000_000}}},
+		{"Volume", []Item{{Description: "Crate", Quantity: 1000, LengthCm: 10000, WidthCm: 10000, HeightCm: 10000}}},
+		{"Item Volume", []Item{{Description: "Crate", Quantity: 1, LengthCm: 100000, WidthCm: 100000, HeightCm: 100000}}},
+		{"Declared Value", []Item{{Description: "Gold", Quantity: 1, DeclaredValueCents: math.MaxInt32 + 1}}},
+		{"Total Declared Value", []Item{
+			{Description: "Gold", Quantity: 2, DeclaredValueCents: math.MaxInt32 / 2},
+			{Description: "Silver", Quantity: 1, DeclaredValueCents: 2},
+		}},
+		{"Parcels", []Item{{Description: "Pins", Quantity: math.MaxInt32 + 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, ValidateItems(tt.items), ErrItemsTooLarge)
+		})
+	}
+
+	assert.NoError(t, ValidateItems([]Item{{Description: "Gold", Quantity: 1, DeclaredValueCents: math.MaxInt32}}))
+}
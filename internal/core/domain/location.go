@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var (
+	ErrLocationOutOfRange  = errors.New("location is out of range")
+	ErrLocationInaccurate  = errors.New("location accuracy is too low")
+	ErrLocationClockSkew   = errors.New("location timestamp is in the future")
+	ErrLocationStale       = errors.New("location timestamp is too old")
+	ErrLocationOutOfOrder  = errors.New("location is older than the last accepted one")
+	ErrLocationImplausible = errors.New("location implies an impossible speed")
+)
+
+type Location struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// Snap rounds the location to a grid of the given number of decimal places.
+// Snapping, unlike random jitter, cannot be averaged out over many samples.
+func (l Location) Snap(decimals int) Location {
+	scale := math.Pow10(decimals)
+	return Location{
+		Lat: math.Round(l.Lat*scale) / scale,
+		Lng: math.Round(l.Lng*scale) / scale,
+	}
+}
+
+// Valid reports whether the coordinates are in range. (0, 0) is rejected too:
+// it is what most devices report when they have no fix.
+func (l Location) Valid() bool {
+	if math.IsNaN(l.Lat) || math.IsNaN(l.Lng) {
+		return false
+	}
+	if l.Lat < -90 || l.Lat > 90 || l.Lng < -180 || l.Lng > 180 {
+		return false
+	}
+	return l.Lat != 0 || l.Lng != 0
+}
+
+// LocationFix is a position reported by a driver's device, with the time it
+// was taken and its accuracy radius in meters (zero when unknown).
+type LocationFix struct {
+	Location
+	RecordedAt time.Time
+	AccuracyM  float64
+}
+
+// LocationAnomalies counts the fixes rejected for a driver, by reason.
+// ImpossibleSpeed is the usual sign of a spoofed location.
+type LocationAnomalies struct {
+	OutOfRange      int       `json:"out_of_range"`
+	Inaccurate      int       `json:"inaccurate"`
+	ClockSkew       int       `json:"clock_skew"`
+	Stale           int       `json:"stale"`
+	OutOfOrder      int       `json:"out_of_order"`
+	ImpossibleSpeed int       `json:"impossible_speed"`
+	LastReason      string    `json:"last_reason"`
+	LastAt          time.Time `json:"last_at"`
+}
+
+func (a *LocationAnomalies) Record(reason error, at time.Time) {
+	switch {
+	case errors.Is(reason, ErrLocationOutOfRange):
+		a.OutOfRange++
+	case errors.Is(reason, ErrLocationInaccurate):
+		a.Inaccurate++
+	case errors.Is(reason, ErrLocationClockSkew):
+		a.ClockSkew++
+	case errors.Is(reason, ErrLocationStale):
+		a.Stale++
+	case errors.Is(reason, ErrLocationOutOfOrder):
+		a.OutOfOrder++
+	case errors.Is(reason, ErrLocationImplausible):
+		a.ImpossibleSpeed++
+	}
+	a.LastReason = reason.Error()
+	a.LastAt = at
+}
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrCurrencyMismatch    = errors.New("currency mismatch")
+)
+
+// Currency is an ISO 4217 currency. MinorUnits is the number of decimal
+// places of its minor unit (2 for USD cents, 0 for VND).
+type Currency struct {
+	Code       string
+	MinorUnits int
+}
+
+// currencies are the currencies a fleet can price in. Each needs standard
+// and handling rates in the pricing package before it is added here.
+var currencies = map[string]Currency{
+	"USD": {Code: "USD", MinorUnits: 2},
+	"EUR": {Code: "EUR", MinorUnits: 2},
+	"SGD": {Code: "SGD", MinorUnits: 2},
+	"VND": {Code: "VND", MinorUnits: 0},
+}
+
+// CurrencyCodes returns the codes of the supported currencies, sorted.
+func CurrencyCodes() []string {
+	return slices.Sorted(maps.Keys(currencies))
+}
+
+func LookupCurrency(code string) (Currency, error) {
+	c, ok := currencies[strings.ToUpper(code)]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, code)
+	}
+	return c, nil
+}
+
+// Money is an amount in the minor unit of its currency. Amounts stored in
+// *_cents columns and fields are minor units of the accompanying currency.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Percent returns pct percent of m rounded with the given rule.
+func (m Money) Percent(pct int, rule RoundingRule) Money {
+	return Money{Amount: rule.Round(m.Amount * int64(pct) / 100), Currency: m.Currency}
+}
+
+func (m Money) String() string {
+	c, err := LookupCurrency(m.Currency)
+	if err != nil || c.MinorUnits == 0 {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+
+	scale := int64(math.Pow10(c.MinorUnits))
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%0*d %s", sign, amount/scale, c.MinorUnits, amount%scale, m.Currency)
+}
+
+type RoundingMode string
+
+const (
+	RoundingNearest RoundingMode = "nearest"
+	RoundingUp      RoundingMode = "up"
+	RoundingDown    RoundingMode = "down"
+)
+
+// RoundingRule rounds minor-unit amounts to a multiple of Increment, e.g.
+// Increment 5 for the nearest 5 cents or 100 for the nearest 100 VND.
+type RoundingRule struct {
+	Increment int64
+	Mode      RoundingMode
+}
+
+func (r RoundingRule) Round(amount int64) int64 {
+	inc := r.Increment
+	if inc <= 1 {
+		return amount
+	}
+
+	floor := amount / inc * inc
+	if amount < 0 && floor != amount {
+		floor -= inc
+	}
+	if floor == amount {
+		return amount
+	}
+
+	switch r.Mode {
+	case RoundingUp:
+		return floor + inc
+	case RoundingDown:
+		return floor
+	default:
+		if (amount-floor)*2 >= inc {
+			return floor + inc
+		}
+		return floor
+	}
+}
+
+// FleetPricingPolicy holds the money settings a fleet prices and pays in.
+type FleetPricingPolicy struct {
+	Currency           string
+	Rounding           RoundingRule
+	MinimumFareCents   int
+	DriverSharePercent int
+}
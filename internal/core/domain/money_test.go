@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundingRule_Round(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     RoundingRule
+		amount   int64
+		expected int64
+	}{
+		{name: "No Increment", rule: RoundingRule{Increment: 1, Mode: RoundingNearest}, amount: 1234, expected: 1234},
+		{name: "Nearest 5 Cents Down", rule: RoundingRule{Increment: 5, Mode: RoundingNearest}, amount: 1232, expected: 1230},
+		{name: "Nearest 5 Cents Half Up", rule: RoundingRule{Increment: 5, Mode: RoundingNearest}, amount: 1233, expected: 1235},
+		{name: "Nearest 100 VND", rule: RoundingRule{Increment: 100, Mode: RoundingNearest}, amount: 15450, expected: 15500},
+		{name: "Nearest 100 VND Below Half", rule: RoundingRule{Increment: 100, Mode: RoundingNearest}, amount: 15449, expected: 15400},
+		{name: "Always Up", rule: RoundingRule{Increment: 1000, Mode: RoundingUp}, amount: 15001, expected: 16000},
+		{name: "Always Down", rule: RoundingRule{Increment: 1000, Mode: RoundingDown}, amount: 15999, expected: 15000},
+		{name: "Already Rounded", rule: RoundingRule{Increment: 1000, Mode: RoundingUp}, amount: 15000, expected: 15000},
+		{name: "Negative Nearest", rule: RoundingRule{Increment: 5, Mode: RoundingNearest}, amount: -7, expected: -5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.rule.Round(tt.amount))
+		})
+	}
+}
+
+func TestMoney(t *testing.T) {
+	usd := NewMoney(1999, "USD")
+	vnd := NewMoney(25000, "VND")
+
+	assert.Equal(t, "19.99 USD", usd.String())
+	assert.Equal(t, "-0.05 USD", NewMoney(-5, "USD").String())
+	assert.Equal(t, "25000 VND", vnd.String())
+
+	sum, err := usd.Add(NewMoney(1, "USD"))
+	assert.NoError(t, err)
+	assert.Equal(t, NewMoney(2000, "USD"), sum)
+
+	_, err = usd.Add(vnd)
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	share := vnd.Percent(80, RoundingRule{Increment: 100, Mode: RoundingDown})
+	assert.Equal(t, NewMoney(20000, "VND"), share)
+}
+
+func TestLookupCurrency(t *testing.T) {
+	vnd, err := LookupCurrency("vnd")
+	assert.NoError(t, err)
+	assert.Equal(t, Currency{Code: "VND", MinorUnits: 0}, vnd)
+
+	_, err = LookupCurrency("GBP")
+	assert.ErrorIs(t, err, ErrUnsupportedCurrency)
+}
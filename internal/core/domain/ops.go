@@ -0,0 +1,95 @@
+package domain
+
+import "time"
+
+// BoundingBox is an axis-aligned lat/lng rectangle. Boxes crossing the
+// antimeridian are not supported.
+type BoundingBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLng float64 `json:"min_lng"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLng float64 `json:"max_lng"`
+}
+
+func (b BoundingBox) Contains(loc Location) bool {
+	return loc.Lat >= b.MinLat && loc.Lat <= b.MaxLat &&
+		loc.Lng >= b.MinLng && loc.Lng <= b.MaxLng
+}
+
+// OpsFilter narrows a dispatcher feed. Empty fields match everything. Items
+// without a known status are never filtered out by status, but a bounding
+// box leaves out items without a known location.
+type OpsFilter struct {
+	BBox           *BoundingBox
+	DriverStatuses map[string]bool
+	OrderStatuses  map[OrderStatus]bool
+}
+
+func (f OpsFilter) MatchDriver(status string, loc *Location) bool {
+	if len(f.DriverStatuses) > 0 && status != "" && !f.DriverStatuses[status] {
+		return false
+	}
+	return f.matchLocation(loc)
+}
+
+func (f OpsFilter) MatchOrder(status OrderStatus, loc *Location) bool {
+	if len(f.OrderStatuses) > 0 && status != "" && !f.OrderStatuses[status] {
+		return false
+	}
+	return f.matchLocation(loc)
+}
+
+func (f OpsFilter) matchLocation(loc *Location) bool {
+	return f.BBox == nil || (loc != nil && f.BBox.Contains(*loc))
+}
+
+type OpsDriver struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Phone    string    `json:"phone"`
+	Status   string    `json:"status"`
+	Location *Location `json:"location,omitempty"`
+
+	Anomalies *LocationAnomalies `json:"anomalies,omitempty"`
+}
+
+type OpsOrder struct {
+	ID        string        `json:"id"`
+	DriverID  string        `json:"driver_id,omitempty"`
+	Status    OrderStatus   `json:"status"`
+	Priority  OrderPriority `json:"priority"`
+	Pickup    Location      `json:"pickup"`
+	Dropoff   Location      `json:"dropoff"`
+	LateSince time.Time     `json:"late_since,omitzero"`
+	SLA       *SLA          `json:"sla,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// OpsSnapshot is the state of a fleet sent when a dispatcher connects.
+// Orders holds active orders only; Unassigned counts the pending ones.
+type OpsSnapshot struct {
+	FleetID    string      `json:"fleet_id"`
+	Drivers    []OpsDriver `json:"drivers"`
+	Orders     []OpsOrder  `json:"orders"`
+	Unassigned int         `json:"unassigned"`
+}
+
+// Apply returns the part of the snapshot matching the filter. Orders are
+// located by their pickup point.
+func (f OpsFilter) Apply(snap OpsSnapshot) OpsSnapshot {
+	out := OpsSnapshot{FleetID: snap.FleetID, Drivers: []OpsDriver{}, Orders: []OpsOrder{}}
+	for _, d := range snap.Drivers {
+		if f.MatchDriver(d.Status, d.Location) {
+			out.Drivers = append(out.Drivers, d)
+		}
+	}
+	for _, o := range snap.Orders {
+		if f.MatchOrder(o.Status, &o.Pickup) {
+			out.Orders = append(out.Orders, o)
+			if o.Status == OrderStatusPending {
+				out.Unassigned++
+			}
+		}
+	}
+	return out
+}
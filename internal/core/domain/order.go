@@ -0,0 +1,75 @@
+package domain
+
+import "time"
+
+type OrderStatus string
+
+const (
+	OrderStatusScheduled OrderStatus = "scheduled"
+	OrderStatusPending   OrderStatus = "pending"
+	OrderStatusAssigned  OrderStatus = "assigned"
+	OrderStatusArrived   OrderStatus = "arrived"
+	OrderStatusPickedUp  OrderStatus = "picked_up"
+	OrderStatusDelivered OrderStatus = "delivered"
+	OrderStatusCancelled OrderStatus = "cancelled"
+
+	// OrderStatusDeliveryFailed is an order the driver could not deliver
+	// and is taking back to its pickup, where it becomes returned.
+	OrderStatusDeliveryFailed OrderStatus = "delivery_failed"
+	OrderStatusReturned       OrderStatus = "returned"
+)
+
+// IsActive reports whether the order can still change, i.e. it has been
+// neither delivered, returned nor cancelled.
+func (s OrderStatus) IsActive() bool {
+	return s != OrderStatusDelivered && s != OrderStatusReturned && s != OrderStatusCancelled
+}
+
+type Order struct {
+	ID        string
+	FleetID   string
+	DriverID  string
+	Status    OrderStatus
+	Pickup    Location
+	Dropoff   Location
+	Stops     []Stop
+	Load      Load
+	Items     []Item
+	Vehicle   VehicleType
+	Notes     string
+	Priority  OrderPriority
+	Fare      Fare
+	ETA       *OrderETA
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// ScheduledPickupAt is when the order was booked to be picked up; zero
+	// for orders dispatched as soon as they are created.
+	ScheduledPickupAt time.Time
+	// RecurringOrderID is the template the order was booked from, if any.
+	RecurringOrderID string
+	// SLA is the order's delivery deadline, nil when it has none.
+	SLA *SLA
+}
+
+// HideContacts strips the contact and access details from the order's
+// addresses, for callers who may see the order but not contact its
+// recipients.
+func (o *Order) HideContacts() {
+	for i, stop := range o.Stops {
+		if stop.Address != nil {
+			public := stop.Address.Public()
+			o.Stops[i].Address = &public
+		}
+	}
+}
+
+// MaskPhones masks the contact phone numbers of the order's addresses, which
+// are no longer needed once it can no longer change.
+func (o *Order) MaskPhones() {
+	for _, stop := range o.Stops {
+		if stop.Address != nil {
+			stop.Address.ContactPhone = MaskPhone(stop.Address.ContactPhone)
+		}
+	}
+}
@@ -2,9 +2,14 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrNoRates means the fleet's currency has no default rates and the trip is
+// not covered by the fleet's own tariffs.
+var ErrNoRates = errors.New("no rates for this currency")
+
 type VehicleType string
 
 const (
@@ -13,12 +18,22 @@ const (
 	VehicleTruck VehicleType = "TRUCK"
 )
 
+// PricingInput is what a trip is priced on. Load carries the order's weight
+// and handling needs for surcharges.
 type PricingInput struct {
+	FleetID        string
+	Currency       string
+	Pickup         Location
+	Dropoff        Location
 	DistanceMeters float64
+	Duration       time.Duration
 	Vehicle        VehicleType
+	Load           Load
 	Time           time.Time
+	CustomerID     string
+	PromoCode      string
 }
 
 type PricingStrategy interface {
-	CalculatePrice(ctx context.Context, input PricingInput) (int, error)
+	CalculatePrice(ctx context.Context, input PricingInput) (Fare, error)
 }
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrForbidden = errors.New("not allowed for this account")
+
+// Role is what an account may do within its fleet. Each role may do
+// everything the roles below it may.
+type Role string
+
+const (
+	// RoleDriver delivers orders.
+	RoleDriver Role = "driver"
+	// RoleDispatcher also runs the fleet's day-to-day operations: its
+	// orders, drivers and live feed.
+	RoleDispatcher Role = "dispatcher"
+	// RoleAdmin also configures the fleet: pricing, zones, service areas,
+	// promos and proof of delivery requirements.
+	RoleAdmin Role = "admin"
+)
+
+func (r Role) rank() int {
+	switch r {
+	case RoleDriver:
+		return 1
+	case RoleDispatcher:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Principal is the authenticated caller of the API.
+type Principal struct {
+	DriverID string
+	FleetID  string
+	Role     Role
+}
+
+// Can reports whether the caller belongs to fleetID and holds at least role.
+func (p Principal) Can(fleetID string, role Role) bool {
+	if p.FleetID == "" || !strings.EqualFold(p.FleetID, fleetID) {
+		return false
+	}
+	return p.Role.rank() >= role.rank() && role.rank() > 0
+}
+
+// Is reports whether the caller is the driver driverID.
+func (p Principal) Is(driverID string) bool {
+	return p.DriverID != "" && strings.EqualFold(p.DriverID, driverID)
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipal_Can(t *testing.T) {
+	const fleet = "5f0c6a52-4a4e-4f7e-9d0b-0a8f7c2b1e11"
+	const other = "9b1deb4d-3b7d-4bad-9bdd-2b0d7b3dcb6d"
+
+	tests := []struct {
+		name      string
+		principal Principal
+		fleetID   string
+		role      Role
+		want      bool
+	}{
+		{name: "Driver As Driver", principal: Principal{FleetID: fleet, Role: RoleDriver}, fleetID: fleet, role: RoleDriver, want: true},
+		{name: "Driver As Dispatcher", principal: Principal{FleetID: fleet, Role: RoleDriver}, fleetID: fleet, role: RoleDispatcher, want: false},
+		{name: "Admin As Dispatcher", principal: Principal{FleetID: fleet, Role: RoleAdmin}, fleetID: fleet, role: RoleDispatcher, want: true},
+		{name: "Dispatcher As Admin", principal: Principal{FleetID: fleet, Role: RoleDispatcher}, fleetID: fleet, role: RoleAdmin, want: false},
+		{name: "Admin Of Other Fleet", principal: Principal{FleetID: other, Role: RoleAdmin}, fleetID: fleet, role: RoleDriver, want: false},
+		{name: "Fleet Id Case", principal: Principal{FleetID: fleet, Role: RoleAdmin}, fleetID: "5F0C6A52-4A4E-4F7E-9D0B-0A8F7C2B1E11", role: RoleAdmin, want: true},
+		{name: "No Fleet", principal: Principal{Role: RoleAdmin}, fleetID: "", role: RoleDriver, want: false},
+		{name: "Unknown Role", principal: Principal{FleetID: fleet, Role: "owner"}, fleetID: fleet, role: RoleDriver, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.principal.Can(tt.fleetID, tt.role))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrPromoNotFound         = errors.New("promo code not found")
+	ErrPromoNotActive        = errors.New("promo code is not active")
+	ErrPromoNotApplicable    = errors.New("promo code does not apply to this fleet")
+	ErrPromoCurrency         = errors.New("promo code does not apply to fares in this currency")
+	ErrPromoMinFare          = errors.New("fare is below the promo code minimum")
+	ErrPromoExhausted        = errors.New("promo code has reached its usage limit")
+	ErrPromoCustomerRequired = errors.New("promo code requires a customer id")
+	ErrPromoCustomerLimit    = errors.New("customer has reached the usage limit for this promo code")
+)
+
+type PromoKind string
+
+const (
+	PromoKindPercentage PromoKind = "percentage"
+	PromoKindFixed      PromoKind = "fixed"
+)
+
+// Promo is a discount rule. Value is a percentage for PromoKindPercentage and
+// an amount in the minor unit of Currency for PromoKindFixed, as are
+// MinFareCents and MaxDiscountCents. Zero limits mean unlimited, an empty
+// FleetID applies to every fleet and an empty Currency, for promos without
+// amounts, to every currency.
+type Promo struct {
+	ID               string
+	Code             string
+	FleetID          string
+	Kind             PromoKind
+	Value            int
+	MinFareCents     int
+	MaxDiscountCents int
+	MaxRedemptions   int
+	MaxPerCustomer   int
+	RedemptionCount  int
+	Currency         string
+	StartsAt         time.Time
+	EndsAt           time.Time
+}
+
+func (p *Promo) ActiveAt(t time.Time) bool {
+	if t.Before(p.StartsAt) {
+		return false
+	}
+	return p.EndsAt.IsZero() || t.Before(p.EndsAt)
+}
+
+// Discount validates the promo against an order and returns the discount in
+// the minor unit of the fare's currency, never more than the fare. Usage
+// limits are only pre-checked here; they are enforced when the redemption is
+// recorded.
+func (p *Promo) Discount(fleetID, customerID string, fare Money, at time.Time) (int, error) {
+	if !p.ActiveAt(at) {
+		return 0, ErrPromoNotActive
+	}
+	if p.FleetID != "" && p.FleetID != fleetID {
+		return 0, ErrPromoNotApplicable
+	}
+	if p.Currency != "" && p.Currency != fare.Currency {
+		return 0, ErrPromoCurrency
+	}
+	fareCents := int(fare.Amount)
+	if p.MaxPerCustomer > 0 && customerID == "" {
+		return 0, ErrPromoCustomerRequired
+	}
+	if p.MaxRedemptions > 0 && p.RedemptionCount >= p.MaxRedemptions {
+		return 0, ErrPromoExhausted
+	}
+	if fareCents < p.MinFareCents {
+		return 0, ErrPromoMinFare
+	}
+
+	var discount int
+	switch p.Kind {
+	case PromoKindPercentage:
+		discount = fareCents * p.Value / 100
+	case PromoKindFixed:
+		discount = p.Value
+	}
+
+	if p.MaxDiscountCents > 0 && discount > p.MaxDiscountCents {
+		discount = p.MaxDiscountCents
+	}
+	if discount > fareCents {
+		discount = fareCents
+	}
+
+	return discount, nil
+}
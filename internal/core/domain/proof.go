@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrProofIncomplete    = errors.New("proof of delivery is incomplete")
+	ErrInvalidProofImage  = errors.New("proof images must be JPEG or PNG")
+	ErrInvalidDeliveryPIN = errors.New("delivery PIN does not match")
+	ErrDeliveryPINLocked  = errors.New("too many wrong delivery PIN attempts")
+	ErrProofNotFound      = errors.New("proof of delivery not found")
+)
+
+// ProofRequirements is the evidence a fleet's drivers must collect before an
+// order counts as delivered.
+type ProofRequirements struct {
+	Photo         bool `json:"photo"`
+	Signature     bool `json:"signature"`
+	RecipientName bool `json:"recipient_name"`
+	PIN           bool `json:"pin"`
+}
+
+// ProofOfDelivery is the evidence collected for an order. PhotoKey and
+// SignatureKey name the images in blob storage.
+type ProofOfDelivery struct {
+	OrderID       string    `json:"order_id"`
+	PhotoKey      string    `json:"photo_key,omitempty"`
+	SignatureKey  string    `json:"signature_key,omitempty"`
+	RecipientName string    `json:"recipient_name,omitempty"`
+	PINVerifiedAt time.Time `json:"pin_verified_at,omitzero"`
+	UpdatedAt     time.Time `json:"updated_at,omitzero"`
+}
+
+// Missing lists the required evidence that proof lacks, in a stable order.
+func (r ProofRequirements) Missing(proof ProofOfDelivery) []string {
+	var missing []string
+	if r.Photo && proof.PhotoKey == "" {
+		missing = append(missing, "photo")
+	}
+	if r.Signature && proof.SignatureKey == "" {
+		missing = append(missing, "signature")
+	}
+	if r.RecipientName && proof.RecipientName == "" {
+		missing = append(missing, "recipient_name")
+	}
+	if r.PIN && proof.PINVerifiedAt.IsZero() {
+		missing = append(missing, "pin")
+	}
+	return missing
+}
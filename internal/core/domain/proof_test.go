@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProofRequirements_Missing(t *testing.T) {
+	all := ProofRequirements{Photo: true, Signature: true, RecipientName: true, PIN: true}
+
+	tests := []struct {
+		name         string
+		requirements ProofRequirements
+		proof        ProofOfDelivery
+		want         []string
+	}{
+		{name: "Nothing Required"},
+		{name: "Nothing Collected", requirements: all, want: []string{"photo", "signature", "recipient_name", "pin"}},
+		{
+			name:         "Partly Collected",
+			requirements: all,
+			proof:        ProofOfDelivery{PhotoKey: "proofs/1/photo.jpg", RecipientName: "Lan"},
+			want:         []string{"signature", "pin"},
+		},
+		{
+			name:         "Complete",
+			requirements: all,
+			proof: ProofOfDelivery{
+				PhotoKey: "proofs/1/photo.jpg", SignatureKey: "proofs/1/signature.png",
+				RecipientName: "Lan", PINVerifiedAt: time.Now(),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.requirements.Missing(tt.proof))
+		})
+	}
+}
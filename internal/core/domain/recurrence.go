@@ -0,0 +1,210 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidRecurrence      = errors.New("invalid recurrence schedule")
+	ErrRecurringOrderNotFound = errors.New("recurring order not found")
+)
+
+// maxOccurrenceSearch bounds how far ahead Next looks for a matching day, so
+// schedules that can never fire (e.g. February 30th) end instead of looping.
+const maxOccurrenceSearch = 5 * 366
+
+// Cron is a parsed five-field cron expression: minute, hour, day of month,
+// month and day of week (0 or 7 = Sunday). Fields take *, values, ranges,
+// steps and comma-separated lists. As in cron, when both day fields are
+// restricted a day matching either one fires.
+type Cron struct {
+	minutes  uint64
+	hours    uint32
+	days     uint32
+	months   uint16
+	weekdays uint8
+
+	anyDay, anyWeekday bool
+
+	expr string
+}
+
+type cronField struct {
+	min, max int
+}
+
+var cronFields = [5]cronField{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+func ParseCron(expr string) (Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return Cron{}, fmt.Errorf("%w: want 5 fields, got %d", ErrInvalidRecurrence, len(fields))
+	}
+
+	var bits [5]uint64
+	for i, field := range fields {
+		b, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return Cron{}, fmt.Errorf("%w: field %d: %v", ErrInvalidRecurrence, i+1, err)
+		}
+		bits[i] = b
+	}
+
+	weekdays := bits[4]
+	if weekdays&(1<<7) != 0 {
+		weekdays |= 1
+	}
+
+	return Cron{
+		minutes:    bits[0],
+		hours:      uint32(bits[1]),
+		days:       uint32(bits[2]),
+		months:     uint16(bits[3]),
+		weekdays:   uint8(weekdays & 0x7f),
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+		expr:       strings.Join(fields, " "),
+	}, nil
+}
+
+func parseCronField(field string, bounds cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rng, step = part[:i], n
+		}
+
+		lo, hi := bounds.min, bounds.max
+		if rng != "*" {
+			var err error
+			if i := strings.IndexByte(rng, '-'); i >= 0 {
+				if lo, err = strconv.Atoi(rng[:i]); err == nil {
+					hi, err = strconv.Atoi(rng[i+1:])
+				}
+			} else if lo, err = strconv.Atoi(rng); err == nil {
+				hi = lo
+				if step > 1 {
+					hi = bounds.max
+				}
+			}
+			if err != nil {
+				return 0, fmt.Errorf("invalid value in %q", part)
+			}
+		}
+		if lo < bounds.min || hi > bounds.max || lo > hi {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, bounds.min, bounds.max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+func (c Cron) String() string {
+	return c.expr
+}
+
+// matchesDay reports whether the schedule fires on the given date.
+func (c Cron) matchesDay(t time.Time) bool {
+	if c.months&(1<<int(t.Month())) == 0 {
+		return false
+	}
+
+	day := c.days&(1<<t.Day()) != 0
+	weekday := c.weekdays&(1<<int(t.Weekday())) != 0
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekday
+	case c.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+// Next returns the first time after t the schedule fires on the wall clock
+// of loc, or the zero time if it never does.
+func (c Cron) Next(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	for range maxOccurrenceSearch {
+		if c.matchesDay(day) {
+			for h := 0; h < 24; h++ {
+				if c.hours&(1<<h) == 0 {
+					continue
+				}
+				for m := 0; m < 60; m++ {
+					if c.minutes&(1<<m) == 0 {
+						continue
+					}
+					at := time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, loc)
+					if at.After(t) {
+						return at
+					}
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return time.Time{}
+}
+
+// RecurringOrder is a template that books the same trip on a schedule.
+type RecurringOrder struct {
+	ID         string
+	FleetID    string
+	CustomerID string
+	Name       string
+	Schedule   Cron
+	Location   *time.Location
+	Pickup     Location
+	Dropoff    Location
+	Vehicle    VehicleType
+	Notes      string
+	Paused     bool
+
+	// Skips holds the local dates, "2006-01-02", on which the template does
+	// not fire.
+	Skips map[string]bool
+}
+
+// Occurrence is one time a recurring order fires.
+type Occurrence struct {
+	At      time.Time `json:"at"`
+	Skipped bool      `json:"skipped"`
+}
+
+// Occurrences lists up to limit times the template fires after from and no
+// later than to, including skipped ones.
+func (r *RecurringOrder) Occurrences(from, to time.Time, limit int) []Occurrence {
+	loc := r.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var occurrences []Occurrence
+	for at := r.Schedule.Next(from, loc); !at.IsZero() && !at.After(to) && len(occurrences) < limit; at = r.Schedule.Next(at, loc) {
+		occurrences = append(occurrences, Occurrence{
+			At:      at,
+			Skipped: r.Skips[at.Format(time.DateOnly)],
+		})
+	}
+
+	return occurrences
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := ParseCron(expr)
+		assert.ErrorIs(t, err, ErrInvalidRecurrence, expr)
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+	saigon, err := time.LoadLocation("Asia/Ho_Chi_Minh")
+	require.NoError(t, err)
+	// Friday 2025-06-13 18:00 local
+	from := time.Date(2025, 6, 13, 18, 0, 0, 0, saigon)
+
+	tests := []struct {
+		name string
+		expr string
+		want time.Time
+	}{
+		{name: "Weekdays At Nine", expr: "0 9 * * 1-5", want: time.Date(2025, 6, 16, 9, 0, 0, 0, saigon)},
+		{name: "Every Quarter Hour", expr: "*/15 * * * *", want: time.Date(2025, 6, 13, 18, 15, 0, 0, saigon)},
+		{name: "Sunday As Seven", expr: "30 7 * * 7", want: time.Date(2025, 6, 15, 7, 30, 0, 0, saigon)},
+		{name: "First Of Month", expr: "0 8 1 * *", want: time.Date(2025, 7, 1, 8, 0, 0, 0, saigon)},
+		{name: "Day Or Weekday", expr: "0 8 20 * 6", want: time.Date(2025, 6, 14, 8, 0, 0, 0, saigon)},
+		{name: "Never", expr: "0 0 30 2 *", want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+
+			assert.True(t, tt.want.Equal(cron.Next(from, saigon)), cron.Next(from, saigon))
+		})
+	}
+}
+
+func TestRecurringOrder_Occurrences(t *testing.T) {
+	cron, err := ParseCron("0 9 * * 1-5")
+	require.NoError(t, err)
+	r := RecurringOrder{Schedule: cron, Location: time.UTC, Skips: map[string]bool{"2025-06-17": true}}
+
+	from := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	got := r.Occurrences(from, from.AddDate(0, 0, 7), 3)
+
+	assert.Equal(t, []Occurrence{
+		{At: time.Date(2025, 6, 16, 9, 0, 0, 0, time.UTC)},
+		{At: time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC), Skipped: true},
+		{At: time.Date(2025, 6, 18, 9, 0, 0, 0, time.UTC)},
+	}, got)
+}
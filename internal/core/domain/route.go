@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNoRoute = errors.New("no route found")
+
+// Route is a road route between two points. Polyline is encoded with the
+// Google polyline algorithm at precision 5.
+type Route struct {
+	DistanceMeters float64
+	Duration       time.Duration
+	Polyline       string
+}
@@ -0,0 +1,8 @@
+package domain
+
+import "errors"
+
+var (
+	ErrPickupOutsideServiceArea  = errors.New("pickup is outside the fleet's service area")
+	ErrDropoffOutsideServiceArea = errors.New("dropoff is outside the fleet's service area")
+)
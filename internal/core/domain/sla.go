@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidPriority = errors.New("priority must be standard, express or critical")
+	ErrInvalidDeadline = errors.New("delivery deadline must be after the pickup time")
+)
+
+// OrderPriority decides which orders get drivers first when there are not
+// enough to go around.
+type OrderPriority string
+
+const (
+	PriorityStandard OrderPriority = "standard"
+	PriorityExpress  OrderPriority = "express"
+	PriorityCritical OrderPriority = "critical"
+)
+
+func (p OrderPriority) Valid() bool {
+	switch p {
+	case PriorityStandard, PriorityExpress, PriorityCritical:
+		return true
+	}
+	return false
+}
+
+// SLA is an order's promised delivery deadline and how it is tracking
+// against it. AtRiskSince is set while the predicted dropoff misses the
+// deadline, BreachedAt once the deadline has passed without delivery.
+type SLA struct {
+	DeliverBy   time.Time `json:"deliver_by"`
+	AtRiskSince time.Time `json:"at_risk_since,omitzero"`
+	BreachedAt  time.Time `json:"breached_at,omitzero"`
+}
+
+// AtRisk reports whether a dropoff predicted at dropoffAt lands less than
+// margin before the deadline. Orders without a deadline or a prediction are
+// never at risk.
+func (s SLA) AtRisk(dropoffAt time.Time, margin time.Duration) bool {
+	if s.DeliverBy.IsZero() || dropoffAt.IsZero() {
+		return false
+	}
+	return dropoffAt.Add(margin).After(s.DeliverBy)
+}
+
+// SLABreach is an order that missed its delivery deadline.
+type SLABreach struct {
+	OrderID    string        `json:"order_id"`
+	DriverID   string        `json:"driver_id,omitempty"`
+	Status     OrderStatus   `json:"status"`
+	Priority   OrderPriority `json:"priority"`
+	DeliverBy  time.Time     `json:"deliver_by"`
+	BreachedAt time.Time     `json:"breached_at"`
+}
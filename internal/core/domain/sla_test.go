@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSLA_AtRisk(t *testing.T) {
+	deadline := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		sla       SLA
+		dropoffAt time.Time
+		margin    time.Duration
+		want      bool
+	}{
+		{name: "Comfortably Early", sla: SLA{DeliverBy: deadline}, dropoffAt: deadline.Add(-time.Hour), margin: 5 * time.Minute},
+		{name: "Within Margin", sla: SLA{DeliverBy: deadline}, dropoffAt: deadline.Add(-2 * time.Minute), margin: 5 * time.Minute, want: true},
+		{name: "Past Deadline", sla: SLA{DeliverBy: deadline}, dropoffAt: deadline.Add(time.Minute), want: true},
+		{name: "Exactly On Deadline", sla: SLA{DeliverBy: deadline}, dropoffAt: deadline},
+		{name: "No Deadline", dropoffAt: deadline},
+		{name: "No Prediction", sla: SLA{DeliverBy: deadline}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.sla.AtRisk(tt.dropoffAt, tt.margin))
+		})
+	}
+}
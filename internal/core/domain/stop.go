@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// MaxOrderStops bounds the stops of a single order.
+const MaxOrderStops = 20
+
+var (
+	ErrInvalidStops      = errors.New("stops must start with a pickup, end with a dropoff and number at most 20")
+	ErrInvalidTimeWindow = errors.New("time window must end after it starts and in the future")
+	ErrStopWindowNotOpen = errors.New("the stop's time window has not opened yet")
+)
+
+type StopKind string
+
+const (
+	StopKindPickup  StopKind = "pickup"
+	StopKindDropoff StopKind = "dropoff"
+	// StopKindReturn takes an undeliverable order back to its pickup.
+	StopKindReturn StopKind = "return"
+)
+
+type StopStatus string
+
+const (
+	StopStatusPending   StopStatus = "pending"
+	StopStatusArrived   StopStatus = "arrived"
+	StopStatusCompleted StopStatus = "completed"
+	StopStatusFailed    StopStatus = "failed"
+)
+
+// TimeWindow is when a stop may be served. Either bound may be zero, leaving
+// that side open.
+type TimeWindow struct {
+	Start time.Time `json:"start,omitzero"`
+	End   time.Time `json:"end,omitzero"`
+}
+
+// Validate checks that the window is bounded, ends after it starts and has
+// not already closed at now.
+func (w TimeWindow) Validate(now time.Time) error {
+	if w.Start.IsZero() && w.End.IsZero() {
+		return ErrInvalidTimeWindow
+	}
+	if !w.End.IsZero() && (!w.End.After(now) || !w.End.After(w.Start)) {
+		return ErrInvalidTimeWindow
+	}
+	return nil
+}
+
+// Serve returns when a driver arriving at arrival can serve the stop: right
+// away, or at the start of the window if they are early. It reports false
+// when they would arrive after the window closed.
+func (w *TimeWindow) Serve(arrival time.Time) (time.Time, bool) {
+	if w == nil {
+		return arrival, true
+	}
+	if !w.End.IsZero() && arrival.After(w.End) {
+		return arrival, false
+	}
+	if arrival.Before(w.Start) {
+		return w.Start, true
+	}
+	return arrival, true
+}
+
+// Late reports whether a driver arriving at arrival missed the window.
+func (w *TimeWindow) Late(arrival time.Time) bool {
+	return w != nil && !w.End.IsZero() && arrival.After(w.End)
+}
+
+// Stop is one place an order's driver visits, in Position order. The order
+// moves to arrived and picked_up with its first stop and to delivered with
+// its last. Late is recorded when the driver reached a stop after its window
+// closed. Address is nil for stops booked with coordinates only.
+type Stop struct {
+	Position    int         `json:"position"`
+	Kind        StopKind    `json:"kind"`
+	Location    Location    `json:"location"`
+	Status      StopStatus  `json:"status"`
+	Window      *TimeWindow `json:"window,omitempty"`
+	Address     *Address    `json:"address,omitempty"`
+	ArrivedAt   time.Time   `json:"arrived_at,omitzero"`
+	CompletedAt time.Time   `json:"completed_at,omitzero"`
+	Late        bool        `json:"late,omitempty"`
+}
+
+// ValidateStops checks that stops describe a deliverable route.
+func ValidateStops(stops []Stop) error {
+	if len(stops) < 2 || len(stops) > MaxOrderStops {
+		return ErrInvalidStops
+	}
+	if stops[0].Kind != StopKindPickup || stops[len(stops)-1].Kind != StopKindDropoff {
+		return ErrInvalidStops
+	}
+	for _, s := range stops {
+		if s.Kind != StopKindPickup && s.Kind != StopKindDropoff {
+			return ErrInvalidStops
+		}
+	}
+	return nil
+}
+
+// NextStop returns the first stop neither completed nor failed, or nil when
+// all are.
+func NextStop(stops []Stop) *Stop {
+	for i := range stops {
+		if stops[i].Status != StopStatusCompleted && stops[i].Status != StopStatusFailed {
+			return &stops[i]
+		}
+	}
+	return nil
+}
+
+// WithoutAddress returns the stop without its address, for those who may
+// follow the order but not contact its recipients.
+func (s Stop) WithoutAddress() Stop {
+	s.Address = nil
+	return s
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeWindow_Serve(t *testing.T) {
+	start := time.Date(2025, 3, 1, 14, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	window := &TimeWindow{Start: start, End: end}
+
+	tests := []struct {
+		name     string
+		window   *TimeWindow
+		arrival  time.Time
+		wantAt   time.Time
+		wantOK   bool
+		wantLate bool
+	}{
+		{name: "Early Waits", window: window, arrival: start.Add(-time.Hour), wantAt: start, wantOK: true},
+		{name: "Inside", window: window, arrival: start.Add(time.Hour), wantAt: start.Add(time.Hour), wantOK: true},
+		{name: "At Close", window: window, arrival: end, wantAt: end, wantOK: true},
+		{name: "Late", window: window, arrival: end.Add(time.Minute), wantAt: end.Add(time.Minute), wantLate: true},
+		{name: "Open Ended", window: &TimeWindow{Start: start}, arrival: end.Add(time.Hour), wantAt: end.Add(time.Hour), wantOK: true},
+		{name: "No Window", arrival: start, wantAt: start, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, ok := tt.window.Serve(tt.arrival)
+			assert.Equal(t, tt.wantAt, at)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantLate, tt.window.Late(tt.arrival))
+		})
+	}
+}
+
+func TestTimeWindow_Validate(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, TimeWindow{Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)}.Validate(now))
+	assert.NoError(t, TimeWindow{Start: now.Add(-time.Hour)}.Validate(now))
+	assert.NoError(t, TimeWindow{End: now.Add(time.Hour)}.Validate(now))
+	assert.ErrorIs(t, TimeWindow{}.Validate(now), ErrInvalidTimeWindow)
+	assert.ErrorIs(t, TimeWindow{Start: now.Add(2 * time.Hour), End: now.Add(time.Hour)}.Validate(now), ErrInvalidTimeWindow)
+	assert.ErrorIs(t, TimeWindow{End: now.Add(-time.Minute)}.Validate(now), ErrInvalidTimeWindow)
+}
+
+func TestNextStop_SkipsFailed(t *testing.T) {
+	stops := []Stop{
+		{Position: 0, Kind: StopKindPickup, Status: StopStatusCompleted},
+		{Position: 1, Kind: StopKindDropoff, Status: StopStatusFailed},
+		{Position: 2, Kind: StopKindReturn, Status: StopStatusPending},
+	}
+
+	assert.Equal(t, 2, NextStop(stops).Position)
+
+	stops[2].Status = StopStatusCompleted
+	assert.Nil(t, NextStop(stops))
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrTariffNotFound = errors.New("tariff schedule not found")
+
+const minutesPerDay = 24 * 60
+
+// TariffRule prices one vehicle type inside a recurring local time window.
+// StartMinute and EndMinute are minutes since local midnight; when EndMinute
+// is not after StartMinute the window runs overnight and belongs to the day
+// it started on.
+type TariffRule struct {
+	Name         string
+	Vehicle      VehicleType
+	DaysOfWeek   uint8 // bit 0 = Sunday ... bit 6 = Saturday
+	HolidaysOnly bool
+	StartMinute  int
+	EndMinute    int
+	Priority     int
+	BaseCents    int
+	PerKmCents   int
+}
+
+type TariffSchedule struct {
+	FleetID  string
+	Location *time.Location
+	Holidays map[string]bool // keyed by local date, "2006-01-02"
+	Rules    []TariffRule
+}
+
+func (s *TariffSchedule) IsHoliday(day time.Time) bool {
+	return s.Holidays[day.Format(time.DateOnly)]
+}
+
+// Match returns the highest priority rule for the vehicle that is in effect at t,
+// evaluated on the wall clock of the schedule's time zone.
+func (s *TariffSchedule) Match(vehicle VehicleType, t time.Time) (TariffRule, bool) {
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	var best TariffRule
+	found := false
+	for _, r := range s.Rules {
+		if r.Vehicle != vehicle {
+			continue
+		}
+
+		day, ok := r.startDay(local, minute)
+		if !ok || !r.appliesOn(day.Weekday(), s.IsHoliday(day)) {
+			continue
+		}
+
+		if !found || r.Priority > best.Priority {
+			best = r
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// startDay reports whether minute falls inside the rule's window and, if so,
+// the local date on which that window opened.
+func (r TariffRule) startDay(local time.Time, minute int) (time.Time, bool) {
+	start, end := r.StartMinute, r.EndMinute
+	if end > minutesPerDay {
+		end = minutesPerDay
+	}
+
+	if start < end {
+		return local, minute >= start && minute < end
+	}
+
+	if minute >= start {
+		return local, true
+	}
+	if minute < end {
+		return local.AddDate(0, 0, -1), true
+	}
+
+	return time.Time{}, false
+}
+
+func (r TariffRule) appliesOn(day time.Weekday, holiday bool) bool {
+	if r.HolidaysOnly {
+		return holiday
+	}
+	return r.DaysOfWeek&(1<<uint(day)) != 0
+}
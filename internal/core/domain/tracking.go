@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrTrackingLinkNotFound = errors.New("tracking link not found")
+	ErrTrackingLinkExpired  = errors.New("tracking link has expired or been revoked")
+)
+
+const (
+	DefaultTrackingLinkTTL = 24 * time.Hour
+	MaxTrackingLinkTTL     = 7 * 24 * time.Hour
+)
+
+// TrackingLink grants read-only access to an order to anyone holding its
+// token. Only a hash of the token is stored.
+type TrackingLink struct {
+	ID        string    `json:"id"`
+	OrderID   string    `json:"order_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at,omitzero"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (l *TrackingLink) ValidAt(t time.Time) bool {
+	return l.RevokedAt.IsZero() && t.Before(l.ExpiresAt)
+}
+
+// PublicTracking is everything a tracking link reveals. It deliberately
+// carries no driver identity or contact details.
+type PublicTracking struct {
+	OrderID        string      `json:"order_id"`
+	Status         OrderStatus `json:"status"`
+	ETA            *OrderETA   `json:"eta,omitempty"`
+	DriverLocation *Location   `json:"driver_location,omitempty"`
+	LinkExpiresAt  time.Time   `json:"link_expires_at"`
+}
+
+// PublicDriverLocation coarsens a driver position for public tracking. Until
+// the parcel is picked up it is snapped to roughly 1 km so a link cannot be
+// used to follow the driver; afterwards to roughly 100 m.
+func PublicDriverLocation(status OrderStatus, loc Location) Location {
+	switch status {
+	case OrderStatusAssigned, OrderStatusArrived:
+		return loc.Snap(2)
+	default:
+		return loc.Snap(3)
+	}
+}
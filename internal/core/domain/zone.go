@@ -0,0 +1,15 @@
+package domain
+
+import "errors"
+
+var (
+	ErrZoneNotFound     = errors.New("pricing zone not found")
+	ErrZoneFareNotFound = errors.New("zone fare not found")
+)
+
+type PricingZone struct {
+	ID             string
+	FleetID        string
+	Name           string
+	SurchargeCents int
+}
@@ -0,0 +1,16 @@
+package port
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps uploaded files, such as proof of delivery images, under
+// slash-separated keys.
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
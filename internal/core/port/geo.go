@@ -1,7 +1,12 @@
 package port
 
-import "context"
+import (
+	"context"
+
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
 
 type GeoFinder interface {
-	FindNearestDrivers(ctx context.Context, lat, lng float64, radiusKm float64) ([]string, error)
+	FindNearestDrivers(ctx context.Context, lat, lng float64, radiusKm float64) ([]domain.NearbyDriver, error)
+	GetDriverLocation(ctx context.Context, driverID string) (domain.Location, error)
 }
@@ -0,0 +1,11 @@
+package port
+
+import (
+	"time"
+
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+type LocationRecorder interface {
+	Record(driverID string, loc domain.Location, at time.Time)
+}
@@ -0,0 +1,25 @@
+package port
+
+import (
+	"context"
+
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+type TariffRepository interface {
+	GetTariffSchedule(ctx context.Context, fleetID string) (*domain.TariffSchedule, error)
+}
+
+type ZoneRepository interface {
+	FindZone(ctx context.Context, fleetID string, loc domain.Location) (*domain.PricingZone, error)
+	GetZoneFare(ctx context.Context, fromZoneID, toZoneID string, vehicle domain.VehicleType) (int, error)
+}
+
+type PromoRepository interface {
+	GetPromoByCode(ctx context.Context, code string) (*domain.Promo, error)
+	CountCustomerRedemptions(ctx context.Context, promoID, customerID string) (int, error)
+}
+
+type FleetPolicyRepository interface {
+	GetPricingPolicy(ctx context.Context, fleetID string) (domain.FleetPricingPolicy, error)
+}
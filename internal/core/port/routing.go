@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+type RoutingProvider interface {
+	Route(ctx context.Context, from, to domain.Location) (domain.Route, error)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -28,18 +29,24 @@ func (s *AuthService) CheckPasswordHash(password, hash string) bool {
 	return err == nil
 }
 
-func (s *AuthService) GenerateToken(driverID uuid.UUID) (string, error) {
+// GenerateToken issues a token for the account, carrying its fleet and role.
+func (s *AuthService) GenerateToken(driverID, fleetID uuid.UUID, role domain.Role) (string, error) {
 	claims := jwt.MapClaims{
-		"sub": driverID.String(),
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
-		"iat": time.Now().Unix(),
+		"sub":   driverID.String(),
+		"fleet": fleetID.String(),
+		"role":  string(role),
+		"exp":   time.Now().Add(time.Hour * 24).Unix(),
+		"iat":   time.Now().Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtSecret)
 }
 
-func (s *AuthService) ValidateToken(tokenString string) (uuid.UUID, error) {
+// ValidateToken returns the caller a token was issued to. Tokens issued
+// before roles existed carry neither fleet nor role and may only act as
+// themselves.
+func (s *AuthService) ValidateToken(tokenString string) (domain.Principal, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
@@ -48,16 +55,28 @@ func (s *AuthService) ValidateToken(tokenString string) (uuid.UUID, error) {
 	})
 
 	if err != nil {
-		return uuid.Nil, err
+		return domain.Principal{}, err
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 		idStr, ok := claims["sub"].(string)
 		if !ok {
-			return uuid.Nil, errors.New("invalid token claims")
+			return domain.Principal{}, errors.New("invalid token claims")
 		}
-		return uuid.Parse(idStr)
+		driverID, err := uuid.Parse(idStr)
+		if err != nil {
+			return domain.Principal{}, err
+		}
+
+		principal := domain.Principal{DriverID: driverID.String(), Role: domain.RoleDriver}
+		if fleet, ok := claims["fleet"].(string); ok {
+			principal.FleetID = fleet
+		}
+		if role, ok := claims["role"].(string); ok {
+			principal.Role = domain.Role(role)
+		}
+		return principal, nil
 	}
 
-	return uuid.Nil, errors.New("invalid token")
+	return domain.Principal{}, errors.New("invalid token")
 }
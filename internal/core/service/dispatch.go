@@ -4,23 +4,65 @@ import (
 	"context"
 	"errors"
 	"log"
+	"math"
+	"slices"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/routing"
 	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
 	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
 	"github.com/vantutran2k1/flowfleet/internal/core/domain"
 	"github.com/vantutran2k1/flowfleet/internal/core/port"
 	"github.com/vantutran2k1/flowfleet/internal/core/service/pricing"
-	"github.com/vantutran2k1/flowfleet/internal/pkg/geo"
 )
 
+// ETAPolicy controls how often an active order's ETA is recomputed from
+// location updates and how far behind its promise a driver may fall before a
+// late event is emitted. A driver's active order is looked up at most once
+// per LookupInterval; updates in between reuse it.
+type ETAPolicy struct {
+	RefreshInterval time.Duration
+	LateThreshold   time.Duration
+	LookupInterval  time.Duration
+}
+
+var DefaultETAPolicy = ETAPolicy{
+	RefreshInterval: 15 * time.Second,
+	LateThreshold:   5 * time.Minute,
+	LookupInterval:  5 * time.Second,
+}
+
 type DispatchService struct {
-	store  postgres.Store
-	geo    port.GeoFinder
-	hub    *websocket.Hub
-	pricer domain.PricingStrategy
+	store     postgres.Store
+	geo       port.GeoFinder
+	hub       *websocket.Hub
+	pricer    domain.PricingStrategy
+	router    port.RoutingProvider
+	etaPolicy ETAPolicy
+	locations port.LocationRecorder
+	blobs     port.BlobStore
+
+	geofencePolicy GeofencePolicy
+	arrivalPrompts sync.Map
+
+	stackingPolicy  StackingPolicy
+	schedulePolicy  SchedulePolicy
+	recurringPolicy RecurringPolicy
+	slaPolicy       SLAPolicy
+	failurePolicy   DeliveryFailurePolicy
+
+	locationPolicy LocationPolicy
+	fixMu          sync.Mutex
+	lastFixes      map[uuid.UUID]domain.LocationFix
+	anomalies      map[uuid.UUID]*domain.LocationAnomalies
+
+	driverFleets sync.Map
+	activeRoutes sync.Map
 }
 
 func NewDispatchService(store postgres.Store, geo port.GeoFinder, hub *websocket.Hub) *DispatchService {
@@ -29,62 +71,458 @@ func NewDispatchService(store postgres.Store, geo port.GeoFinder, hub *websocket
 		geo:    geo,
 		hub:    hub,
 		pricer: pricing.NewStandardStrategy(),
+		router: routing.NewHaversineProvider(routing.DefaultDetourFactor, routing.DefaultSpeedKmh),
+
+		etaPolicy:       DefaultETAPolicy,
+		geofencePolicy:  DefaultGeofencePolicy,
+		locationPolicy:  DefaultLocationPolicy,
+		stackingPolicy:  DefaultStackingPolicy,
+		schedulePolicy:  DefaultSchedulePolicy,
+		recurringPolicy: DefaultRecurringPolicy,
+		slaPolicy:       DefaultSLAPolicy,
+		failurePolicy:   DefaultDeliveryFailurePolicy,
 	}
 }
 
-func (s *DispatchService) CreateAndDispatchOrder(ctx context.Context, fleetID uuid.UUID, pickupLat, pickupLng, dropoffLat, dropoffLng float64) (uuid.UUID, error) {
-	distMeters := geo.CalculateDistance(pickupLat, pickupLng, dropoffLat, dropoffLng)
+func (s *DispatchService) SetPricingStrategy(pricer domain.PricingStrategy) {
+	s.pricer = pricer
+}
+
+func (s *DispatchService) SetRoutingProvider(router port.RoutingProvider) {
+	s.router = router
+}
+
+func (s *DispatchService) SetETAPolicy(policy ETAPolicy) {
+	s.etaPolicy = policy
+}
+
+func (s *DispatchService) SetLocationRecorder(locations port.LocationRecorder) {
+	s.locations = locations
+}
+
+// CreateOrderInput describes a new order. An empty Vehicle is a bike and an
+// empty Priority standard.
+type CreateOrderInput struct {
+	FleetID uuid.UUID
+	Pickup  domain.Location
+	Dropoff domain.Location
+	// Stops, when given, replace Pickup and Dropoff with an ordered route of
+	// several pickups and dropoffs.
+	Stops []domain.Stop
+	// PickupWindow and DropoffWindow apply to the first and last stop when
+	// it has no window of its own, as do the addresses.
+	PickupWindow   *domain.TimeWindow
+	DropoffWindow  *domain.TimeWindow
+	PickupAddress  *domain.Address
+	DropoffAddress *domain.Address
+	// Items, when given, replace Load with their totals. A zero Load is a
+	// single parcel.
+	Items      []domain.Item
+	Load       domain.Load
+	Vehicle    domain.VehicleType
+	Notes      string
+	Priority   domain.OrderPriority
+	CustomerID string
+	PromoCode  string
+
+	// DeliverBy is the delivery deadline; zero leaves the order without one.
+	DeliverBy time.Time
+	// ScheduledPickupAt books the order for later; zero dispatches it right
+	// away.
+	ScheduledPickupAt time.Time
+	// RecurringOrderID links an order booked from a recurring template.
+	RecurringOrderID uuid.UUID
+}
+
+// CreateOrderResult identifies a new order. TrackingToken and DeliveryPIN
+// are only returned here; the customer presents the token to follow the
+// order and shares the PIN with the recipient to hand to the driver.
+type CreateOrderResult struct {
+	OrderID       uuid.UUID
+	TrackingToken string
+	DeliveryPIN   string
+	Status        domain.OrderStatus
+}
+
+func (s *DispatchService) CreateAndDispatchOrder(ctx context.Context, input CreateOrderInput) (CreateOrderResult, error) {
+	fleetID := input.FleetID
+
+	scheduled, err := s.checkSchedule(input.ScheduledPickupAt, time.Now())
+	if err != nil {
+		return CreateOrderResult{}, err
+	}
+	status := domain.OrderStatusPending
+	if scheduled {
+		status = domain.OrderStatusScheduled
+	}
+	if input.Priority == "" {
+		input.Priority = domain.PriorityStandard
+	}
+	if !input.Priority.Valid() {
+		return CreateOrderResult{}, domain.ErrInvalidPriority
+	}
+	if err := checkDeadline(input.DeliverBy, input.ScheduledPickupAt, time.Now()); err != nil {
+		return CreateOrderResult{}, err
+	}
+
+	stops, err := orderStops(input, time.Now())
+	if err != nil {
+		return CreateOrderResult{}, err
+	}
+	if err := domain.ValidateItems(input.Items); err != nil {
+		return CreateOrderResult{}, err
+	}
+	if len(input.Items) > 0 {
+		input.Load = domain.ItemsLoad(input.Items)
+	}
+	if input.Load.Parcels == 0 {
+		input.Load.Parcels = 1
+	}
+	if input.Vehicle == "" {
+		input.Vehicle = domain.VehicleBike
+	}
+	input.Pickup = stops[0].Location
+	input.Dropoff = stops[len(stops)-1].Location
+
+	if err := s.checkServiceArea(ctx, fleetID, stops); err != nil {
+		return CreateOrderResult{}, err
+	}
+
+	route, err := s.routeStops(ctx, stops)
+	if err != nil {
+		return CreateOrderResult{}, err
+	}
 
-	priceCents, err := s.pricer.CalculatePrice(ctx, domain.PricingInput{
-		DistanceMeters: distMeters,
-		Vehicle:        domain.VehicleBike,
-		Time:           time.Now(),
+	fare, err := s.pricer.CalculatePrice(ctx, domain.PricingInput{
+		FleetID:        fleetID.String(),
+		Pickup:         input.Pickup,
+		Dropoff:        input.Dropoff,
+		DistanceMeters: route.DistanceMeters,
+		Duration:       route.Duration,
+		Vehicle:        input.Vehicle,
+		Load:           input.Load,
+		Time:           pricedAt(input.ScheduledPickupAt),
+		CustomerID:     input.CustomerID,
+		PromoCode:      input.PromoCode,
 	})
 	if err != nil {
-		return uuid.Nil, err
+		return CreateOrderResult{}, err
+	}
+
+	trackingToken, trackingHash, err := newTrackingToken()
+	if err != nil {
+		return CreateOrderResult{}, err
+	}
+	deliveryPIN, pinHash, err := newDeliveryPIN()
+	if err != nil {
+		return CreateOrderResult{}, err
 	}
 
 	params := postgres.CreateOrderParams{
 		FleetID:       fleetID,
-		AmountCents:   int32(priceCents),
-		StMakepoint:   pickupLng,
-		StMakepoint_2: pickupLat,
-		StMakepoint_3: pickupLng,
-		StMakepoint_4: pickupLat,
+		AmountCents:   int32(fare.TotalCents),
+		Currency:      fare.Currency,
+		Status:        postgres.OrderStatus(status),
+		StMakepoint:   input.Pickup.Lng,
+		StMakepoint_2: input.Pickup.Lat,
+		StMakepoint_3: input.Dropoff.Lng,
+		StMakepoint_4: input.Dropoff.Lat,
+
+		TrackingTokenHash: trackingHash,
+		DeliveryPinHash:   pinHash,
+		ParcelCount:       int32(input.Load.Parcels),
+		WeightGrams:       int32(input.Load.WeightGrams),
+		VolumeCm3:         int32(input.Load.VolumeCm3),
+		Fragile:           input.Load.Fragile,
+		ColdChain:         input.Load.ColdChain,
+		LongestItemCm:     int32(input.Load.LongestCm),
+		ScheduledPickupAt: timestamptz(input.ScheduledPickupAt),
+		RecurringOrderID:  pgtype.UUID{Bytes: input.RecurringOrderID, Valid: input.RecurringOrderID != uuid.Nil},
+		VehicleType:       string(input.Vehicle),
+		Notes:             input.Notes,
+		Priority:          postgres.OrderPriority(input.Priority),
+		DeliverBy:         timestamptz(input.DeliverBy),
 	}
 
-	order, err := s.store.CreateOrder(ctx, params)
-	if err != nil {
-		return uuid.Nil, err
+	var order postgres.CreateOrderRow
+	if err := s.store.ExecTx(ctx, func(q postgres.Querier) error {
+		createdOrder, err := q.CreateOrder(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		for _, stop := range stops {
+			if err := createStop(ctx, q, createdOrder.ID, stop); err != nil {
+				return err
+			}
+		}
+
+		for i, item := range input.Items {
+			if err := q.CreateOrderItem(ctx, postgres.CreateOrderItemParams{
+				OrderID:            createdOrder.ID,
+				Position:           int32(i),
+				Description:        item.Description,
+				Quantity:           int32(item.Quantity),
+				WeightGrams:        int32(item.WeightGrams),
+				LengthCm:           int32(item.LengthCm),
+				WidthCm:            int32(item.WidthCm),
+				HeightCm:           int32(item.HeightCm),
+				Fragile:            item.Fragile,
+				ColdChain:          item.ColdChain,
+				DeclaredValueCents: int32(item.DeclaredValueCents),
+			}); err != nil {
+				return err
+			}
+		}
+
+		for i, line := range fare.Lines {
+			if err := q.CreateOrderFareLine(ctx, postgres.CreateOrderFareLineParams{
+				OrderID:     createdOrder.ID,
+				Position:    int32(i),
+				Kind:        string(line.Kind),
+				Description: line.Description,
+				AmountCents: int32(line.AmountCents),
+			}); err != nil {
+				return err
+			}
+		}
+
+		if fare.PromoCode != "" {
+			if err := redeemPromo(ctx, q, createdOrder.ID, fare, input.CustomerID); err != nil {
+				return err
+			}
+		}
+
+		order = createdOrder
+
+		return nil
+	}); err != nil {
+		return CreateOrderResult{}, err
+	}
+
+	result := CreateOrderResult{OrderID: order.ID, TrackingToken: trackingToken, DeliveryPIN: deliveryPIN, Status: status}
+	// like the stop events, the feed carries the route without addresses
+	opsStops := make([]domain.Stop, len(stops))
+	for i, stop := range stops {
+		opsStops[i] = stop.WithoutAddress()
+	}
+	data := map[string]any{"dropoff": input.Dropoff, "stops": opsStops, "fare": fare, "priority": input.Priority}
+	if !input.ScheduledPickupAt.IsZero() {
+		data["scheduled_pickup_at"] = input.ScheduledPickupAt
+	}
+	if !input.DeliverBy.IsZero() {
+		data["deliver_by"] = input.DeliverBy
+	}
+	s.hub.PublishOps(websocket.OpsEvent{
+		Event:    websocket.EventOrderCreated,
+		FleetID:  fleetID.String(),
+		OrderID:  order.ID.String(),
+		Status:   string(status),
+		Location: &input.Pickup,
+		Data:     data,
+	})
+
+	if scheduled {
+		return result, nil
+	}
+
+	return result, s.dispatchOrder(ctx, dispatchRequest{
+		OrderID:   order.ID,
+		FleetID:   fleetID,
+		Stops:     stops,
+		Items:     input.Items,
+		Load:      input.Load,
+		Fare:      fare,
+		Route:     route,
+		PickupAt:  input.ScheduledPickupAt,
+		DeliverBy: input.DeliverBy,
+		Priority:  input.Priority,
+	})
+}
+
+// dispatchRequest is what assigning an order to a driver needs to know about
+// it.
+type dispatchRequest struct {
+	OrderID uuid.UUID
+	FleetID uuid.UUID
+	Stops   []domain.Stop
+	Items   []domain.Item
+	Load    domain.Load
+	Fare    domain.Fare
+	Route   domain.Route
+
+	// PickupAt is the booked pickup time of a scheduled order; the driver
+	// is never promised to be there earlier.
+	PickupAt time.Time
+	// DeliverBy is the order's delivery deadline, if it has one.
+	DeliverBy time.Time
+	Priority  domain.OrderPriority
+	// Redispatch marks another attempt at an order already announced as
+	// unassigned, which is not announced again when it fails.
+	Redispatch bool
+}
+
+// dispatchOrder offers a pending order to the best available driver nearby,
+// or announces it as unassigned when there is none. An order with a deadline
+// goes to the nearest driver who can make it, falling back to the nearest
+// driver when none can. A new standard order leaves the SLA policy's
+// reserved drivers to more urgent orders and waits for the redispatch.
+func (s *DispatchService) dispatchOrder(ctx context.Context, req dispatchRequest) error {
+	fleetID, stops, route, fare := req.FleetID, req.Stops, req.Route, req.Fare
+	pickup := stops[0].Location
+	pickupLat, pickupLng := pickup.Lat, pickup.Lng
+
+	unassigned := func() {
+		if !req.Redispatch {
+			s.publishUnassigned(fleetID.String(), req.OrderID, &pickup)
+		}
 	}
 
-	candidateIDs, err := s.geo.FindNearestDrivers(ctx, pickupLat, pickupLng, 5.0)
+	candidates, err := s.geo.FindNearestDrivers(ctx, pickupLat, pickupLng, 5.0)
 	if err != nil {
 		log.Println("redis error:", err)
-		return order.ID, nil
+		unassigned()
+		return nil
 	}
 
-	var assignedDriverID string
-	for _, dID := range candidateIDs {
-		driverUUID, _ := uuid.Parse(dID)
+	sla := domain.SLA{DeliverBy: req.DeliverBy}
+	reserved := 0
+	if !req.Redispatch && req.Priority == domain.PriorityStandard {
+		reserved = s.slaPolicy.ReservedDrivers
+	}
+	ranked := s.rankByETA(ctx, candidates, pickup)
+	var assigned *rankedDriver
+	var plan *driverPlan
+	var promise driverPromise
+	var atRiskSince time.Time
+	// a driver planned for may be given another order before this one is
+	// assigned to them, in which case the candidates are planned for again
+	for attempt := 1; ; attempt++ {
+		assigned = nil
+		// settled is set once the nearest driver who meets the deadline is
+		// found; the candidates after them are only counted
+		settled := false
+		available := 0
+		for _, candidate := range ranked {
+			driverUUID, _ := uuid.Parse(candidate.ID)
 
-		driver, err := s.store.GetDriver(ctx, driverUUID)
-		if err != nil {
-			continue
+			driver, err := s.store.GetDriver(ctx, driverUUID)
+			if err != nil {
+				continue
+			}
+
+			now := time.Now()
+			p, ok := s.planDriver(ctx, candidate, driver.Status, req.OrderID, stops, req.Load, now)
+			if !ok {
+				continue
+			}
+			available++
+			if !settled {
+				pr := promiseOf(candidate, p, route, req.PickupAt, now)
+				meetsDeadline := req.DeliverBy.IsZero() || (pr.Reachable && !sla.AtRisk(pr.DropoffAt, s.slaPolicy.AtRiskMargin))
+				if assigned == nil || meetsDeadline {
+					assigned, plan, promise = &candidate, p, pr
+					settled = meetsDeadline
+				}
+			}
+			if settled && available > reserved {
+				break
+			}
+		}
+
+		if assigned == nil {
+			unassigned()
+			return errors.New("no available drivers found")
+		}
+		if available <= reserved {
+			unassigned()
+			return nil
+		}
+
+		atRiskSince = time.Time{}
+		if sla.AtRisk(promise.DropoffAt, s.slaPolicy.AtRiskMargin) {
+			atRiskSince = time.Now()
 		}
 
-		if driver.Status == postgres.DriverStatusIdle {
-			assignedDriverID = dID
+		err := s.assignDriver(ctx, req.OrderID, assigned.ID, plan, promise, atRiskSince)
+		if err == nil {
 			break
 		}
+		if !errors.Is(err, errDriverChanged) {
+			return err
+		}
+		if attempt == maxAssignAttempts {
+			unassigned()
+			return err
+		}
 	}
+	assignedDriverID := assigned.ID
+	reachable := promise.Reachable
+	promisedPickup, promisedDropoff := promise.PickupAt, promise.DropoffAt
 
-	if assignedDriverID == "" {
-		return order.ID, errors.New("no available drivers found")
+	// the offer is the assigned driver's alone, so its stops keep their
+	// contact and access details
+	offer := map[string]any{
+		"event":            "ORDER_ASSIGNED",
+		"order_id":         req.OrderID,
+		"lat":              pickupLat,
+		"lng":              pickupLng,
+		"fare":             fare,
+		"stops":            stops,
+		"trip_eta_seconds": int(route.Duration.Seconds()),
+		"trip_distance_m":  int(route.DistanceMeters),
+		"stacked":          plan.Stacked,
 	}
+	if plan.Stacked {
+		offer["route"] = plan.Stops
+	}
+	if len(req.Items) > 0 {
+		offer["items"] = req.Items
+	}
+	if !req.DeliverBy.IsZero() {
+		offer["deliver_by"] = req.DeliverBy
+	}
+	if reachable {
+		offer["pickup_eta_seconds"] = int(promise.PickupETA.Seconds())
+		offer["pickup_eta_at"] = promisedPickup
+		offer["dropoff_eta_at"] = promisedDropoff
+	}
+	s.hub.SendToDriver(assignedDriverID, offer)
 
-	if err := s.store.ExecTx(ctx, func(q postgres.Querier) error {
-		driverUUID, _ := uuid.Parse(assignedDriverID)
+	driverUUID, _ := uuid.Parse(assignedDriverID)
+	s.forgetActiveOrder(driverUUID)
+	s.publishDriverStatus(fleetID.String(), driverUUID, postgres.DriverStatusEnRoute)
+	s.publishStatus(fleetID.String(), req.OrderID, domain.OrderStatusAssigned, map[string]any{
+		"driver_id":      assignedDriverID,
+		"pickup_eta_at":  offer["pickup_eta_at"],
+		"dropoff_eta_at": offer["dropoff_eta_at"],
+	})
+	if !atRiskSince.IsZero() {
+		s.publishSLAAtRisk(fleetID.String(), assignedDriverID, req.OrderID, req.DeliverBy, promisedDropoff)
+	}
+
+	return nil
+}
+
+// assignDriver gives the order to the driver along the planned route. The
+// driver's row is locked first, so concurrent dispatches to the same driver
+// take turns, and errDriverChanged is returned when their status or orders
+// are no longer those the plan was made for.
+func (s *DispatchService) assignDriver(ctx context.Context, orderID uuid.UUID, driverID string, plan *driverPlan, promise driverPromise, atRiskSince time.Time) error {
+	driverUUID, _ := uuid.Parse(driverID)
+
+	return s.store.ExecTx(ctx, func(q postgres.Querier) error {
+		status, err := q.LockDriverStatus(ctx, driverUUID)
+		if err != nil {
+			return err
+		}
+		current, err := q.GetDriverLoad(ctx, driverUUID)
+		if err != nil {
+			return err
+		}
+		if status != plan.DriverStatus || current.ActiveOrders != plan.ActiveOrders {
+			return errDriverChanged
+		}
 
 		if err := q.SetDriverStatus(ctx, postgres.SetDriverStatusParams{
 			ID:     driverUUID,
@@ -93,111 +531,559 @@ func (s *DispatchService) CreateAndDispatchOrder(ctx context.Context, fleetID uu
 			return err
 		}
 
-		if err := q.AssignDriverToOrder(ctx, postgres.AssignDriverToOrderParams{
-			DriverID: pgtype.UUID{Bytes: driverUUID, Valid: true},
-			ID:       order.ID,
-		}); err != nil {
+		// the order may have been taken by a concurrent dispatch
+		if err := markOrder(q.AssignDriverToOrder(ctx, postgres.AssignDriverToOrderParams{
+			DriverID:          pgtype.UUID{Bytes: driverUUID, Valid: true},
+			ID:                orderID,
+			PromisedPickupAt:  timestamptz(promise.PickupAt),
+			PromisedDropoffAt: timestamptz(promise.DropoffAt),
+			SlaAtRiskSince:    timestamptz(atRiskSince),
+		})); err != nil {
 			return err
 		}
 
-		return nil
-	}); err != nil {
-		return order.ID, err
+		return saveSequence(ctx, q, plan.Stops)
+	})
+}
+
+// driverPromise is when a driver would reach an order's pickup and
+// dropoff. It is only made when the router could reach the driver.
+type driverPromise struct {
+	PickupETA time.Duration
+	PickupAt  time.Time
+	DropoffAt time.Time
+	Reachable bool
+}
+
+// promiseOf returns what the driver can promise for the order: a timed plan
+// reaches its stops along the planned route, waiting for their windows, and
+// a scheduled order is never picked up before pickupAt.
+func promiseOf(candidate rankedDriver, plan *driverPlan, route domain.Route, pickupAt, now time.Time) driverPromise {
+	pickupETA, dropoffETA := candidate.ETA, candidate.ETA+route.Duration
+	if plan.Timed {
+		pickupETA, dropoffETA = plan.PickupETA, plan.DropoffETA
 	}
 
-	s.hub.SendToDriver(assignedDriverID, map[string]any{
-		"event":    "ORDER_ASSIGNED",
-		"order_id": order.ID,
-		"lat":      pickupLat,
-		"lng":      pickupLng,
-	})
+	promise := driverPromise{PickupETA: pickupETA, Reachable: plan.Timed || candidate.Reachable}
+	if !promise.Reachable {
+		return promise
+	}
 
-	return order.ID, nil
+	promise.PickupAt = now.Add(pickupETA)
+	promise.DropoffAt = now.Add(dropoffETA)
+	if early := pickupAt.Sub(promise.PickupAt); early > 0 {
+		promise.PickupAt = promise.PickupAt.Add(early)
+		promise.DropoffAt = promise.DropoffAt.Add(early)
+	}
+
+	return promise
 }
 
-func (s *DispatchService) AcceptAssignment(ctx context.Context, driverID uuid.UUID, orderID uuid.UUID) error {
-	return s.store.ExecTx(ctx, func(q postgres.Querier) error {
-		return q.SetDriverStatus(ctx, postgres.SetDriverStatusParams{
-			ID:     driverID,
-			Status: postgres.DriverStatusEnRoute,
-		})
+// rankedDriver is a candidate with its road travel time to the pickup.
+type rankedDriver struct {
+	domain.NearbyDriver
+	ETA       time.Duration
+	Reachable bool
+}
+
+// rankByETA orders candidates by road travel time to the pickup. Candidates
+// the router cannot reach keep their straight-line order after the others.
+func (s *DispatchService) rankByETA(ctx context.Context, candidates []domain.NearbyDriver, pickup domain.Location) []rankedDriver {
+	ranked := make([]rankedDriver, len(candidates))
+	for i, c := range candidates {
+		ranked[i] = rankedDriver{NearbyDriver: c, ETA: time.Duration(math.MaxInt64)}
+
+		route, err := s.router.Route(ctx, c.Location, pickup)
+		if err != nil {
+			continue
+		}
+		ranked[i].ETA = route.Duration
+		ranked[i].Reachable = true
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].ETA < ranked[j].ETA
 	})
+
+	return ranked
 }
 
-func (s *DispatchService) RejectAssignment(ctx context.Context, driverID uuid.UUID, orderID uuid.UUID) error {
-	return s.store.ExecTx(ctx, func(q postgres.Querier) error {
-		if err := q.RejectOrderAssignment(ctx, postgres.RejectOrderAssignmentParams{
-			ID:       orderID,
-			DriverID: pgtype.UUID{Bytes: driverID, Valid: true},
-		}); err != nil {
-			return err
+// UpdateDriverLocation records a fix in the driver's history at the time the
+// device took it and refreshes the ETAs of their active orders from it.
+// Refreshes are throttled per order by the ETA policy; a DRIVER_LATE event is sent once
+// when the estimate for the next stop falls more than the late threshold
+// behind its promise, and re-armed when the driver catches up.
+func (s *DispatchService) UpdateDriverLocation(ctx context.Context, driverID uuid.UUID, fix domain.LocationFix) error {
+	loc := fix.Location
+	if s.locations != nil {
+		recordedAt := fix.RecordedAt
+		if recordedAt.IsZero() {
+			recordedAt = time.Now()
 		}
+		s.locations.Record(driverID.String(), loc, recordedAt)
+	}
 
-		if err := q.SetDriverStatus(ctx, postgres.SetDriverStatusParams{
-			ID:     driverID,
-			Status: postgres.DriverStatusIdle,
-		}); err != nil {
+	if fleetID := s.driverFleet(ctx, driverID); fleetID != "" {
+		s.hub.PublishOps(websocket.OpsEvent{
+			Event:    websocket.EventDriverLocation,
+			FleetID:  fleetID,
+			DriverID: driverID.String(),
+			Location: &loc,
+		})
+	}
+
+	active, err := s.activeRouteOf(ctx, driverID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var arrivals []time.Time
+	estimated := false
+	for _, order := range active.orders {
+		s.hub.PublishDriverPosition(order.ID.String(), loc.Lat, loc.Lng)
+		s.detectArrival(ctx, driverID, order.ID, active.route, loc)
+
+		if order.EtaUpdatedAt.Valid && now.Sub(order.EtaUpdatedAt.Time) < s.etaPolicy.RefreshInterval {
+			continue
+		}
+		// the estimates follow the driver's route through the stops left on
+		// all their orders, so one pass serves every order and stops of
+		// stacked orders served first are counted
+		if !estimated {
+			if arrivals, err = s.routeArrivals(ctx, loc, now, active.route); err != nil {
+				return err
+			}
+			estimated = true
+		}
+		if err := s.updateOrderETA(ctx, driverID, order, active.route, arrivals, now); err != nil {
 			return err
 		}
+	}
+
+	return nil
+}
 
+// updateOrderETA saves the estimates for order read off arrivals, the times
+// the driver reaches each stop of route, and raises the late and at-risk
+// events when the order first crosses their thresholds.
+func (s *DispatchService) updateOrderETA(ctx context.Context, driverID uuid.UUID, order postgres.ListActiveOrdersByDriverRow, route []routeStop, arrivals []time.Time, now time.Time) error {
+	eta := domain.OrderETA{
+		PromisedPickupAt:  order.PromisedPickupAt.Time,
+		PromisedDropoffAt: order.PromisedDropoffAt.Time,
+		PickupAt:          order.PickupEtaAt.Time,
+		LateSince:         order.LateSince.Time,
+		UpdatedAt:         now,
+	}
+	for i, stop := range route {
+		if stop.OrderID != order.ID {
+			continue
+		}
+		if stop.Position == 0 {
+			eta.PickupAt = arrivals[i]
+		}
+		eta.DropoffAt = arrivals[i]
+	}
+	if eta.DropoffAt.IsZero() {
 		return nil
+	}
+
+	status := domain.OrderStatus(order.Status)
+	// an order on its way back to the sender is tracked to its return stop
+	// but no longer held to the delivery promise or deadline
+	returning := status == domain.OrderStatusDeliveryFailed
+
+	delay := eta.Delay(status)
+	late := !returning && delay >= s.etaPolicy.LateThreshold
+	switch {
+	case late && eta.LateSince.IsZero():
+		eta.LateSince = now
+	case !late:
+		eta.LateSince = time.Time{}
+	}
+
+	sla := domain.SLA{DeliverBy: order.DeliverBy.Time, AtRiskSince: order.SlaAtRiskSince.Time}
+	atRisk := !returning && sla.AtRisk(eta.DropoffAt, s.slaPolicy.AtRiskMargin)
+	switch {
+	case atRisk && sla.AtRiskSince.IsZero():
+		sla.AtRiskSince = now
+	case !atRisk:
+		sla.AtRiskSince = time.Time{}
+	}
+
+	if err := s.store.UpdateOrderETA(ctx, postgres.UpdateOrderETAParams{
+		ID:             order.ID,
+		PickupEtaAt:    timestamptz(eta.PickupAt),
+		DropoffEtaAt:   timestamptz(eta.DropoffAt),
+		LateSince:      timestamptz(eta.LateSince),
+		SlaAtRiskSince: timestamptz(sla.AtRiskSince),
+	}); err != nil {
+		return err
+	}
+	s.rememberETA(driverID, order.ID, eta, sla)
+
+	s.hub.PublishOrderEvent(order.ID.String(), map[string]any{
+		"event":    "ETA_UPDATED",
+		"order_id": order.ID,
+		"eta":      eta,
 	})
+
+	if late && !order.LateSince.Valid {
+		lateEvent := map[string]any{
+			"event":         "DRIVER_LATE",
+			"order_id":      order.ID,
+			"status":        status,
+			"delay_seconds": int(delay.Seconds()),
+			"eta":           eta,
+		}
+		s.hub.SendToDriver(driverID.String(), lateEvent)
+		s.hub.PublishOrderEvent(order.ID.String(), lateEvent)
+		if fleetID := s.driverFleet(ctx, driverID); fleetID != "" {
+			s.hub.PublishOps(websocket.OpsEvent{
+				Event:    websocket.EventOrderLate,
+				FleetID:  fleetID,
+				DriverID: driverID.String(),
+				OrderID:  order.ID.String(),
+				Status:   string(status),
+				Data:     lateEvent,
+			})
+		}
+	}
+
+	if atRisk && !order.SlaAtRiskSince.Valid {
+		s.publishSLAAtRisk(s.driverFleet(ctx, driverID), driverID.String(), order.ID, sla.DeliverBy, eta.DropoffAt)
+	}
+
+	return nil
 }
 
-func (s *DispatchService) ArriveAtPickup(ctx context.Context, driverID uuid.UUID, orderID uuid.UUID) error {
-	return s.store.ExecTx(ctx, func(q postgres.Querier) error {
-		rows, err := q.MarkOrderArrived(ctx, postgres.MarkOrderArrivedParams{
-			ID:       orderID,
-			DriverID: pgtype.UUID{Bytes: driverID, Valid: true},
-		})
-		if err != nil {
-			return err
+// activeRoute is what a driver was last found carrying: their active orders
+// and the route through the stops left on them, with when both were looked
+// up.
+type activeRoute struct {
+	orders    []postgres.ListActiveOrdersByDriverRow
+	route     []routeStop
+	fetchedAt time.Time
+}
+
+// activeRouteOf returns the orders driverID is carrying and their route,
+// querying them only when the last lookup is older than the ETA policy's
+// LookupInterval.
+func (s *DispatchService) activeRouteOf(ctx context.Context, driverID uuid.UUID) (activeRoute, error) {
+	now := time.Now()
+	if cached, ok := s.activeRoutes.Load(driverID); ok {
+		if entry := cached.(*activeRoute); now.Sub(entry.fetchedAt) < s.etaPolicy.LookupInterval {
+			return *entry, nil
 		}
-		if rows == 0 {
-			return domain.ErrInvalidTransition
+	}
+
+	entry := activeRoute{fetchedAt: now}
+	orders, err := s.store.ListActiveOrdersByDriver(ctx, pgtype.UUID{Bytes: driverID, Valid: true})
+	if err != nil {
+		return entry, err
+	}
+	if len(orders) > 0 {
+		route, err := pendingRoute(ctx, s.store, driverID)
+		if err != nil {
+			return entry, err
 		}
+		entry.orders, entry.route = orders, route
+	}
+	s.activeRoutes.Store(driverID, &entry)
+	return entry, nil
+}
 
-		return nil
+// rememberETA updates the cached active order of driverID with the ETA just
+// saved for it, so updates before the next lookup see it as saved.
+func (s *DispatchService) rememberETA(driverID uuid.UUID, orderID uuid.UUID, eta domain.OrderETA, sla domain.SLA) {
+	cached, ok := s.activeRoutes.Load(driverID)
+	if !ok {
+		return
+	}
+	entry := *cached.(*activeRoute)
+	i := slices.IndexFunc(entry.orders, func(order postgres.ListActiveOrdersByDriverRow) bool {
+		return order.ID == orderID
 	})
+	if i < 0 {
+		return
+	}
+	entry.orders = slices.Clone(entry.orders)
+	entry.orders[i].PickupEtaAt = timestamptz(eta.PickupAt)
+	entry.orders[i].LateSince = timestamptz(eta.LateSince)
+	entry.orders[i].SlaAtRiskSince = timestamptz(sla.AtRiskSince)
+	entry.orders[i].EtaUpdatedAt = timestamptz(eta.UpdatedAt)
+	s.activeRoutes.CompareAndSwap(driverID, cached, &entry)
 }
 
-func (s *DispatchService) PickUpOrder(ctx context.Context, driverID uuid.UUID, orderID uuid.UUID) error {
-	return s.store.ExecTx(ctx, func(q postgres.Querier) error {
-		rows, err := q.MarkOrderPickedUp(ctx, postgres.MarkOrderPickedUpParams{
-			ID:       orderID,
-			DriverID: pgtype.UUID{Bytes: driverID, Valid: true},
+// forgetActiveOrder drops the cached active order of driverID once its
+// orders change, so the next location update looks it up again.
+func (s *DispatchService) forgetActiveOrder(driverID uuid.UUID) {
+	s.activeRoutes.Delete(driverID)
+}
+
+func timestamptz(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: !t.IsZero()}
+}
+
+// redeemPromo records a promo redemption for the order. The conditional
+// increment locks the promo row until the transaction ends, so concurrent
+// orders are serialized and cannot exceed either usage limit.
+func redeemPromo(ctx context.Context, q postgres.Querier, orderID uuid.UUID, fare domain.Fare, customerID string) error {
+	promo, err := q.GetPromoCodeByCode(ctx, fare.PromoCode)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrPromoNotFound
+		}
+		return err
+	}
+
+	rows, err := q.ClaimPromoRedemption(ctx, promo.ID)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrPromoExhausted
+	}
+
+	customer := pgtype.Text{String: customerID, Valid: customerID != ""}
+	if promo.MaxPerCustomer.Valid {
+		used, err := q.CountPromoRedemptionsByCustomer(ctx, postgres.CountPromoRedemptionsByCustomerParams{
+			PromoID:    promo.ID,
+			CustomerID: customer,
 		})
 		if err != nil {
 			return err
 		}
-		if rows == 0 {
-			return domain.ErrInvalidTransition
+		if used >= int64(promo.MaxPerCustomer.Int32) {
+			return domain.ErrPromoCustomerLimit
 		}
+	}
 
-		return nil
+	return q.CreatePromoRedemption(ctx, postgres.CreatePromoRedemptionParams{
+		PromoID:       promo.ID,
+		OrderID:       orderID,
+		CustomerID:    customer,
+		DiscountCents: int32(-fare.Subtotal(domain.FareLineDiscount)),
 	})
 }
 
-func (s *DispatchService) CompleteOrder(ctx context.Context, driverID uuid.UUID, orderID uuid.UUID) error {
-	return s.store.ExecTx(ctx, func(q postgres.Querier) error {
-		rows, err := q.MarkOrderDelivered(ctx, postgres.MarkOrderDeliveredParams{
-			ID:       orderID,
-			DriverID: pgtype.UUID{Bytes: driverID, Valid: true},
-		})
-		if err != nil {
-			return err
+// recordDriverEarning credits the driver with the fleet's share of the fare,
+// rounded with the fleet's rounding rule.
+func recordDriverEarning(ctx context.Context, q postgres.Querier, driverID, orderID uuid.UUID) error {
+	order, err := q.GetOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	policyRow, err := q.GetFleetPricingPolicy(ctx, order.FleetID)
+	if err != nil {
+		return err
+	}
+	policy := postgres.PricingPolicyFromRow(policyRow)
+
+	earning := domain.NewMoney(int64(order.AmountCents), order.Currency).
+		Percent(policy.DriverSharePercent, policy.Rounding)
+
+	return q.CreateDriverEarning(ctx, postgres.CreateDriverEarningParams{
+		DriverID:    driverID,
+		OrderID:     orderID,
+		AmountCents: int32(earning.Amount),
+		Currency:    earning.Currency,
+	})
+}
+
+// AuthorizeOrder returns domain.ErrForbidden unless p holds at least role in
+// the order's fleet or, when assignee is set, is the order's driver.
+func (s *DispatchService) AuthorizeOrder(ctx context.Context, p domain.Principal, orderID uuid.UUID, role domain.Role, assignee bool) error {
+	order, err := s.store.GetOrder(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrOrderNotFound
 		}
-		if rows == 0 {
-			return domain.ErrInvalidTransition
+		return err
+	}
+
+	if p.Can(order.FleetID.String(), role) {
+		return nil
+	}
+	if assignee && order.DriverID.Valid && p.Is(uuid.UUID(order.DriverID.Bytes).String()) {
+		return nil
+	}
+	return domain.ErrForbidden
+}
+
+func (s *DispatchService) GetOrder(ctx context.Context, orderID uuid.UUID) (*domain.Order, error) {
+	row, err := s.store.GetOrder(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrOrderNotFound
 		}
+		return nil, err
+	}
 
-		if err := q.SetDriverStatus(ctx, postgres.SetDriverStatusParams{
+	lines, err := s.store.ListOrderFareLines(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	stops, err := s.listStops(ctx, s.store, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.listItems(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	fare := domain.NewFare(row.Currency)
+	for _, line := range lines {
+		fare.Add(domain.FareLineKind(line.Kind), line.Description, int(line.AmountCents))
+	}
+	if len(lines) == 0 {
+		// orders created before fares were itemized only kept the total
+		fare.Add(domain.FareLineBase, "Fare", int(row.AmountCents))
+	}
+
+	order := &domain.Order{
+		ID:      row.ID.String(),
+		FleetID: row.FleetID.String(),
+		Status:  domain.OrderStatus(row.Status),
+		Pickup:  domain.Location{Lat: row.PickupLat, Lng: row.PickupLng},
+		Dropoff: domain.Location{Lat: row.DropoffLat, Lng: row.DropoffLng},
+		Stops:   stops,
+		Items:   items,
+		Load: domain.Load{
+			Parcels:     int(row.ParcelCount),
+			WeightGrams: int(row.WeightGrams),
+			VolumeCm3:   int(row.VolumeCm3),
+			Fragile:     row.Fragile,
+			ColdChain:   row.ColdChain,
+			LongestCm:   int(row.LongestItemCm),
+		},
+		Vehicle:   domain.VehicleType(row.VehicleType),
+		Notes:     row.Notes,
+		Priority:  domain.OrderPriority(row.Priority),
+		Fare:      fare,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+
+		ScheduledPickupAt: row.ScheduledPickupAt.Time,
+	}
+	if !order.Status.IsActive() {
+		order.MaskPhones()
+	}
+	if row.DeliverBy.Valid {
+		order.SLA = &domain.SLA{
+			DeliverBy:   row.DeliverBy.Time,
+			AtRiskSince: row.SlaAtRiskSince.Time,
+			BreachedAt:  row.SlaBreachedAt.Time,
+		}
+	}
+	if row.RecurringOrderID.Valid {
+		order.RecurringOrderID = uuid.UUID(row.RecurringOrderID.Bytes).String()
+	}
+	if row.DriverID.Valid {
+		order.DriverID = uuid.UUID(row.DriverID.Bytes).String()
+	}
+	if row.EtaUpdatedAt.Valid {
+		order.ETA = &domain.OrderETA{
+			PromisedPickupAt:  row.PromisedPickupAt.Time,
+			PromisedDropoffAt: row.PromisedDropoffAt.Time,
+			PickupAt:          row.PickupEtaAt.Time,
+			DropoffAt:         row.DropoffEtaAt.Time,
+			UpdatedAt:         row.EtaUpdatedAt.Time,
+			LateSince:         row.LateSince.Time,
+		}
+	}
+
+	return order, nil
+}
+
+func (s *DispatchService) listItems(ctx context.Context, orderID uuid.UUID) ([]domain.Item, error) {
+	rows, err := s.store.ListOrderItems(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]domain.Item, len(rows))
+	for i, row := range rows {
+		items[i] = domain.Item{
+			Description:        row.Description,
+			Quantity:           int(row.Quantity),
+			WeightGrams:        int(row.WeightGrams),
+			LengthCm:           int(row.LengthCm),
+			WidthCm:            int(row.WidthCm),
+			HeightCm:           int(row.HeightCm),
+			Fragile:            row.Fragile,
+			ColdChain:          row.ColdChain,
+			DeclaredValueCents: int(row.DeclaredValueCents),
+		}
+	}
+
+	return items, nil
+}
+
+func (s *DispatchService) AcceptAssignment(ctx context.Context, driverID uuid.UUID, orderID uuid.UUID) error {
+	if err := s.store.ExecTx(ctx, func(q postgres.Querier) error {
+		return q.SetDriverStatus(ctx, postgres.SetDriverStatusParams{
 			ID:     driverID,
-			Status: postgres.DriverStatusIdle,
+			Status: postgres.DriverStatusEnRoute,
+		})
+	}); err != nil {
+		return err
+	}
+
+	s.publishDriverStatus(s.driverFleet(ctx, driverID), driverID, postgres.DriverStatusEnRoute)
+
+	return nil
+}
+
+// RejectAssignment hands the order back for dispatch. The driver goes idle
+// unless they still carry other orders.
+func (s *DispatchService) RejectAssignment(ctx context.Context, driverID uuid.UUID, orderID uuid.UUID) error {
+	var idle bool
+	if err := s.store.ExecTx(ctx, func(q postgres.Querier) error {
+		if err := q.RejectOrderAssignment(ctx, postgres.RejectOrderAssignmentParams{
+			ID:       orderID,
+			DriverID: pgtype.UUID{Bytes: driverID, Valid: true},
 		}); err != nil {
 			return err
 		}
 
-		return nil
-	})
+		var err error
+		idle, err = releaseDriver(ctx, q, driverID)
+		return err
+	}); err != nil {
+		return err
+	}
+	s.forgetActiveOrder(driverID)
+	s.forgetArrivalPrompts(orderID)
+
+	fleetID := s.driverFleet(ctx, driverID)
+	if idle {
+		s.publishDriverStatus(fleetID, driverID, postgres.DriverStatusIdle)
+	}
+	s.publishStatus(fleetID, orderID, domain.OrderStatusPending, nil)
+	s.publishUnassigned(fleetID, orderID, nil)
+
+	return nil
+}
+
+// ArriveAtPickup handles a driver reporting arrival at the order's next stop,
+// which must be a pickup. The arrival is checked against their live position
+// as the geofence policy says.
+func (s *DispatchService) ArriveAtPickup(ctx context.Context, driverID uuid.UUID, orderID uuid.UUID) error {
+	return s.advanceStop(ctx, driverID, orderID, isPickup, false, true)
+}
+
+// PickUpOrder completes the order's next stop, which must be a pickup.
+func (s *DispatchService) PickUpOrder(ctx context.Context, driverID uuid.UUID, orderID uuid.UUID) error {
+	return s.advanceStop(ctx, driverID, orderID, isPickup, true, false)
+}
+
+// CompleteOrder completes the order's next stop, which must be a dropoff.
+// The order is delivered once its last stop is.
+func (s *DispatchService) CompleteOrder(ctx context.Context, driverID uuid.UUID, orderID uuid.UUID) error {
+	return s.advanceStop(ctx, driverID, orderID, isDropoff, true, false)
 }
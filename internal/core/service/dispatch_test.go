@@ -2,14 +2,23 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	gorillaws "github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
 	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
 )
 
 func TestDispatchService_CreateAndDispatchOrder_WithOneDriver(t *testing.T) {
@@ -21,58 +30,765 @@ func TestDispatchService_CreateAndDispatchOrder_WithOneDriver(t *testing.T) {
 
 	driverID := uuid.New()
 	fleetID := uuid.New()
+	mockRepo.On("CheckServiceArea", mock.Anything, mock.Anything).Return(postgres.CheckServiceAreaRow{}, nil)
 	mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
 	mockRepo.On("CreateOrder", mock.Anything, mock.Anything).Return(postgres.CreateOrderRow{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
 	}, nil)
+	mockRepo.On("CreateOrderStop", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateOrderFareLine", mock.Anything, mock.Anything).Return(nil)
 	mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{
 		ID:      driverID,
 		FleetID: fleetID,
 		Status:  postgres.DriverStatusIdle,
 	}, nil)
+	mockRepo.On("GetDriverLoad", mock.Anything, driverID).Return(postgres.GetDriverLoadRow{}, nil)
+	mockRepo.On("LockDriverStatus", mock.Anything, driverID).Return(postgres.DriverStatusIdle, nil)
 	mockRepo.On("SetDriverStatus", mock.Anything, mock.Anything).Return(nil)
-	mockRepo.On("AssignDriverToOrder", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("UpdateOrderStopSequences", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("AssignDriverToOrder", mock.Anything, mock.MatchedBy(func(arg postgres.AssignDriverToOrderParams) bool {
+		return arg.PromisedPickupAt.Valid && arg.PromisedDropoffAt.Time.After(arg.PromisedPickupAt.Time)
+	})).Return(int64(1), nil)
 
 	mockGeo.On("FindNearestDrivers", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
-		Return([]string{driverID.String()}, nil)
+		Return([]domain.NearbyDriver{{ID: driverID.String(), Location: domain.Location{Lat: 40.01, Lng: -74.01}}}, nil)
 
-	_, err := svc.CreateAndDispatchOrder(context.Background(), fleetID, 40.0, -74.0, 40.1, -74.1)
+	_, err := svc.CreateAndDispatchOrder(context.Background(), CreateOrderInput{
+		FleetID: fleetID,
+		Pickup:  domain.Location{Lat: 40.0, Lng: -74.0},
+		Dropoff: domain.Location{Lat: 40.1, Lng: -74.1},
+	})
 
 	assert.NoError(t, err)
+	mockRepo.AssertCalled(t, "CreateOrder", mock.Anything, mock.MatchedBy(func(arg postgres.CreateOrderParams) bool {
+		return arg.ParcelCount == 1
+	}))
 	mockRepo.AssertExpectations(t)
 	mockGeo.AssertExpectations(t)
 }
 
+func TestDispatchService_CreateAndDispatchOrder_DriverChanged(t *testing.T) {
+	tests := []struct {
+		name         string
+		changedLocks int
+		wantAssigned bool
+	}{
+		{name: "Planned Again", changedLocks: 1, wantAssigned: true},
+		{name: "Gives Up", changedLocks: maxAssignAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockQuerier)
+			mockGeo := new(MockGeoFinder)
+			svc := NewDispatchService(mockRepo, mockGeo, &websocket.Hub{})
+
+			driverID := uuid.New()
+			fleetID := uuid.New()
+			mockRepo.On("CheckServiceArea", mock.Anything, mock.Anything).Return(postgres.CheckServiceAreaRow{}, nil)
+			mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("CreateOrder", mock.Anything, mock.Anything).Return(postgres.CreateOrderRow{ID: uuid.New(), CreatedAt: time.Now()}, nil)
+			mockRepo.On("CreateOrderStop", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("CreateOrderFareLine", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{
+				ID: driverID, FleetID: fleetID, Status: postgres.DriverStatusIdle,
+			}, nil)
+			mockRepo.On("GetDriverLoad", mock.Anything, driverID).Return(postgres.GetDriverLoadRow{}, nil)
+			// a concurrent dispatch gives the driver another order after they
+			// were planned for
+			mockRepo.On("LockDriverStatus", mock.Anything, driverID).Return(postgres.DriverStatusEnRoute, nil).Times(tt.changedLocks)
+			mockRepo.On("LockDriverStatus", mock.Anything, driverID).Return(postgres.DriverStatusIdle, nil)
+			mockRepo.On("SetDriverStatus", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("UpdateOrderStopSequences", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("AssignDriverToOrder", mock.Anything, mock.Anything).Return(int64(1), nil)
+			mockGeo.On("FindNearestDrivers", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return([]domain.NearbyDriver{{ID: driverID.String(), Location: domain.Location{Lat: 40.01, Lng: -74.01}}}, nil)
+
+			_, err := svc.CreateAndDispatchOrder(context.Background(), CreateOrderInput{
+				FleetID: fleetID,
+				Pickup:  domain.Location{Lat: 40.0, Lng: -74.0},
+				Dropoff: domain.Location{Lat: 40.1, Lng: -74.1},
+			})
+
+			if tt.wantAssigned {
+				assert.NoError(t, err)
+				mockRepo.AssertNumberOfCalls(t, "AssignDriverToOrder", 1)
+				return
+			}
+			assert.ErrorIs(t, err, errDriverChanged)
+			mockRepo.AssertNumberOfCalls(t, "LockDriverStatus", maxAssignAttempts)
+			mockRepo.AssertNotCalled(t, "AssignDriverToOrder", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestDispatchService_CreateAndDispatchOrder_Items(t *testing.T) {
+	items := []domain.Item{
+		{Description: "Vaccines", Quantity: 2, WeightGrams: 1500, LengthCm: 40, WidthCm: 30, HeightCm: 30, ColdChain: true},
+		{Description: "Glassware", Quantity: 1, WeightGrams: 3000, Fragile: true, DeclaredValueCents: 12000},
+	}
+
+	tests := []struct {
+		name         string
+		refrigerated bool
+		wantErr      bool
+	}{
+		{name: "Refrigerated Driver", refrigerated: true},
+		{name: "No Refrigerated Driver", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockQuerier)
+			mockGeo := new(MockGeoFinder)
+			driverID := uuid.New()
+			fleetID := uuid.New()
+
+			var priced domain.PricingInput
+			svc := NewDispatchService(mockRepo, mockGeo, &websocket.Hub{})
+			svc.SetPricingStrategy(pricerFunc(func(input domain.PricingInput) {
+				priced = input
+			}))
+
+			mockRepo.On("CheckServiceArea", mock.Anything, mock.Anything).Return(postgres.CheckServiceAreaRow{}, nil)
+			mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("CreateOrder", mock.Anything, mock.Anything).Return(postgres.CreateOrderRow{ID: uuid.New()}, nil)
+			mockRepo.On("CreateOrderStop", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("CreateOrderItem", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("CreateOrderFareLine", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{
+				ID: driverID, FleetID: fleetID, Status: postgres.DriverStatusIdle,
+			}, nil)
+			mockRepo.On("GetDriverLoad", mock.Anything, driverID).Return(postgres.GetDriverLoadRow{ColdChain: tt.refrigerated}, nil)
+			mockRepo.On("LockDriverStatus", mock.Anything, driverID).Return(postgres.DriverStatusIdle, nil)
+			mockRepo.On("SetDriverStatus", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("UpdateOrderStopSequences", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("AssignDriverToOrder", mock.Anything, mock.Anything).Return(int64(1), nil)
+			mockGeo.On("FindNearestDrivers", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return([]domain.NearbyDriver{{ID: driverID.String(), Location: domain.Location{Lat: 40.01, Lng: -74.01}}}, nil)
+
+			_, err := svc.CreateAndDispatchOrder(context.Background(), CreateOrderInput{
+				FleetID: fleetID,
+				Pickup:  domain.Location{Lat: 40.0, Lng: -74.0},
+				Dropoff: domain.Location{Lat: 40.1, Lng: -74.1},
+				Items:   items,
+			})
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				mockRepo.AssertNotCalled(t, "AssignDriverToOrder", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, domain.ItemsLoad(items), priced.Load)
+			mockRepo.AssertCalled(t, "CreateOrder", mock.Anything, mock.MatchedBy(func(arg postgres.CreateOrderParams) bool {
+				return arg.ParcelCount == 3 && arg.WeightGrams == 6000 && arg.ColdChain && arg.Fragile && arg.LongestItemCm == 40
+			}))
+			mockRepo.AssertNumberOfCalls(t, "CreateOrderItem", 2)
+		})
+	}
+}
+
+func TestDispatchService_CreateAndDispatchOrder_PromoExhausted(t *testing.T) {
+	mockRepo := new(MockQuerier)
+	mockGeo := new(MockGeoFinder)
+
+	svc := NewDispatchService(mockRepo, mockGeo, &websocket.Hub{})
+	fare := domain.NewFare(domain.DefaultCurrency)
+	fare.Add(domain.FareLineBase, "Base fare", 1000)
+	fare.Add(domain.FareLineDiscount, "Promo LAUNCH", -200)
+	fare.PromoCode = "LAUNCH"
+	svc.SetPricingStrategy(stubPricer{fare: fare})
+
+	promoID := uuid.New()
+	mockRepo.On("CheckServiceArea", mock.Anything, mock.Anything).Return(postgres.CheckServiceAreaRow{}, nil)
+	mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateOrder", mock.Anything, mock.Anything).Return(postgres.CreateOrderRow{ID: uuid.New()}, nil)
+	mockRepo.On("CreateOrderStop", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateOrderFareLine", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("GetPromoCodeByCode", mock.Anything, "LAUNCH").Return(postgres.GetPromoCodeByCodeRow{ID: promoID, Code: "LAUNCH"}, nil)
+	mockRepo.On("ClaimPromoRedemption", mock.Anything, promoID).Return(int64(0), nil)
+
+	_, err := svc.CreateAndDispatchOrder(context.Background(), CreateOrderInput{
+		FleetID:   uuid.New(),
+		Pickup:    domain.Location{Lat: 40.0, Lng: -74.0},
+		Dropoff:   domain.Location{Lat: 40.1, Lng: -74.1},
+		PromoCode: "LAUNCH",
+	})
+
+	assert.ErrorIs(t, err, domain.ErrPromoExhausted)
+	mockRepo.AssertNotCalled(t, "CreatePromoRedemption", mock.Anything, mock.Anything)
+	mockGeo.AssertNotCalled(t, "FindNearestDrivers", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDispatchService_CreateAndDispatchOrder_OutsideServiceArea(t *testing.T) {
+	tests := []struct {
+		name     string
+		coverage postgres.CheckServiceAreaRow
+		wantErr  error
+	}{
+		{
+			name:     "Pickup Outside",
+			coverage: postgres.CheckServiceAreaRow{HasAreas: true, Uncovered: []int32{0}},
+			wantErr:  domain.ErrPickupOutsideServiceArea,
+		},
+		{
+			name:     "Dropoff Outside",
+			coverage: postgres.CheckServiceAreaRow{HasAreas: true, Uncovered: []int32{1}},
+			wantErr:  domain.ErrDropoffOutsideServiceArea,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockQuerier)
+			mockGeo := new(MockGeoFinder)
+			fleetID := uuid.New()
+
+			mockRepo.On("CheckServiceArea", mock.Anything, postgres.CheckServiceAreaParams{
+				FleetID: fleetID,
+				Lngs:    []float64{-74.0, -74.1},
+				Lats:    []float64{40.0, 40.1},
+			}).Return(tt.coverage, nil)
+
+			svc := NewDispatchService(mockRepo, mockGeo, &websocket.Hub{})
+			_, err := svc.CreateAndDispatchOrder(context.Background(), CreateOrderInput{
+				FleetID: fleetID,
+				Pickup:  domain.Location{Lat: 40.0, Lng: -74.0},
+				Dropoff: domain.Location{Lat: 40.1, Lng: -74.1},
+			})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			mockRepo.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestDispatchService_UpdateDriverLocation_EmitsLateOnce(t *testing.T) {
+	mockRepo := new(MockQuerier)
+	hub := &websocket.Hub{}
+	svc := NewDispatchService(mockRepo, new(MockGeoFinder), hub)
+	svc.SetRoutingProvider(stubRouter{duration: 20 * time.Minute})
+	svc.SetETAPolicy(ETAPolicy{LateThreshold: 5 * time.Minute})
+
+	driverID := uuid.New()
+	fleetID := uuid.New()
+	orderID := uuid.New()
+	promised := pgtype.Timestamptz{Time: time.Now().Add(5 * time.Minute), Valid: true}
+	order := postgres.ListActiveOrdersByDriverRow{
+		ID:                orderID,
+		Status:            postgres.OrderStatusAssigned,
+		PromisedPickupAt:  promised,
+		PromisedDropoffAt: promised,
+		EtaUpdatedAt:      pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true},
+	}
+	// the second update reads the order back as the first one saved it
+	lateOrder := order
+	lateOrder.LateSince = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: fleetID}, nil)
+	mockRepo.On("ListActiveOrdersByDriver", mock.Anything, pgtype.UUID{Bytes: driverID, Valid: true}).Return([]postgres.ListActiveOrdersByDriverRow{order}, nil).Once()
+	mockRepo.On("ListActiveOrdersByDriver", mock.Anything, pgtype.UUID{Bytes: driverID, Valid: true}).Return([]postgres.ListActiveOrdersByDriverRow{lateOrder}, nil).Once()
+	mockRepo.On("ListDriverPendingStops", mock.Anything, pgtype.UUID{Bytes: driverID, Valid: true}).Return(pendingStops(orderID, 0, 1), nil)
+	mockRepo.On("UpdateOrderETA", mock.Anything, mock.MatchedBy(func(arg postgres.UpdateOrderETAParams) bool {
+		return arg.ID == orderID && arg.LateSince.Valid && arg.DropoffEtaAt.Time.Sub(arg.PickupEtaAt.Time) == 20*time.Minute
+	})).Return(nil).Twice()
+
+	feed := subscribeOps(t, hub, fleetID.String())
+	for range 2 {
+		err := svc.UpdateDriverLocation(context.Background(), driverID, domain.LocationFix{Location: domain.Location{Lat: 40.0, Lng: -74.0}, RecordedAt: time.Now()})
+		assert.NoError(t, err)
+	}
+
+	late := 0
+	for _, event := range readOps(t, feed) {
+		if event["event"] == websocket.EventOrderLate {
+			late++
+		}
+	}
+	assert.Equal(t, 1, late)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDispatchService_UpdateDriverLocation_ReusesActiveOrder(t *testing.T) {
+	mockRepo := new(MockQuerier)
+	svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+	svc.SetRoutingProvider(stubRouter{duration: 20 * time.Minute})
+	svc.SetETAPolicy(ETAPolicy{LateThreshold: 5 * time.Minute, LookupInterval: time.Minute})
+
+	driverID := uuid.New()
+	orderID := uuid.New()
+	promised := pgtype.Timestamptz{Time: time.Now().Add(5 * time.Minute), Valid: true}
+	mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: uuid.New()}, nil)
+	mockRepo.On("ListActiveOrdersByDriver", mock.Anything, pgtype.UUID{Bytes: driverID, Valid: true}).
+		Return([]postgres.ListActiveOrdersByDriverRow{{
+			ID:                orderID,
+			Status:            postgres.OrderStatusAssigned,
+			PromisedPickupAt:  promised,
+			PromisedDropoffAt: promised,
+		}}, nil).Once()
+	mockRepo.On("ListDriverPendingStops", mock.Anything, pgtype.UUID{Bytes: driverID, Valid: true}).Return(pendingStops(orderID, 0, 1), nil)
+	mockRepo.On("UpdateOrderETA", mock.Anything, mock.Anything).Return(nil)
+
+	for range 3 {
+		err := svc.UpdateDriverLocation(context.Background(), driverID, domain.LocationFix{Location: domain.Location{Lat: 40.0, Lng: -74.0}, RecordedAt: time.Now()})
+		assert.NoError(t, err)
+	}
+
+	mockRepo.AssertNumberOfCalls(t, "ListActiveOrdersByDriver", 1)
+	mockRepo.AssertNumberOfCalls(t, "ListDriverPendingStops", 1)
+	// the cached order keeps the late flag the first update saved
+	calls := 0
+	for _, call := range mockRepo.Calls {
+		if call.Method == "UpdateOrderETA" {
+			calls++
+			assert.True(t, call.Arguments.Get(1).(postgres.UpdateOrderETAParams).LateSince.Valid)
+		}
+	}
+	assert.Equal(t, 3, calls)
+
+	svc.forgetActiveOrder(driverID)
+	mockRepo.On("ListActiveOrdersByDriver", mock.Anything, mock.Anything).Return([]postgres.ListActiveOrdersByDriverRow{}, nil).Once()
+	err := svc.UpdateDriverLocation(context.Background(), driverID, domain.LocationFix{Location: domain.Location{Lat: 40.0, Lng: -74.0}, RecordedAt: time.Now()})
+	assert.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "ListActiveOrdersByDriver", 2)
+}
+
+func TestDispatchService_UpdateDriverLocation_Throttled(t *testing.T) {
+	mockRepo := new(MockQuerier)
+	svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+
+	driverID := uuid.New()
+	orderID := uuid.New()
+	mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: uuid.New()}, nil)
+	mockRepo.On("ListActiveOrdersByDriver", mock.Anything, mock.Anything).Return([]postgres.ListActiveOrdersByDriverRow{{
+		ID:           orderID,
+		Status:       postgres.OrderStatusPickedUp,
+		EtaUpdatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}}, nil)
+	mockRepo.On("ListDriverPendingStops", mock.Anything, mock.Anything).Return(pendingStops(orderID, 1), nil)
+
+	err := svc.UpdateDriverLocation(context.Background(), driverID, domain.LocationFix{Location: domain.Location{Lat: 40.0, Lng: -74.0}, RecordedAt: time.Now()})
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "UpdateOrderETA", mock.Anything, mock.Anything)
+}
+
+func TestDispatchService_UpdateDriverLocation_FollowsRoute(t *testing.T) {
+	mockRepo := new(MockQuerier)
+	svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+	svc.SetRoutingProvider(stubRouter{duration: 20 * time.Minute})
+
+	driverID := uuid.New()
+	orderID := uuid.New()
+	mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: uuid.New()}, nil)
+	mockRepo.On("ListActiveOrdersByDriver", mock.Anything, mock.Anything).Return([]postgres.ListActiveOrdersByDriverRow{{
+		ID:     orderID,
+		Status: postgres.OrderStatusAssigned,
+	}}, nil)
+	// a stacked order's dropoff is served before this order's stops
+	route := append(pendingStops(uuid.New(), 1), pendingStops(orderID, 0, 1, 2)...)
+	mockRepo.On("ListDriverPendingStops", mock.Anything, mock.Anything).Return(route, nil)
+	mockRepo.On("UpdateOrderETA", mock.Anything, mock.Anything).Return(nil)
+
+	now := time.Now()
+	err := svc.UpdateDriverLocation(context.Background(), driverID, domain.LocationFix{Location: domain.Location{Lat: 40.0, Lng: -74.0}, RecordedAt: now})
+
+	require.NoError(t, err)
+	arg := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(1).(postgres.UpdateOrderETAParams)
+	assert.WithinDuration(t, now.Add(40*time.Minute), arg.PickupEtaAt.Time, time.Second)
+	assert.Equal(t, 40*time.Minute, arg.DropoffEtaAt.Time.Sub(arg.PickupEtaAt.Time), "the dropoff is reached through the middle stop")
+}
+
+func TestDispatchService_UpdateDriverLocation_TracksReturn(t *testing.T) {
+	mockRepo := new(MockQuerier)
+	svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+	svc.SetRoutingProvider(stubRouter{duration: 20 * time.Minute})
+
+	driverID := uuid.New()
+	orderID := uuid.New()
+	now := time.Now()
+	mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: uuid.New()}, nil)
+	mockRepo.On("ListActiveOrdersByDriver", mock.Anything, mock.Anything).Return([]postgres.ListActiveOrdersByDriverRow{{
+		ID:                orderID,
+		Status:            postgres.OrderStatusDeliveryFailed,
+		PromisedDropoffAt: timestamptz(now.Add(-time.Hour)),
+		DeliverBy:         timestamptz(now.Add(-time.Hour)),
+	}}, nil)
+	// the failed dropoff is off the route and the return stop is left
+	route := pendingStops(orderID, 2)
+	route[0].Kind = postgres.OrderStopKindReturn
+	mockRepo.On("ListDriverPendingStops", mock.Anything, mock.Anything).Return(route, nil)
+	mockRepo.On("UpdateOrderETA", mock.Anything, mock.Anything).Return(nil)
+
+	err := svc.UpdateDriverLocation(context.Background(), driverID, domain.LocationFix{Location: domain.Location{Lat: 40.0, Lng: -74.0}, RecordedAt: now})
+
+	require.NoError(t, err)
+	arg := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(1).(postgres.UpdateOrderETAParams)
+	assert.WithinDuration(t, now.Add(20*time.Minute), arg.DropoffEtaAt.Time, time.Second)
+	assert.False(t, arg.LateSince.Valid, "the return is not held to the delivery promise")
+	assert.False(t, arg.SlaAtRiskSince.Valid)
+}
+
+func TestDispatchService_UpdateDriverLocation_RefreshesEveryOrder(t *testing.T) {
+	mockRepo := new(MockQuerier)
+	svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+	svc.SetRoutingProvider(stubRouter{duration: 20 * time.Minute})
+
+	driverID := uuid.New()
+	firstID, stackedID := uuid.New(), uuid.New()
+	mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: uuid.New()}, nil)
+	mockRepo.On("ListActiveOrdersByDriver", mock.Anything, mock.Anything).Return([]postgres.ListActiveOrdersByDriverRow{
+		{ID: firstID, Status: postgres.OrderStatusPickedUp},
+		{ID: stackedID, Status: postgres.OrderStatusAssigned},
+	}, nil)
+	route := append(pendingStops(stackedID, 0), pendingStops(firstID, 1)...)
+	route = append(route, pendingStops(stackedID, 1)...)
+	mockRepo.On("ListDriverPendingStops", mock.Anything, mock.Anything).Return(route, nil)
+	etas := map[uuid.UUID]postgres.UpdateOrderETAParams{}
+	mockRepo.On("UpdateOrderETA", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		arg := args.Get(1).(postgres.UpdateOrderETAParams)
+		etas[arg.ID] = arg
+	}).Return(nil)
+
+	now := time.Now()
+	err := svc.UpdateDriverLocation(context.Background(), driverID, domain.LocationFix{Location: domain.Location{Lat: 40.0, Lng: -74.0}, RecordedAt: now})
+
+	require.NoError(t, err)
+	require.Len(t, etas, 2, "every order the driver carries gets an estimate")
+	assert.WithinDuration(t, now.Add(40*time.Minute), etas[firstID].DropoffEtaAt.Time, time.Second)
+	assert.WithinDuration(t, now.Add(20*time.Minute), etas[stackedID].PickupEtaAt.Time, time.Second)
+	assert.WithinDuration(t, now.Add(60*time.Minute), etas[stackedID].DropoffEtaAt.Time, time.Second)
+}
+
+func TestDispatchService_UpdateDriverLocation_RecordsFixTime(t *testing.T) {
+	mockRepo := new(MockQuerier)
+	svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+	recorder := &stubRecorder{}
+	svc.SetLocationRecorder(recorder)
+
+	driverID := uuid.New()
+	mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: uuid.New()}, nil)
+	mockRepo.On("ListActiveOrdersByDriver", mock.Anything, mock.Anything).Return([]postgres.ListActiveOrdersByDriverRow{}, nil)
+
+	takenAt := time.Now().Add(-20 * time.Second)
+	loc := domain.Location{Lat: 40.0, Lng: -74.0}
+	err := svc.UpdateDriverLocation(context.Background(), driverID, domain.LocationFix{Location: loc, RecordedAt: takenAt})
+
+	assert.NoError(t, err)
+	require.Len(t, recorder.points, 1)
+	assert.Equal(t, recordedPoint{driverID: driverID.String(), loc: loc, at: takenAt}, recorder.points[0])
+}
+
+func TestDispatchService_AuthorizeOrder(t *testing.T) {
+	fleetID := uuid.New()
+	driverID := uuid.New()
+	orderID := uuid.New()
+	missingID := uuid.New()
+
+	mockRepo := new(MockQuerier)
+	mockRepo.On("GetOrder", mock.Anything, orderID).Return(postgres.GetOrderRow{
+		ID:       orderID,
+		FleetID:  fleetID,
+		DriverID: pgtype.UUID{Bytes: driverID, Valid: true},
+	}, nil)
+	mockRepo.On("GetOrder", mock.Anything, missingID).Return(postgres.GetOrderRow{}, pgx.ErrNoRows)
+	svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+
+	dispatcher := domain.Principal{DriverID: uuid.NewString(), FleetID: fleetID.String(), Role: domain.RoleDispatcher}
+	assignee := domain.Principal{DriverID: driverID.String(), FleetID: fleetID.String(), Role: domain.RoleDriver}
+	otherDriver := domain.Principal{DriverID: uuid.NewString(), FleetID: fleetID.String(), Role: domain.RoleDriver}
+	otherFleet := domain.Principal{DriverID: uuid.NewString(), FleetID: uuid.NewString(), Role: domain.RoleAdmin}
+
+	tests := []struct {
+		name      string
+		principal domain.Principal
+		orderID   uuid.UUID
+		assignee  bool
+		wantErr   error
+	}{
+		{name: "Dispatcher Of Fleet", principal: dispatcher, orderID: orderID},
+		{name: "Admin Of Other Fleet", principal: otherFleet, orderID: orderID, assignee: true, wantErr: domain.ErrForbidden},
+		{name: "Assigned Driver", principal: assignee, orderID: orderID, assignee: true},
+		{name: "Assigned Driver Not Allowed", principal: assignee, orderID: orderID, wantErr: domain.ErrForbidden},
+		{name: "Other Driver", principal: otherDriver, orderID: orderID, assignee: true, wantErr: domain.ErrForbidden},
+		{name: "Unknown Order", principal: dispatcher, orderID: missingID, wantErr: domain.ErrOrderNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.AuthorizeOrder(context.Background(), tt.principal, tt.orderID, domain.RoleDispatcher, tt.assignee)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// subscribeOps opens a dispatcher feed of fleetID on hub and reads past its
+// snapshot.
+func subscribeOps(t *testing.T, hub *websocket.Hub, fleetID string) *gorillaws.Conn {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ops", func(c *gin.Context) {
+		websocket.ServeOps(hub, c, fleetID, domain.OpsFilter{}, func() (domain.OpsSnapshot, error) {
+			return domain.OpsSnapshot{}, nil
+		})
+	})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	conn, _, err := gorillaws.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ops", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	var snapshot map[string]any
+	require.NoError(t, conn.ReadJSON(&snapshot))
+	require.Equal(t, websocket.EventSnapshot, snapshot["event"])
+	return conn
+}
+
+// readOps returns the events the feed delivers until it goes quiet, after
+// which conn may no longer be read.
+func readOps(t *testing.T, conn *gorillaws.Conn) []map[string]any {
+	t.Helper()
+	var events []map[string]any
+	for {
+		conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return events
+		}
+		var event map[string]any
+		require.NoError(t, json.Unmarshal(msg, &event))
+		events = append(events, event)
+	}
+}
+
+type recordedPoint struct {
+	driverID string
+	loc      domain.Location
+	at       time.Time
+}
+
+type stubRecorder struct {
+	points []recordedPoint
+}
+
+func (r *stubRecorder) Record(driverID string, loc domain.Location, at time.Time) {
+	r.points = append(r.points, recordedPoint{driverID: driverID, loc: loc, at: at})
+}
+
+// pendingStops is a route through the stops of orderID at positions, the
+// first of which is a pickup.
+func pendingStops(orderID uuid.UUID, positions ...int32) []postgres.ListDriverPendingStopsRow {
+	rows := make([]postgres.ListDriverPendingStopsRow, len(positions))
+	for i, position := range positions {
+		kind := postgres.OrderStopKindDropoff
+		if position == 0 {
+			kind = postgres.OrderStopKindPickup
+		}
+		rows[i] = postgres.ListDriverPendingStopsRow{
+			OrderID:  orderID,
+			Position: position,
+			Kind:     kind,
+			Lat:      40.1 + float64(position)/100,
+			Lng:      -74.0,
+			Status:   postgres.OrderStopStatusPending,
+		}
+	}
+	return rows
+}
+
+type stubRouter struct {
+	duration time.Duration
+}
+
+func (r stubRouter) Route(ctx context.Context, from, to domain.Location) (domain.Route, error) {
+	return domain.Route{DistanceMeters: 1000, Duration: r.duration}, nil
+}
+
+type stubPricer struct {
+	fare domain.Fare
+}
+
+func (p stubPricer) CalculatePrice(ctx context.Context, input domain.PricingInput) (domain.Fare, error) {
+	return p.fare, nil
+}
+
+// pricerFunc records the input it is priced with and returns a flat fare.
+type pricerFunc func(domain.PricingInput)
+
+func (f pricerFunc) CalculatePrice(ctx context.Context, input domain.PricingInput) (domain.Fare, error) {
+	f(input)
+	fare := domain.NewFare(domain.DefaultCurrency)
+	fare.Add(domain.FareLineBase, "Base fare", 1000)
+	return fare, nil
+}
+
 type MockQuerier struct {
 	mock.Mock
 }
 
-func (m *MockQuerier) AssignDriverToOrder(ctx context.Context, arg postgres.AssignDriverToOrderParams) error {
+func (m *MockQuerier) AddOrderCharge(ctx context.Context, arg postgres.AddOrderChargeParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) AddRecurringOrderSkip(ctx context.Context, arg postgres.AddRecurringOrderSkipParams) error {
 	args := m.Called(ctx, arg)
 	return args.Error(0)
 }
 
+func (m *MockQuerier) AssignDriverToOrder(ctx context.Context, arg postgres.AssignDriverToOrderParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) CancelRecurringOccurrences(ctx context.Context, arg postgres.CancelRecurringOccurrencesParams) ([]uuid.UUID, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockQuerier) CancelScheduledOrder(ctx context.Context, id uuid.UUID) (int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) CheckServiceArea(ctx context.Context, arg postgres.CheckServiceAreaParams) (postgres.CheckServiceAreaRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(postgres.CheckServiceAreaRow), args.Error(1)
+}
+
+func (m *MockQuerier) ClaimPromoRedemption(ctx context.Context, id uuid.UUID) (int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockQuerier) ConfirmOrderAcceptance(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockQuerier) CountActiveOrdersByDriver(ctx context.Context, driverID pgtype.UUID) (int64, error) {
+	args := m.Called(ctx, driverID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) CountDeliveryAttempts(ctx context.Context, arg postgres.CountDeliveryAttemptsParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) CountPromoRedemptionsByCustomer(ctx context.Context, arg postgres.CountPromoRedemptionsByCustomerParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) CreateDeliveryAttempt(ctx context.Context, arg postgres.CreateDeliveryAttemptParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
 func (m *MockQuerier) CreateDriver(ctx context.Context, arg postgres.CreateDriverParams) (postgres.CreateDriverRow, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(postgres.CreateDriverRow), args.Error(1)
 }
 
+func (m *MockQuerier) CreateDriverEarning(ctx context.Context, arg postgres.CreateDriverEarningParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
 func (m *MockQuerier) CreateOrder(ctx context.Context, arg postgres.CreateOrderParams) (postgres.CreateOrderRow, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(postgres.CreateOrderRow), args.Error(1)
 }
 
+func (m *MockQuerier) CreateOrderFareLine(ctx context.Context, arg postgres.CreateOrderFareLineParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) CreateOrderItem(ctx context.Context, arg postgres.CreateOrderItemParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) CreateOrderStop(ctx context.Context, arg postgres.CreateOrderStopParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) CreatePromoCode(ctx context.Context, arg postgres.CreatePromoCodeParams) (postgres.CreatePromoCodeRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(postgres.CreatePromoCodeRow), args.Error(1)
+}
+
+func (m *MockQuerier) CreatePromoRedemption(ctx context.Context, arg postgres.CreatePromoRedemptionParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) CreateRecurringOrder(ctx context.Context, arg postgres.CreateRecurringOrderParams) (postgres.CreateRecurringOrderRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(postgres.CreateRecurringOrderRow), args.Error(1)
+}
+
+func (m *MockQuerier) CreateTrackingLink(ctx context.Context, arg postgres.CreateTrackingLinkParams) (postgres.CreateTrackingLinkRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(postgres.CreateTrackingLinkRow), args.Error(1)
+}
+
+func (m *MockQuerier) DeletePricingZone(ctx context.Context, arg postgres.DeletePricingZoneParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) DeletePromoRedemptionByOrder(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockQuerier) DeleteRecurringOrderSkip(ctx context.Context, arg postgres.DeleteRecurringOrderSkipParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) DeleteServiceArea(ctx context.Context, arg postgres.DeleteServiceAreaParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) EnsureDriverLocationsPartition(ctx context.Context, month pgtype.Date) error {
+	args := m.Called(ctx, month)
+	return args.Error(0)
+}
+
 func (m *MockQuerier) FindNearestDrivers(ctx context.Context, arg postgres.FindNearestDriversParams) ([]postgres.FindNearestDriversRow, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).([]postgres.FindNearestDriversRow), args.Error(1)
 }
 
+func (m *MockQuerier) FindPricingZone(ctx context.Context, arg postgres.FindPricingZoneParams) (postgres.FindPricingZoneRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(postgres.FindPricingZoneRow), args.Error(1)
+}
+
+func (m *MockQuerier) GetDeliveryProof(ctx context.Context, orderID uuid.UUID) (postgres.GetDeliveryProofRow, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(postgres.GetDeliveryProofRow), args.Error(1)
+}
+
+func (m *MockQuerier) GetDeliveryProofCheck(ctx context.Context, id uuid.UUID) (postgres.GetDeliveryProofCheckRow, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(postgres.GetDeliveryProofCheckRow), args.Error(1)
+}
+
 func (m *MockQuerier) GetDriver(ctx context.Context, id uuid.UUID) (postgres.GetDriverRow, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(postgres.GetDriverRow), args.Error(1)
@@ -83,11 +799,176 @@ func (m *MockQuerier) GetDriverByEmail(ctx context.Context, email string) (postg
 	return args.Get(0).(postgres.GetDriverByEmailRow), args.Error(1)
 }
 
+func (m *MockQuerier) GetDriverLoad(ctx context.Context, id uuid.UUID) (postgres.GetDriverLoadRow, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(postgres.GetDriverLoadRow), args.Error(1)
+}
+
+func (m *MockQuerier) GetFleetPricingPolicy(ctx context.Context, id uuid.UUID) (postgres.GetFleetPricingPolicyRow, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(postgres.GetFleetPricingPolicyRow), args.Error(1)
+}
+
+func (m *MockQuerier) GetFleetProofPolicy(ctx context.Context, id uuid.UUID) (postgres.GetFleetProofPolicyRow, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(postgres.GetFleetProofPolicyRow), args.Error(1)
+}
+
+func (m *MockQuerier) GetOrder(ctx context.Context, id uuid.UUID) (postgres.GetOrderRow, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(postgres.GetOrderRow), args.Error(1)
+}
+
+func (m *MockQuerier) GetOrderDeliveryPIN(ctx context.Context, id uuid.UUID) (postgres.GetOrderDeliveryPINRow, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(postgres.GetOrderDeliveryPINRow), args.Error(1)
+}
+
+func (m *MockQuerier) GetOrderTrackingTokenHash(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockQuerier) GetPromoCodeByCode(ctx context.Context, code string) (postgres.GetPromoCodeByCodeRow, error) {
+	args := m.Called(ctx, code)
+	return args.Get(0).(postgres.GetPromoCodeByCodeRow), args.Error(1)
+}
+
+func (m *MockQuerier) GetRecurringOrder(ctx context.Context, id uuid.UUID) (postgres.GetRecurringOrderRow, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(postgres.GetRecurringOrderRow), args.Error(1)
+}
+
+func (m *MockQuerier) GetTariffScheduleByFleet(ctx context.Context, fleetID uuid.UUID) (postgres.GetTariffScheduleByFleetRow, error) {
+	args := m.Called(ctx, fleetID)
+	return args.Get(0).(postgres.GetTariffScheduleByFleetRow), args.Error(1)
+}
+
+func (m *MockQuerier) GetTrackingLinkByTokenHash(ctx context.Context, tokenHash []byte) (postgres.GetTrackingLinkByTokenHashRow, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(postgres.GetTrackingLinkByTokenHashRow), args.Error(1)
+}
+
+func (m *MockQuerier) GetZoneFare(ctx context.Context, arg postgres.GetZoneFareParams) (int32, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockQuerier) IncrementDeliveryPINAttempts(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) InsertDriverLocations(ctx context.Context, arg postgres.InsertDriverLocationsParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) ListActiveOrdersByDriver(ctx context.Context, driverID pgtype.UUID) ([]postgres.ListActiveOrdersByDriverRow, error) {
+	args := m.Called(ctx, driverID)
+	return args.Get(0).([]postgres.ListActiveOrdersByDriverRow), args.Error(1)
+}
+
+func (m *MockQuerier) ListActiveOrdersByFleet(ctx context.Context, fleetID uuid.UUID) ([]postgres.ListActiveOrdersByFleetRow, error) {
+	args := m.Called(ctx, fleetID)
+	return args.Get(0).([]postgres.ListActiveOrdersByFleetRow), args.Error(1)
+}
+
+func (m *MockQuerier) ListDeliveryAttempts(ctx context.Context, orderID uuid.UUID) ([]postgres.ListDeliveryAttemptsRow, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]postgres.ListDeliveryAttemptsRow), args.Error(1)
+}
+
+func (m *MockQuerier) ListDriverLocations(ctx context.Context, arg postgres.ListDriverLocationsParams) ([]postgres.ListDriverLocationsRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]postgres.ListDriverLocationsRow), args.Error(1)
+}
+
+func (m *MockQuerier) ListDriverPendingStops(ctx context.Context, driverID pgtype.UUID) ([]postgres.ListDriverPendingStopsRow, error) {
+	args := m.Called(ctx, driverID)
+	return args.Get(0).([]postgres.ListDriverPendingStopsRow), args.Error(1)
+}
+
 func (m *MockQuerier) ListDriversByFleet(ctx context.Context, fleetID uuid.UUID) ([]postgres.ListDriversByFleetRow, error) {
 	args := m.Called(ctx, fleetID)
 	return args.Get(0).([]postgres.ListDriversByFleetRow), args.Error(1)
 }
 
+func (m *MockQuerier) ListDueRecurringOrders(ctx context.Context, arg postgres.ListDueRecurringOrdersParams) ([]postgres.ListDueRecurringOrdersRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]postgres.ListDueRecurringOrdersRow), args.Error(1)
+}
+
+func (m *MockQuerier) ListDueScheduledOrders(ctx context.Context, arg postgres.ListDueScheduledOrdersParams) ([]uuid.UUID, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockQuerier) ListOrderFareLines(ctx context.Context, orderID uuid.UUID) ([]postgres.ListOrderFareLinesRow, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]postgres.ListOrderFareLinesRow), args.Error(1)
+}
+
+func (m *MockQuerier) ListOrderItems(ctx context.Context, orderID uuid.UUID) ([]postgres.ListOrderItemsRow, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]postgres.ListOrderItemsRow), args.Error(1)
+}
+
+func (m *MockQuerier) ListOrderStops(ctx context.Context, orderID uuid.UUID) ([]postgres.ListOrderStopsRow, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]postgres.ListOrderStopsRow), args.Error(1)
+}
+
+func (m *MockQuerier) ListPendingOrders(ctx context.Context, arg postgres.ListPendingOrdersParams) ([]uuid.UUID, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockQuerier) ListPricingZones(ctx context.Context, fleetID uuid.UUID) ([]postgres.ListPricingZonesRow, error) {
+	args := m.Called(ctx, fleetID)
+	return args.Get(0).([]postgres.ListPricingZonesRow), args.Error(1)
+}
+
+func (m *MockQuerier) ListRecurringOccurrences(ctx context.Context, arg postgres.ListRecurringOccurrencesParams) ([]pgtype.Timestamptz, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]pgtype.Timestamptz), args.Error(1)
+}
+
+func (m *MockQuerier) ListRecurringOrders(ctx context.Context, fleetID uuid.UUID) ([]postgres.ListRecurringOrdersRow, error) {
+	args := m.Called(ctx, fleetID)
+	return args.Get(0).([]postgres.ListRecurringOrdersRow), args.Error(1)
+}
+
+func (m *MockQuerier) ListSLABreachesByFleet(ctx context.Context, arg postgres.ListSLABreachesByFleetParams) ([]postgres.ListSLABreachesByFleetRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]postgres.ListSLABreachesByFleetRow), args.Error(1)
+}
+
+func (m *MockQuerier) ListServiceAreas(ctx context.Context, fleetID uuid.UUID) ([]postgres.ListServiceAreasRow, error) {
+	args := m.Called(ctx, fleetID)
+	return args.Get(0).([]postgres.ListServiceAreasRow), args.Error(1)
+}
+
+func (m *MockQuerier) ListTariffHolidays(ctx context.Context, scheduleID uuid.UUID) ([]postgres.ListTariffHolidaysRow, error) {
+	args := m.Called(ctx, scheduleID)
+	return args.Get(0).([]postgres.ListTariffHolidaysRow), args.Error(1)
+}
+
+func (m *MockQuerier) ListTariffRules(ctx context.Context, scheduleID uuid.UUID) ([]postgres.ListTariffRulesRow, error) {
+	args := m.Called(ctx, scheduleID)
+	return args.Get(0).([]postgres.ListTariffRulesRow), args.Error(1)
+}
+
+func (m *MockQuerier) LockDriverStatus(ctx context.Context, id uuid.UUID) (postgres.DriverStatus, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(postgres.DriverStatus), args.Error(1)
+}
+
+func (m *MockQuerier) MarkLateDelivery(ctx context.Context, id uuid.UUID) (pgtype.Timestamptz, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(pgtype.Timestamptz), args.Error(1)
+}
+
 func (m *MockQuerier) MarkOrderArrived(ctx context.Context, arg postgres.MarkOrderArrivedParams) (int64, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(int64), args.Error(1)
@@ -98,21 +979,146 @@ func (m *MockQuerier) MarkOrderDelivered(ctx context.Context, arg postgres.MarkO
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockQuerier) MarkOrderDeliveryFailed(ctx context.Context, arg postgres.MarkOrderDeliveryFailedParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockQuerier) MarkOrderPickedUp(ctx context.Context, arg postgres.MarkOrderPickedUpParams) (int64, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockQuerier) MarkOrderReturned(ctx context.Context, arg postgres.MarkOrderReturnedParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) MarkOrderStopArrived(ctx context.Context, arg postgres.MarkOrderStopArrivedParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) MarkOrderStopCompleted(ctx context.Context, arg postgres.MarkOrderStopCompletedParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) MarkOrderStopFailed(ctx context.Context, arg postgres.MarkOrderStopFailedParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) MarkSLABreaches(ctx context.Context) ([]postgres.MarkSLABreachesRow, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]postgres.MarkSLABreachesRow), args.Error(1)
+}
+
+func (m *MockQuerier) RecordDispatchAttempt(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func (m *MockQuerier) RejectOrderAssignment(ctx context.Context, arg postgres.RejectOrderAssignmentParams) error {
 	args := m.Called(ctx, arg)
 	return args.Error(0)
 }
 
+func (m *MockQuerier) ReleaseScheduledOrder(ctx context.Context, id uuid.UUID) (int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) RescheduleOrder(ctx context.Context, arg postgres.RescheduleOrderParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) ResetOrderStop(ctx context.Context, arg postgres.ResetOrderStopParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) RevokeTrackingLink(ctx context.Context, arg postgres.RevokeTrackingLinkParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockQuerier) SetDriverStatus(ctx context.Context, arg postgres.SetDriverStatusParams) error {
 	args := m.Called(ctx, arg)
 	return args.Error(0)
 }
 
+func (m *MockQuerier) SetRecurringOrderMaterializedUntil(ctx context.Context, arg postgres.SetRecurringOrderMaterializedUntilParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) SetRecurringOrderPaused(ctx context.Context, arg postgres.SetRecurringOrderPausedParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) SumDriverEarnings(ctx context.Context, driverID uuid.UUID) ([]postgres.SumDriverEarningsRow, error) {
+	args := m.Called(ctx, driverID)
+	return args.Get(0).([]postgres.SumDriverEarningsRow), args.Error(1)
+}
+
+func (m *MockQuerier) UnclaimPromoRedemption(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) UpdateDriverCapacity(ctx context.Context, arg postgres.UpdateDriverCapacityParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) UpdateDriverCurrentLocations(ctx context.Context, arg postgres.UpdateDriverCurrentLocationsParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) UpdateFleetPricingPolicy(ctx context.Context, arg postgres.UpdateFleetPricingPolicyParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) UpdateFleetProofPolicy(ctx context.Context, arg postgres.UpdateFleetProofPolicyParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) UpdateOrderETA(ctx context.Context, arg postgres.UpdateOrderETAParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) UpdateOrderStopSequences(ctx context.Context, arg postgres.UpdateOrderStopSequencesParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) UpsertDeliveryProof(ctx context.Context, arg postgres.UpsertDeliveryProofParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) UpsertPricingZone(ctx context.Context, arg postgres.UpsertPricingZoneParams) (postgres.UpsertPricingZoneRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(postgres.UpsertPricingZoneRow), args.Error(1)
+}
+
+func (m *MockQuerier) UpsertServiceArea(ctx context.Context, arg postgres.UpsertServiceAreaParams) (postgres.UpsertServiceAreaRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(postgres.UpsertServiceAreaRow), args.Error(1)
+}
+
+func (m *MockQuerier) UpsertZoneFare(ctx context.Context, arg postgres.UpsertZoneFareParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
 func (m *MockQuerier) ExecTx(ctx context.Context, fn func(postgres.Querier) error) error {
 	args := m.Called(ctx, fn)
 
@@ -129,7 +1135,12 @@ type MockGeoFinder struct {
 	mock.Mock
 }
 
-func (m *MockGeoFinder) FindNearestDrivers(ctx context.Context, lat, lng float64, radiusKm float64) ([]string, error) {
+func (m *MockGeoFinder) FindNearestDrivers(ctx context.Context, lat, lng float64, radiusKm float64) ([]domain.NearbyDriver, error) {
 	args := m.Called(ctx, lat, lng, radiusKm)
-	return args.Get(0).([]string), args.Error(1)
+	return args.Get(0).([]domain.NearbyDriver), args.Error(1)
+}
+
+func (m *MockGeoFinder) GetDriverLocation(ctx context.Context, driverID string) (domain.Location, error) {
+	args := m.Called(ctx, driverID)
+	return args.Get(0).(domain.Location), args.Error(1)
 }
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+// DeliveryFailurePolicy controls failed dropoffs. A dropoff failed for a
+// retryable reason may be attempted again until MaxAttempts attempts have
+// failed; after that, or for any other reason, the driver takes the order
+// back to its pickup and ReturnFeePercent of its price is charged for the
+// return leg.
+type DeliveryFailurePolicy struct {
+	MaxAttempts      int
+	ReturnFeePercent int
+}
+
+var DefaultDeliveryFailurePolicy = DeliveryFailurePolicy{
+	MaxAttempts:      2,
+	ReturnFeePercent: 50,
+}
+
+func (s *DispatchService) SetDeliveryFailurePolicy(policy DeliveryFailurePolicy) {
+	s.failurePolicy = policy
+}
+
+// FailDeliveryInput is why a dropoff could not be completed. Retry asks to
+// attempt the dropoff again later instead of returning the order.
+type FailDeliveryInput struct {
+	Reason domain.FailureReason
+	Notes  string
+	Retry  bool
+}
+
+// FailDeliveryResult is the outcome of a failed dropoff: either it stays
+// pending for another attempt, or the order is returning to sender via
+// ReturnStop.
+type FailDeliveryResult struct {
+	Status     domain.OrderStatus `json:"status"`
+	Attempt    int                `json:"attempt"`
+	Retry      bool               `json:"retry"`
+	ReturnStop *domain.Stop       `json:"return_stop,omitempty"`
+}
+
+// FailDelivery records that the driver could not complete the order's next
+// stop, which must be a dropoff. The attempt is kept with its reason. If a
+// retry is asked for and allowed the stop stays pending; otherwise it fails,
+// the order becomes delivery_failed and a return stop at its pickup is added
+// to the end of the route. The driver stays busy until they complete it.
+func (s *DispatchService) FailDelivery(ctx context.Context, driverID, orderID uuid.UUID, input FailDeliveryInput) (*FailDeliveryResult, error) {
+	if !input.Reason.Valid() {
+		return nil, domain.ErrInvalidFailureReason
+	}
+
+	stops, err := s.listStops(ctx, s.store, orderID)
+	if err != nil {
+		return nil, err
+	}
+	next := domain.NextStop(stops)
+	if next == nil {
+		return nil, domain.ErrInvalidTransition
+	}
+	if next.Kind != domain.StopKindDropoff {
+		return nil, domain.ErrNotAtDropoff
+	}
+
+	driver := pgtype.UUID{Bytes: driverID, Valid: true}
+	position := int32(next.Position)
+	result := &FailDeliveryResult{Status: domain.OrderStatusPickedUp}
+	if err := s.store.ExecTx(ctx, func(q postgres.Querier) error {
+		attempts, err := q.CountDeliveryAttempts(ctx, postgres.CountDeliveryAttemptsParams{OrderID: orderID, Position: position})
+		if err != nil {
+			return err
+		}
+		result.Attempt = int(attempts) + 1
+		result.Retry = input.Retry && input.Reason.Retryable() && result.Attempt < s.failurePolicy.MaxAttempts
+
+		if result.Retry {
+			if err := markOrder(q.ResetOrderStop(ctx, postgres.ResetOrderStopParams{
+				OrderID: orderID, Position: position, DriverID: driver,
+			})); err != nil {
+				return err
+			}
+		} else {
+			if err := markOrder(q.MarkOrderStopFailed(ctx, postgres.MarkOrderStopFailedParams{
+				OrderID: orderID, Position: position, DriverID: driver,
+			})); err != nil {
+				return err
+			}
+			// a multi-stop order may already be returning from an earlier
+			// failed dropoff
+			if _, err := q.MarkOrderDeliveryFailed(ctx, postgres.MarkOrderDeliveryFailedParams{ID: orderID, DriverID: driver}); err != nil {
+				return err
+			}
+			result.Status = domain.OrderStatusDeliveryFailed
+			if last := stops[len(stops)-1]; last.Kind == domain.StopKindReturn {
+				result.ReturnStop = &last
+			} else {
+				result.ReturnStop, err = addReturnStop(ctx, q, orderID, stops)
+				if err != nil {
+					return err
+				}
+				if err := s.chargeReturnFee(ctx, q, orderID); err != nil {
+					return err
+				}
+			}
+		}
+
+		return q.CreateDeliveryAttempt(ctx, postgres.CreateDeliveryAttemptParams{
+			OrderID:  orderID,
+			Position: position,
+			DriverID: driverID,
+			Reason:   postgres.DeliveryFailureReason(input.Reason),
+			Notes:    strings.TrimSpace(input.Notes),
+			Retried:  result.Retry,
+		})
+	}); err != nil {
+		return nil, err
+	}
+	s.forgetActiveOrder(driverID)
+	s.forgetArrivalPrompts(orderID)
+
+	fleetID := s.driverFleet(ctx, driverID)
+	if fleetID != "" {
+		s.hub.PublishOps(websocket.OpsEvent{
+			Event:    websocket.EventOrderDeliveryFailed,
+			FleetID:  fleetID,
+			DriverID: driverID.String(),
+			OrderID:  orderID.String(),
+			Status:   string(result.Status),
+			Location: &next.Location,
+			Data: map[string]any{
+				"position": next.Position,
+				"reason":   input.Reason,
+				"attempt":  result.Attempt,
+				"retry":    result.Retry,
+			},
+		})
+	}
+	if result.ReturnStop != nil {
+		s.publishStatus(fleetID, orderID, result.Status, map[string]any{"stop": result.ReturnStop.WithoutAddress()})
+		s.hub.SendToDriver(driverID.String(), map[string]any{
+			"event":    "RETURN_TO_SENDER",
+			"order_id": orderID,
+			"stop":     result.ReturnStop,
+		})
+	}
+
+	return result, nil
+}
+
+// addReturnStop appends a stop taking the order back to its first pickup
+// and the sender waiting there.
+func addReturnStop(ctx context.Context, q postgres.Querier, orderID uuid.UUID, stops []domain.Stop) (*domain.Stop, error) {
+	stop := &domain.Stop{
+		Position: len(stops),
+		Kind:     domain.StopKindReturn,
+		Location: stops[0].Location,
+		Address:  stops[0].Address,
+		Status:   domain.StopStatusPending,
+	}
+	if err := createStop(ctx, q, orderID, *stop); err != nil {
+		return nil, err
+	}
+	return stop, nil
+}
+
+// chargeReturnFee adds the return fee to the order's price as its own fare
+// line, rounded with the fleet's rounding rule.
+func (s *DispatchService) chargeReturnFee(ctx context.Context, q postgres.Querier, orderID uuid.UUID) error {
+	if s.failurePolicy.ReturnFeePercent <= 0 {
+		return nil
+	}
+
+	order, err := q.GetOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	policyRow, err := q.GetFleetPricingPolicy(ctx, order.FleetID)
+	if err != nil {
+		return err
+	}
+	policy := postgres.PricingPolicyFromRow(policyRow)
+	fee := domain.NewMoney(int64(order.AmountCents), order.Currency).
+		Percent(s.failurePolicy.ReturnFeePercent, policy.Rounding)
+	if fee.Amount <= 0 {
+		return nil
+	}
+
+	lines, err := q.ListOrderFareLines(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if err := q.CreateOrderFareLine(ctx, postgres.CreateOrderFareLineParams{
+		OrderID:     orderID,
+		Position:    int32(len(lines)),
+		Kind:        string(domain.FareLineReturn),
+		Description: "Return to sender",
+		AmountCents: int32(fee.Amount),
+	}); err != nil {
+		return err
+	}
+	return q.AddOrderCharge(ctx, postgres.AddOrderChargeParams{ID: orderID, AmountCents: int32(fee.Amount)})
+}
+
+// DeliveryAttempts lists the order's failed dropoff attempts, oldest first.
+func (s *DispatchService) DeliveryAttempts(ctx context.Context, orderID uuid.UUID) ([]domain.DeliveryAttempt, error) {
+	if _, err := s.store.GetOrder(ctx, orderID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := s.store.ListDeliveryAttempts(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	attempts := make([]domain.DeliveryAttempt, len(rows))
+	for i, row := range rows {
+		attempts[i] = domain.DeliveryAttempt{
+			Position:  int(row.Position),
+			DriverID:  row.DriverID.String(),
+			Reason:    domain.FailureReason(row.Reason),
+			Notes:     row.Notes,
+			Retried:   row.Retried,
+			CreatedAt: row.CreatedAt,
+		}
+	}
+	return attempts, nil
+}
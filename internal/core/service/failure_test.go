@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+func TestDispatchService_FailDelivery(t *testing.T) {
+	driverID := uuid.New()
+	orderID := uuid.New()
+	fleetID := uuid.New()
+
+	t.Run("Not At Dropoff", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		mockRepo.On("ListOrderStops", mock.Anything, orderID).Return(stopRows(
+			postgres.OrderStopStatusArrived, postgres.OrderStopStatusPending,
+		), nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		_, err := svc.FailDelivery(context.Background(), driverID, orderID, FailDeliveryInput{Reason: domain.FailureRefused})
+
+		assert.ErrorIs(t, err, domain.ErrNotAtDropoff)
+		mockRepo.AssertNotCalled(t, "ExecTx", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		mockRepo.On("ListOrderStops", mock.Anything, orderID).Return(stopRows(
+			postgres.OrderStopStatusCompleted, postgres.OrderStopStatusArrived,
+		), nil)
+		mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("CountDeliveryAttempts", mock.Anything, postgres.CountDeliveryAttemptsParams{OrderID: orderID, Position: 1}).Return(int64(0), nil)
+		mockRepo.On("ResetOrderStop", mock.Anything, mock.MatchedBy(func(arg postgres.ResetOrderStopParams) bool {
+			return arg.OrderID == orderID && arg.Position == 1
+		})).Return(int64(1), nil)
+		mockRepo.On("CreateDeliveryAttempt", mock.Anything, postgres.CreateDeliveryAttemptParams{
+			OrderID: orderID, Position: 1, DriverID: driverID,
+			Reason: postgres.DeliveryFailureReasonRecipientAbsent, Notes: "nobody home", Retried: true,
+		}).Return(nil)
+		mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: fleetID}, nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		result, err := svc.FailDelivery(context.Background(), driverID, orderID, FailDeliveryInput{
+			Reason: domain.FailureRecipientAbsent, Notes: " nobody home ", Retry: true,
+		})
+
+		require.NoError(t, err)
+		assert.True(t, result.Retry)
+		assert.Equal(t, 1, result.Attempt)
+		assert.Equal(t, domain.OrderStatusPickedUp, result.Status)
+		assert.Nil(t, result.ReturnStop)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "MarkOrderDeliveryFailed", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Retries Exhausted Returns To Sender", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		stops := stopRows(postgres.OrderStopStatusCompleted, postgres.OrderStopStatusArrived)
+		mockRepo.On("ListOrderStops", mock.Anything, orderID).Return(stops, nil)
+		mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("CountDeliveryAttempts", mock.Anything, mock.Anything).Return(int64(1), nil)
+		mockRepo.On("MarkOrderStopFailed", mock.Anything, mock.Anything).Return(int64(1), nil)
+		mockRepo.On("MarkOrderDeliveryFailed", mock.Anything, mock.Anything).Return(int64(1), nil)
+		mockRepo.On("CreateOrderStop", mock.Anything, postgres.CreateOrderStopParams{
+			OrderID: orderID, Position: 2, Kind: postgres.OrderStopKindReturn, Lat: stops[0].Lat, Lng: stops[0].Lng,
+		}).Return(nil)
+		mockRepo.On("GetOrder", mock.Anything, orderID).Return(postgres.GetOrderRow{
+			ID: orderID, FleetID: fleetID, AmountCents: 1250, Currency: domain.DefaultCurrency,
+		}, nil)
+		mockRepo.On("GetFleetPricingPolicy", mock.Anything, fleetID).Return(postgres.GetFleetPricingPolicyRow{
+			Currency: domain.DefaultCurrency, DriverSharePercent: 80, RoundingIncrement: 1,
+		}, nil)
+		mockRepo.On("ListOrderFareLines", mock.Anything, orderID).Return([]postgres.ListOrderFareLinesRow{{}, {}}, nil)
+		mockRepo.On("CreateOrderFareLine", mock.Anything, postgres.CreateOrderFareLineParams{
+			OrderID: orderID, Position: 2, Kind: "RETURN_FEE", Description: "Return to sender", AmountCents: 625,
+		}).Return(nil)
+		mockRepo.On("AddOrderCharge", mock.Anything, postgres.AddOrderChargeParams{ID: orderID, AmountCents: 625}).Return(nil)
+		mockRepo.On("CreateDeliveryAttempt", mock.Anything, mock.MatchedBy(func(arg postgres.CreateDeliveryAttemptParams) bool {
+			return !arg.Retried && arg.Reason == postgres.DeliveryFailureReasonRecipientAbsent
+		})).Return(nil)
+		mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: fleetID}, nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		result, err := svc.FailDelivery(context.Background(), driverID, orderID, FailDeliveryInput{
+			Reason: domain.FailureRecipientAbsent, Retry: true,
+		})
+
+		require.NoError(t, err)
+		assert.False(t, result.Retry)
+		assert.Equal(t, 2, result.Attempt)
+		assert.Equal(t, domain.OrderStatusDeliveryFailed, result.Status)
+		require.NotNil(t, result.ReturnStop)
+		assert.Equal(t, domain.StopKindReturn, result.ReturnStop.Kind)
+		assert.Equal(t, 2, result.ReturnStop.Position)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "ResetOrderStop", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Already Returning", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		stops := stopRows(postgres.OrderStopStatusCompleted, postgres.OrderStopStatusFailed,
+			postgres.OrderStopStatusArrived, postgres.OrderStopStatusPending)
+		stops[3].Kind = postgres.OrderStopKindReturn
+		mockRepo.On("ListOrderStops", mock.Anything, orderID).Return(stops, nil)
+		mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("CountDeliveryAttempts", mock.Anything, mock.Anything).Return(int64(0), nil)
+		mockRepo.On("MarkOrderStopFailed", mock.Anything, mock.MatchedBy(func(arg postgres.MarkOrderStopFailedParams) bool {
+			return arg.Position == 2
+		})).Return(int64(1), nil)
+		mockRepo.On("MarkOrderDeliveryFailed", mock.Anything, mock.Anything).Return(int64(0), nil)
+		mockRepo.On("CreateDeliveryAttempt", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: fleetID}, nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		result, err := svc.FailDelivery(context.Background(), driverID, orderID, FailDeliveryInput{Reason: domain.FailureRefused})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.ReturnStop.Position)
+		mockRepo.AssertNotCalled(t, "CreateOrderStop", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "AddOrderCharge", mock.Anything, mock.Anything)
+	})
+}
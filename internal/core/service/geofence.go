@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/pkg/geo"
+)
+
+// ArrivalCheck is how strictly a driver's manual arrival is checked against
+// their live position.
+type ArrivalCheck string
+
+const (
+	// ArrivalCheckOff accepts every manual arrival.
+	ArrivalCheckOff ArrivalCheck = "off"
+	// ArrivalCheckLenient rejects arrivals from drivers known to be far
+	// away, and accepts them when the position is unknown.
+	ArrivalCheckLenient ArrivalCheck = "lenient"
+	// ArrivalCheckStrict also rejects arrivals when the position is unknown.
+	ArrivalCheckStrict ArrivalCheck = "strict"
+)
+
+func (c ArrivalCheck) Valid() bool {
+	switch c {
+	case ArrivalCheckOff, ArrivalCheckLenient, ArrivalCheckStrict:
+		return true
+	default:
+		return false
+	}
+}
+
+// GeofencePolicy controls arrival detection. A driver entering ArrivalRadiusM
+// of a pickup is moved to arrived when AutoArrive is set, and prompted to
+// confirm otherwise; entering it around any other stop always prompts. Manual
+// arrivals farther than ManualArrivalMaxM from the stop are checked as
+// ManualArrivalCheck says.
+type GeofencePolicy struct {
+	ArrivalRadiusM     float64
+	AutoArrive         bool
+	ManualArrivalMaxM  float64
+	ManualArrivalCheck ArrivalCheck
+}
+
+var DefaultGeofencePolicy = GeofencePolicy{
+	ArrivalRadiusM:     100,
+	AutoArrive:         true,
+	ManualArrivalMaxM:  500,
+	ManualArrivalCheck: ArrivalCheckLenient,
+}
+
+func (s *DispatchService) SetGeofencePolicy(policy GeofencePolicy) {
+	s.geofencePolicy = policy
+}
+
+// detectArrival handles a driver's position against the geofence of the
+// order's next stop on their route. Prompts are sent once per visit: leaving
+// twice the radius re-arms them.
+func (s *DispatchService) detectArrival(ctx context.Context, driverID, orderID uuid.UUID, route []routeStop, loc domain.Location) {
+	radius := s.geofencePolicy.ArrivalRadiusM
+	if radius <= 0 {
+		return
+	}
+
+	next := nextRouteStop(route, orderID)
+	if next == nil || next.arrived {
+		return
+	}
+
+	key := fmt.Sprintf("%s:%d", orderID, next.Position)
+	distance := geo.CalculateDistance(loc.Lat, loc.Lng, next.Location.Lat, next.Location.Lng)
+	if distance > radius {
+		if distance > 2*radius {
+			s.arrivalPrompts.Delete(key)
+		}
+		return
+	}
+
+	if next.Kind == domain.StopKindPickup && s.geofencePolicy.AutoArrive {
+		if err := s.advanceStop(ctx, driverID, orderID, atPosition(next.Position), false, false); err != nil && !errors.Is(err, domain.ErrInvalidTransition) {
+			log.Printf("failed to auto-arrive driver %s for order %s: %v", driverID, orderID, err)
+		}
+		return
+	}
+
+	if _, prompted := s.arrivalPrompts.LoadOrStore(key, true); prompted {
+		return
+	}
+	s.hub.SendToDriver(driverID.String(), map[string]any{
+		"event":      "ARRIVAL_PROMPT",
+		"order_id":   orderID,
+		"stop":       next.Kind,
+		"position":   next.Position,
+		"distance_m": int(distance),
+	})
+}
+
+// forgetArrivalPrompts drops the prompts sent for the order's stops once the
+// driver has moved on from them or no longer carries it.
+func (s *DispatchService) forgetArrivalPrompts(orderID uuid.UUID) {
+	prefix := orderID.String() + ":"
+	s.arrivalPrompts.Range(func(key, _ any) bool {
+		if strings.HasPrefix(key.(string), prefix) {
+			s.arrivalPrompts.Delete(key)
+		}
+		return true
+	})
+}
+
+// checkManualArrival enforces the geofence policy on a driver reporting
+// arrival at a stop.
+func (s *DispatchService) checkManualArrival(ctx context.Context, driverID uuid.UUID, stop domain.Location) error {
+	check := s.geofencePolicy.ManualArrivalCheck
+	if check == ArrivalCheckOff || check == "" {
+		return nil
+	}
+
+	loc, err := s.geo.GetDriverLocation(ctx, driverID.String())
+	if err != nil {
+		if errors.Is(err, domain.ErrDriverLocationUnknown) {
+			if check == ArrivalCheckStrict {
+				return fmt.Errorf("%w: position unknown", domain.ErrTooFarFromStop)
+			}
+			return nil
+		}
+		return err
+	}
+
+	distance := geo.CalculateDistance(loc.Lat, loc.Lng, stop.Lat, stop.Lng)
+	if distance > s.geofencePolicy.ManualArrivalMaxM {
+		return fmt.Errorf("%w: %dm away", domain.ErrTooFarFromStop, int(distance))
+	}
+
+	return nil
+}
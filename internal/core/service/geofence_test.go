@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+func TestDispatchService_DetectArrival(t *testing.T) {
+	driverID := uuid.New()
+	orderID := uuid.New()
+	pickup := domain.Location{Lat: 10.7769, Lng: 106.7009}
+	dropoff := domain.Location{Lat: 10.8000, Lng: 106.7200}
+	route := []routeStop{
+		{OrderID: orderID, Position: 0, Kind: domain.StopKindPickup, Location: pickup},
+		{OrderID: orderID, Position: 1, Kind: domain.StopKindDropoff, Location: dropoff},
+	}
+	atPickup := domain.Location{Lat: 10.7770, Lng: 106.7010}
+	farAway := domain.Location{Lat: 10.7900, Lng: 106.7009}
+
+	t.Run("Auto Arrive", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		mockRepo.On("ListOrderStops", mock.Anything, orderID).Return([]postgres.ListOrderStopsRow{
+			{Position: 0, Kind: postgres.OrderStopKindPickup, Lat: pickup.Lat, Lng: pickup.Lng, Status: postgres.OrderStopStatusPending},
+			{Position: 1, Kind: postgres.OrderStopKindDropoff, Lat: dropoff.Lat, Lng: dropoff.Lng, Status: postgres.OrderStopStatusPending},
+		}, nil)
+		mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("MarkOrderStopArrived", mock.Anything, postgres.MarkOrderStopArrivedParams{
+			OrderID:  orderID,
+			Position: 0,
+			DriverID: pgtype.UUID{Bytes: driverID, Valid: true},
+		}).Return(int64(1), nil)
+		mockRepo.On("MarkOrderArrived", mock.Anything, postgres.MarkOrderArrivedParams{
+			ID:       orderID,
+			DriverID: pgtype.UUID{Bytes: driverID, Valid: true},
+		}).Return(int64(1), nil)
+		mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: uuid.New()}, nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		svc.detectArrival(context.Background(), driverID, orderID, route, farAway)
+		mockRepo.AssertNotCalled(t, "MarkOrderArrived", mock.Anything, mock.Anything)
+
+		svc.detectArrival(context.Background(), driverID, orderID, route, atPickup)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Prompt Once Per Visit", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		policy := DefaultGeofencePolicy
+		policy.AutoArrive = false
+		svc.SetGeofencePolicy(policy)
+
+		key := orderID.String() + ":0"
+		svc.detectArrival(context.Background(), driverID, orderID, route, atPickup)
+		_, prompted := svc.arrivalPrompts.Load(key)
+		assert.True(t, prompted)
+
+		svc.detectArrival(context.Background(), driverID, orderID, route, farAway)
+		_, prompted = svc.arrivalPrompts.Load(key)
+		assert.False(t, prompted, "leaving the geofence re-arms the prompt")
+		mockRepo.AssertNotCalled(t, "ExecTx", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Dropoff Prompts", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+
+		svc.detectArrival(context.Background(), driverID, orderID, route[1:], domain.Location{Lat: 10.8001, Lng: 106.7200})
+
+		_, prompted := svc.arrivalPrompts.Load(orderID.String() + ":1")
+		assert.True(t, prompted)
+		mockRepo.AssertNotCalled(t, "ExecTx", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Next Stop Of Order", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+
+		// a stacked order's stop comes first on the route, and the order's
+		// middle stop is nearer than its last
+		middle := domain.Location{Lat: 10.7900, Lng: 106.7100}
+		stacked := []routeStop{
+			{OrderID: uuid.New(), Position: 1, Kind: domain.StopKindDropoff, Location: farAway},
+			{OrderID: orderID, Position: 2, Kind: domain.StopKindDropoff, Location: dropoff},
+			{OrderID: orderID, Position: 1, Kind: domain.StopKindDropoff, Location: middle},
+		}
+		svc.detectArrival(context.Background(), driverID, orderID, stacked, middle)
+
+		_, prompted := svc.arrivalPrompts.Load(orderID.String() + ":1")
+		assert.True(t, prompted)
+		_, prompted = svc.arrivalPrompts.Load(orderID.String() + ":2")
+		assert.False(t, prompted)
+	})
+
+	t.Run("Already Arrived", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+
+		arrived := []routeStop{route[0], route[1]}
+		arrived[0].arrived = true
+		svc.detectArrival(context.Background(), driverID, orderID, arrived, atPickup)
+
+		mockRepo.AssertNotCalled(t, "ListOrderStops", mock.Anything, mock.Anything)
+		_, prompted := svc.arrivalPrompts.Load(orderID.String() + ":0")
+		assert.False(t, prompted)
+	})
+}
+
+func TestDispatchService_CheckManualArrival(t *testing.T) {
+	pickup := domain.Location{Lat: 10.7769, Lng: 106.7009}
+	near := uuid.New()
+	far := uuid.New()
+	unknown := uuid.New()
+
+	mockGeo := new(MockGeoFinder)
+	mockGeo.On("GetDriverLocation", mock.Anything, near.String()).Return(domain.Location{Lat: 10.7780, Lng: 106.7009}, nil)
+	mockGeo.On("GetDriverLocation", mock.Anything, far.String()).Return(domain.Location{Lat: 10.8769, Lng: 106.7009}, nil)
+	mockGeo.On("GetDriverLocation", mock.Anything, unknown.String()).Return(domain.Location{}, domain.ErrDriverLocationUnknown)
+
+	tests := []struct {
+		name     string
+		check    ArrivalCheck
+		driverID uuid.UUID
+		wantErr  bool
+	}{
+		{name: "Lenient Near", check: ArrivalCheckLenient, driverID: near},
+		{name: "Lenient Far", check: ArrivalCheckLenient, driverID: far, wantErr: true},
+		{name: "Lenient Unknown", check: ArrivalCheckLenient, driverID: unknown},
+		{name: "Strict Unknown", check: ArrivalCheckStrict, driverID: unknown, wantErr: true},
+		{name: "Off Far", check: ArrivalCheckOff, driverID: far},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewDispatchService(new(MockQuerier), mockGeo, &websocket.Hub{})
+			policy := DefaultGeofencePolicy
+			policy.ManualArrivalCheck = tt.check
+			svc.SetGeofencePolicy(policy)
+
+			err := svc.checkManualArrival(context.Background(), tt.driverID, pickup)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain.ErrTooFarFromStop)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestDispatchService_ForgetArrivalPrompts(t *testing.T) {
+	svc := NewDispatchService(new(MockQuerier), new(MockGeoFinder), &websocket.Hub{})
+	orderID := uuid.New()
+	otherID := uuid.New()
+	svc.arrivalPrompts.Store(orderID.String()+":0", true)
+	svc.arrivalPrompts.Store(orderID.String()+":1", true)
+	svc.arrivalPrompts.Store(otherID.String()+":0", true)
+
+	svc.forgetArrivalPrompts(orderID)
+
+	var left []any
+	svc.arrivalPrompts.Range(func(key, _ any) bool {
+		left = append(left, key)
+		return true
+	})
+	assert.Equal(t, []any{otherID.String() + ":0"}, left)
+}
+
+func TestArrivalCheck_Valid(t *testing.T) {
+	for _, check := range []ArrivalCheck{ArrivalCheckOff, ArrivalCheckLenient, ArrivalCheckStrict} {
+		assert.True(t, check.Valid(), check)
+	}
+	for _, check := range []ArrivalCheck{"", "Strict", "on"} {
+		assert.False(t, check.Valid(), check)
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/pkg/geo"
+)
+
+// LocationPolicy bounds the location fixes accepted from drivers. A fix whose
+// accuracy radius is wider than MaxAccuracyM, which is timestamped more than
+// MaxClockSkew ahead of or behind the server, or which implies travelling
+// faster than MaxSpeedKmh from the previous accepted fix is rejected.
+type LocationPolicy struct {
+	MaxSpeedKmh  float64
+	MaxAccuracyM float64
+	MaxClockSkew time.Duration
+}
+
+var DefaultLocationPolicy = LocationPolicy{
+	MaxSpeedKmh:  200,
+	MaxAccuracyM: 200,
+	MaxClockSkew: 30 * time.Second,
+}
+
+func (s *DispatchService) SetLocationPolicy(policy LocationPolicy) {
+	s.locationPolicy = policy
+}
+
+// CheckLocationFix validates a fix reported by a driver against the location
+// policy and the driver's previous accepted fix. Accepted fixes become the
+// new reference; rejected ones are counted as anomalies and reported to the
+// driver's fleet dispatchers.
+func (s *DispatchService) CheckLocationFix(ctx context.Context, driverID uuid.UUID, fix domain.LocationFix) error {
+	now := time.Now()
+
+	s.fixMu.Lock()
+	err := s.validateFix(s.lastFixes[driverID], fix, now)
+	if err == nil {
+		if s.lastFixes == nil {
+			s.lastFixes = make(map[uuid.UUID]domain.LocationFix)
+		}
+		s.lastFixes[driverID] = fix
+		s.fixMu.Unlock()
+		return nil
+	}
+
+	if s.anomalies == nil {
+		s.anomalies = make(map[uuid.UUID]*domain.LocationAnomalies)
+	}
+	anomalies := s.anomalies[driverID]
+	if anomalies == nil {
+		anomalies = &domain.LocationAnomalies{}
+		s.anomalies[driverID] = anomalies
+	}
+	anomalies.Record(err, now)
+	counters := *anomalies
+	s.fixMu.Unlock()
+
+	log.Printf("rejected location of driver %s: %v", driverID, err)
+	if fleetID := s.driverFleet(ctx, driverID); fleetID != "" {
+		s.hub.PublishOps(websocket.OpsEvent{
+			Event:    websocket.EventDriverAnomaly,
+			FleetID:  fleetID,
+			DriverID: driverID.String(),
+			Data:     counters,
+		})
+	}
+
+	return err
+}
+
+func (s *DispatchService) validateFix(last, fix domain.LocationFix, now time.Time) error {
+	policy := s.locationPolicy
+
+	if !fix.Valid() || math.IsNaN(fix.AccuracyM) || fix.AccuracyM < 0 {
+		return domain.ErrLocationOutOfRange
+	}
+	if policy.MaxAccuracyM > 0 && fix.AccuracyM > policy.MaxAccuracyM {
+		return domain.ErrLocationInaccurate
+	}
+	if fix.RecordedAt.Sub(now) > policy.MaxClockSkew {
+		return domain.ErrLocationClockSkew
+	}
+	if now.Sub(fix.RecordedAt) > policy.MaxClockSkew {
+		return domain.ErrLocationStale
+	}
+	if last.RecordedAt.IsZero() {
+		return nil
+	}
+	if !fix.RecordedAt.After(last.RecordedAt) {
+		return domain.ErrLocationOutOfOrder
+	}
+
+	// the fixes may be anywhere within their accuracy radius, so only the
+	// distance that cannot be explained by it counts
+	distance := geo.CalculateDistance(last.Lat, last.Lng, fix.Lat, fix.Lng) - last.AccuracyM - fix.AccuracyM
+	elapsed := fix.RecordedAt.Sub(last.RecordedAt).Hours()
+	if policy.MaxSpeedKmh > 0 && distance > 0 && distance/1000/elapsed > policy.MaxSpeedKmh {
+		return domain.ErrLocationImplausible
+	}
+
+	return nil
+}
+
+// locationAnomalies returns a copy of the driver's anomaly counters, or nil
+// when none of their fixes has been rejected.
+func (s *DispatchService) locationAnomalies(driverID uuid.UUID) *domain.LocationAnomalies {
+	s.fixMu.Lock()
+	defer s.fixMu.Unlock()
+
+	anomalies, ok := s.anomalies[driverID]
+	if !ok {
+		return nil
+	}
+	counters := *anomalies
+	return &counters
+}
+
+// ForgetDrivers drops the location state kept for drivers who went offline:
+// their last accepted fix, anomaly counters and active order. A driver coming
+// back starts afresh.
+func (s *DispatchService) ForgetDrivers(driverIDs []string) {
+	s.fixMu.Lock()
+	defer s.fixMu.Unlock()
+
+	for _, id := range driverIDs {
+		driverID, err := uuid.Parse(id)
+		if err != nil {
+			continue
+		}
+		delete(s.lastFixes, driverID)
+		delete(s.anomalies, driverID)
+		s.forgetActiveOrder(driverID)
+	}
+}
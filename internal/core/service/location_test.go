@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+func TestDispatchService_CheckLocationFix(t *testing.T) {
+	driverID := uuid.New()
+
+	mockRepo := new(MockQuerier)
+	mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: uuid.New()}, nil)
+
+	svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+	policy := DefaultLocationPolicy
+	policy.MaxClockSkew = 10 * time.Minute
+	svc.SetLocationPolicy(policy)
+
+	start := time.Now().Add(-5 * time.Minute)
+	fix := func(lat, lng float64, offset time.Duration, accuracy float64) domain.LocationFix {
+		return domain.LocationFix{
+			Location:   domain.Location{Lat: lat, Lng: lng},
+			RecordedAt: start.Add(offset),
+			AccuracyM:  accuracy,
+		}
+	}
+
+	// applied in order; each accepted fix becomes the reference for the next
+	tests := []struct {
+		name    string
+		fix     domain.LocationFix
+		wantErr error
+	}{
+		{name: "First Fix", fix: fix(10.7769, 106.7009, 0, 10)},
+		{name: "Null Island", fix: fix(0, 0, time.Second, 10), wantErr: domain.ErrLocationOutOfRange},
+		{name: "Out Of Range", fix: fix(91, 106.7009, time.Second, 10), wantErr: domain.ErrLocationOutOfRange},
+		{name: "Inaccurate", fix: fix(10.7769, 106.7009, time.Second, 5000), wantErr: domain.ErrLocationInaccurate},
+		{name: "From The Future", fix: fix(10.7769, 106.7009, 2*time.Hour, 10), wantErr: domain.ErrLocationClockSkew},
+		{name: "Too Old", fix: fix(10.7769, 106.7009, -time.Hour, 10), wantErr: domain.ErrLocationStale},
+		{name: "Driving", fix: fix(10.7800, 106.7009, time.Minute, 10)},
+		{name: "Out Of Order", fix: fix(10.7790, 106.7009, 30*time.Second, 10), wantErr: domain.ErrLocationOutOfOrder},
+		{name: "Teleport", fix: fix(21.0285, 105.8542, 2*time.Minute, 10), wantErr: domain.ErrLocationImplausible},
+		{name: "Jitter Within Accuracy", fix: fix(10.7810, 106.7009, 61*time.Second, 150)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.CheckLocationFix(context.Background(), driverID, tt.fix)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	anomalies := svc.locationAnomalies(driverID)
+	require.NotNil(t, anomalies)
+	assert.Equal(t, 2, anomalies.OutOfRange)
+	assert.Equal(t, 1, anomalies.Inaccurate)
+	assert.Equal(t, 1, anomalies.ClockSkew)
+	assert.Equal(t, 1, anomalies.Stale)
+	assert.Equal(t, 1, anomalies.OutOfOrder)
+	assert.Equal(t, 1, anomalies.ImpossibleSpeed)
+	assert.Equal(t, domain.ErrLocationImplausible.Error(), anomalies.LastReason)
+
+	assert.Nil(t, svc.locationAnomalies(uuid.New()))
+}
+
+func TestDispatchService_ForgetDrivers(t *testing.T) {
+	driverID := uuid.New()
+	otherID := uuid.New()
+
+	mockRepo := new(MockQuerier)
+	mockRepo.On("GetDriver", mock.Anything, mock.Anything).Return(postgres.GetDriverRow{FleetID: uuid.New()}, nil)
+	svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+
+	now := time.Now()
+	for _, id := range []uuid.UUID{driverID, otherID} {
+		require.NoError(t, svc.CheckLocationFix(context.Background(), id, domain.LocationFix{
+			Location: domain.Location{Lat: 10.7769, Lng: 106.7009}, RecordedAt: now,
+		}))
+		require.Error(t, svc.CheckLocationFix(context.Background(), id, domain.LocationFix{
+			Location: domain.Location{Lat: 21.0285, Lng: 105.8542}, RecordedAt: now.Add(time.Second),
+		}))
+	}
+
+	svc.ForgetDrivers([]string{driverID.String(), "not-a-uuid"})
+
+	assert.Nil(t, svc.locationAnomalies(driverID))
+	assert.NotNil(t, svc.locationAnomalies(otherID))
+	// with no reference fix left, the far-away fix is taken as a fresh start
+	assert.NoError(t, svc.CheckLocationFix(context.Background(), driverID, domain.LocationFix{
+		Location: domain.Location{Lat: 21.0285, Lng: 105.8542}, RecordedAt: now.Add(2 * time.Second),
+	}))
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+// OpsSnapshot returns the current state of a fleet for the dispatcher feed:
+// every driver with their live position, if any, and every active order.
+func (s *DispatchService) OpsSnapshot(ctx context.Context, fleetID uuid.UUID) (domain.OpsSnapshot, error) {
+	snap := domain.OpsSnapshot{FleetID: fleetID.String()}
+
+	drivers, err := s.store.ListDriversByFleet(ctx, fleetID)
+	if err != nil {
+		return snap, err
+	}
+	for _, d := range drivers {
+		driver := domain.OpsDriver{
+			ID:     d.ID.String(),
+			Name:   d.Name,
+			Phone:  d.Phone,
+			Status: string(d.Status),
+
+			Anomalies: s.locationAnomalies(d.ID),
+		}
+
+		loc, err := s.geo.GetDriverLocation(ctx, driver.ID)
+		switch {
+		case err == nil:
+			driver.Location = &loc
+		case !errors.Is(err, domain.ErrDriverLocationUnknown):
+			return snap, err
+		}
+
+		snap.Drivers = append(snap.Drivers, driver)
+	}
+
+	orders, err := s.store.ListActiveOrdersByFleet(ctx, fleetID)
+	if err != nil {
+		return snap, err
+	}
+	for _, o := range orders {
+		order := domain.OpsOrder{
+			ID:        o.ID.String(),
+			Status:    domain.OrderStatus(o.Status),
+			Priority:  domain.OrderPriority(o.Priority),
+			Pickup:    domain.Location{Lat: o.PickupLat, Lng: o.PickupLng},
+			Dropoff:   domain.Location{Lat: o.DropoffLat, Lng: o.DropoffLng},
+			LateSince: o.LateSince.Time,
+			CreatedAt: o.CreatedAt,
+		}
+		if o.DriverID.Valid {
+			order.DriverID = uuid.UUID(o.DriverID.Bytes).String()
+		}
+		if o.DeliverBy.Valid {
+			order.SLA = &domain.SLA{
+				DeliverBy:   o.DeliverBy.Time,
+				AtRiskSince: o.SlaAtRiskSince.Time,
+				BreachedAt:  o.SlaBreachedAt.Time,
+			}
+		}
+		if order.Status == domain.OrderStatusPending {
+			snap.Unassigned++
+		}
+
+		snap.Orders = append(snap.Orders, order)
+	}
+
+	return snap, nil
+}
+
+// driverFleet returns the id of the driver's fleet. Drivers do not move
+// between fleets, so lookups are cached for the life of the service.
+func (s *DispatchService) driverFleet(ctx context.Context, driverID uuid.UUID) string {
+	if fleetID, ok := s.driverFleets.Load(driverID); ok {
+		return fleetID.(string)
+	}
+
+	driver, err := s.store.GetDriver(ctx, driverID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("failed to look up fleet of driver %s: %v", driverID, err)
+		}
+		return ""
+	}
+
+	fleetID := driver.FleetID.String()
+	s.driverFleets.Store(driverID, fleetID)
+	return fleetID
+}
+
+func (s *DispatchService) publishDriverStatus(fleetID string, driverID uuid.UUID, status postgres.DriverStatus) {
+	if fleetID == "" {
+		return
+	}
+	s.hub.PublishOps(websocket.OpsEvent{
+		Event:    websocket.EventDriverStatus,
+		FleetID:  fleetID,
+		DriverID: driverID.String(),
+		Status:   string(status),
+	})
+}
+
+func (s *DispatchService) publishUnassigned(fleetID string, orderID uuid.UUID, pickup *domain.Location) {
+	if fleetID == "" {
+		return
+	}
+	s.hub.PublishOps(websocket.OpsEvent{
+		Event:    websocket.EventOrderUnassigned,
+		FleetID:  fleetID,
+		OrderID:  orderID.String(),
+		Status:   string(domain.OrderStatusPending),
+		Location: pickup,
+	})
+}
@@ -0,0 +1,72 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+// HandlingRates are the surcharges for heavy and special cargo, in the minor
+// unit of a currency. Weight over FreeWeightGrams is charged PerKgCents for
+// each started kilogram; fragile and cold chain loads add a flat fee each.
+type HandlingRates struct {
+	FreeWeightGrams int
+	PerKgCents      int
+	FragileCents    int
+	ColdChainCents  int
+}
+
+// DefaultHandlingRates are the handling surcharges by currency.
+var DefaultHandlingRates = map[string]HandlingRates{
+	"USD": {FreeWeightGrams: 5000, PerKgCents: 20, FragileCents: 200, ColdChainCents: 500},
+	"EUR": {FreeWeightGrams: 5000, PerKgCents: 20, FragileCents: 180, ColdChainCents: 450},
+	"SGD": {FreeWeightGrams: 5000, PerKgCents: 25, FragileCents: 250, ColdChainCents: 650},
+	"VND": {FreeWeightGrams: 5000, PerKgCents: 5000, FragileCents: 20000, ColdChainCents: 50000},
+}
+
+// HandlingStrategy adds weight and special handling surcharges in the fare's
+// currency on top of the base strategy's price. Loads with a weight or
+// special handling are rejected in a currency without handling rates; other
+// loads are left to the base price.
+type HandlingStrategy struct {
+	rates map[string]HandlingRates
+	base  domain.PricingStrategy
+}
+
+func NewHandlingStrategy(rates map[string]HandlingRates, base domain.PricingStrategy) *HandlingStrategy {
+	return &HandlingStrategy{
+		rates: rates,
+		base:  base,
+	}
+}
+
+func (s *HandlingStrategy) CalculatePrice(ctx context.Context, input domain.PricingInput) (domain.Fare, error) {
+	fare, err := s.base.CalculatePrice(ctx, input)
+	if err != nil {
+		return domain.Fare{}, err
+	}
+
+	load := input.Load
+	if load.WeightGrams <= 0 && !load.Fragile && !load.ColdChain {
+		return fare, nil
+	}
+
+	rates, ok := s.rates[fare.Currency]
+	if !ok {
+		return domain.Fare{}, fmt.Errorf("%w: %s", domain.ErrNoRates, fare.Currency)
+	}
+
+	if over := load.WeightGrams - rates.FreeWeightGrams; over > 0 && rates.PerKgCents > 0 {
+		kg := (over + 999) / 1000
+		fare.Add(domain.FareLineSurcharge, fmt.Sprintf("Weight surcharge (%d kg over)", kg), kg*rates.PerKgCents)
+	}
+	if load.Fragile && rates.FragileCents > 0 {
+		fare.Add(domain.FareLineSurcharge, "Fragile handling", rates.FragileCents)
+	}
+	if load.ColdChain && rates.ColdChainCents > 0 {
+		fare.Add(domain.FareLineSurcharge, "Cold chain handling", rates.ColdChainCents)
+	}
+
+	return fare, nil
+}
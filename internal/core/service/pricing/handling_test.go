@@ -0,0 +1,99 @@
+package pricing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+func TestHandlingStrategy_CalculatePrice(t *testing.T) {
+	strategy := NewHandlingStrategy(DefaultHandlingRates, NewStandardStrategy())
+
+	tests := []struct {
+		name       string
+		load       domain.Load
+		surcharges []domain.FareLine
+	}{
+		{name: "Light", load: domain.Load{Parcels: 1, WeightGrams: 5000}, surcharges: []domain.FareLine{}},
+		{
+			name: "Heavy",
+			load: domain.Load{Parcels: 1, WeightGrams: 7200},
+			surcharges: []domain.FareLine{
+				{Kind: domain.FareLineSurcharge, Description: "Weight surcharge (3 kg over)", AmountCents: 60},
+			},
+		},
+		{
+			name: "Fragile Cold Chain",
+			load: domain.Load{Parcels: 1, Fragile: true, ColdChain: true},
+			surcharges: []domain.FareLine{
+				{Kind: domain.FareLineSurcharge, Description: "Fragile handling", AmountCents: 200},
+				{Kind: domain.FareLineSurcharge, Description: "Cold chain handling", AmountCents: 500},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fare, err := strategy.CalculatePrice(context.Background(), domain.PricingInput{
+				Vehicle:        domain.VehicleBike,
+				DistanceMeters: 2000,
+				Load:           tt.load,
+			})
+			require.NoError(t, err)
+
+			// base and distance lines come first
+			assert.Equal(t, tt.surcharges, fare.Lines[2:])
+			assert.Equal(t, 600+fare.Subtotal(domain.FareLineSurcharge), fare.TotalCents)
+		})
+	}
+}
+
+func TestHandlingStrategy_CalculatePrice_UsesFareCurrency(t *testing.T) {
+	strategy := NewHandlingStrategy(DefaultHandlingRates, NewStandardStrategy())
+
+	fare, err := strategy.CalculatePrice(context.Background(), domain.PricingInput{
+		Currency:       "VND",
+		Vehicle:        domain.VehicleBike,
+		DistanceMeters: 2000,
+		Load:           domain.Load{Parcels: 1, Fragile: true},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, DefaultHandlingRates["VND"].FragileCents, fare.Subtotal(domain.FareLineSurcharge))
+}
+
+func TestHandlingStrategy_CalculatePrice_RejectsCurrencyWithoutRates(t *testing.T) {
+	strategy := NewHandlingStrategy(map[string]HandlingRates{"USD": DefaultHandlingRates["USD"]}, NewStandardStrategy())
+
+	_, err := strategy.CalculatePrice(context.Background(), domain.PricingInput{
+		Currency:       "VND",
+		Vehicle:        domain.VehicleBike,
+		DistanceMeters: 2000,
+		Load:           domain.Load{Parcels: 1, Fragile: true},
+	})
+
+	assert.ErrorIs(t, err, domain.ErrNoRates)
+}
+
+func TestHandlingStrategy_CalculatePrice_PlainLoadWithoutRates(t *testing.T) {
+	strategy := NewHandlingStrategy(map[string]HandlingRates{"USD": DefaultHandlingRates["USD"]}, NewStandardStrategy())
+
+	fare, err := strategy.CalculatePrice(context.Background(), domain.PricingInput{
+		Currency:       "VND",
+		Vehicle:        domain.VehicleBike,
+		DistanceMeters: 2000,
+		Load:           domain.Load{Parcels: 1},
+	})
+
+	require.NoError(t, err)
+	assert.Zero(t, fare.Subtotal(domain.FareLineSurcharge))
+}
+
+func TestDefaultHandlingRates_CoverStandardCurrencies(t *testing.T) {
+	for currency := range StandardRates {
+		assert.Contains(t, DefaultHandlingRates, currency)
+	}
+}
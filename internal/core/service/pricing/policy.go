@@ -0,0 +1,53 @@
+package pricing
+
+import (
+	"context"
+
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/core/port"
+)
+
+// PolicyStrategy applies the fleet's money settings: it prices in the fleet
+// currency, tops the fare up to the minimum fare and rounds the total. It
+// must wrap every other strategy so rounding is the last adjustment.
+type PolicyStrategy struct {
+	repo port.FleetPolicyRepository
+	base domain.PricingStrategy
+}
+
+func NewPolicyStrategy(repo port.FleetPolicyRepository, base domain.PricingStrategy) *PolicyStrategy {
+	return &PolicyStrategy{
+		repo: repo,
+		base: base,
+	}
+}
+
+func (s *PolicyStrategy) CalculatePrice(ctx context.Context, input domain.PricingInput) (domain.Fare, error) {
+	policy, err := s.repo.GetPricingPolicy(ctx, input.FleetID)
+	if err != nil {
+		return domain.Fare{}, err
+	}
+	if _, err := domain.LookupCurrency(policy.Currency); err != nil {
+		return domain.Fare{}, err
+	}
+
+	input.Currency = policy.Currency
+	fare, err := s.base.CalculatePrice(ctx, input)
+	if err != nil {
+		return domain.Fare{}, err
+	}
+	fare.Currency = policy.Currency
+
+	// the minimum applies to the service price, promos may still discount below it
+	beforeDiscount := fare.TotalCents - fare.Subtotal(domain.FareLineDiscount)
+	if beforeDiscount < policy.MinimumFareCents {
+		fare.Add(domain.FareLineMinimum, "Minimum fare", policy.MinimumFareCents-beforeDiscount)
+	}
+
+	rounded := int(policy.Rounding.Round(int64(fare.TotalCents)))
+	if rounded != fare.TotalCents {
+		fare.Add(domain.FareLineRounding, "Rounding", rounded-fare.TotalCents)
+	}
+
+	return fare, nil
+}
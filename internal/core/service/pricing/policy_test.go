@@ -0,0 +1,117 @@
+package pricing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+type fakePolicyRepo struct {
+	policies map[string]domain.FleetPricingPolicy
+}
+
+func (f *fakePolicyRepo) GetPricingPolicy(ctx context.Context, fleetID string) (domain.FleetPricingPolicy, error) {
+	p, ok := f.policies[fleetID]
+	if !ok {
+		return domain.FleetPricingPolicy{}, domain.ErrFleetNotFound
+	}
+	return p, nil
+}
+
+type fixedFare struct {
+	lines []domain.FareLine
+}
+
+func (f fixedFare) CalculatePrice(ctx context.Context, input domain.PricingInput) (domain.Fare, error) {
+	fare := domain.NewFare(input.Currency)
+	for _, l := range f.lines {
+		fare.Add(l.Kind, l.Description, l.AmountCents)
+	}
+	return fare, nil
+}
+
+func TestPolicyStrategy_CalculatePrice(t *testing.T) {
+	repo := &fakePolicyRepo{policies: map[string]domain.FleetPricingPolicy{
+		"usd": {Currency: "USD", Rounding: domain.RoundingRule{Increment: 5, Mode: domain.RoundingNearest}, MinimumFareCents: 500},
+		"vnd": {Currency: "VND", Rounding: domain.RoundingRule{Increment: 100, Mode: domain.RoundingNearest}, MinimumFareCents: 15000},
+		"xxx": {Currency: "XXX", Rounding: domain.RoundingRule{Increment: 1}},
+	}}
+
+	tests := []struct {
+		name     string
+		fleetID  string
+		lines    []domain.FareLine
+		expected int
+		kinds    []domain.FareLineKind
+		wantErr  error
+	}{
+		{
+			name:     "Rounded To Nearest 5 Cents",
+			fleetID:  "usd",
+			lines:    []domain.FareLine{{Kind: domain.FareLineBase, AmountCents: 500}, {Kind: domain.FareLineDistance, AmountCents: 123}},
+			expected: 625,
+			kinds:    []domain.FareLineKind{domain.FareLineBase, domain.FareLineDistance, domain.FareLineRounding},
+		},
+		{
+			name:     "Already Rounded",
+			fleetID:  "usd",
+			lines:    []domain.FareLine{{Kind: domain.FareLineBase, AmountCents: 600}},
+			expected: 600,
+			kinds:    []domain.FareLineKind{domain.FareLineBase},
+		},
+		{
+			name:     "Minimum Fare",
+			fleetID:  "usd",
+			lines:    []domain.FareLine{{Kind: domain.FareLineBase, AmountCents: 320}},
+			expected: 500,
+			kinds:    []domain.FareLineKind{domain.FareLineBase, domain.FareLineMinimum},
+		},
+		{
+			name:     "Discount May Go Below Minimum",
+			fleetID:  "usd",
+			lines:    []domain.FareLine{{Kind: domain.FareLineBase, AmountCents: 600}, {Kind: domain.FareLineDiscount, AmountCents: -300}},
+			expected: 300,
+			kinds:    []domain.FareLineKind{domain.FareLineBase, domain.FareLineDiscount},
+		},
+		{
+			name:     "Nearest 100 VND",
+			fleetID:  "vnd",
+			lines:    []domain.FareLine{{Kind: domain.FareLineBase, AmountCents: 12000}, {Kind: domain.FareLineDistance, AmountCents: 8350}},
+			expected: 20400,
+			kinds:    []domain.FareLineKind{domain.FareLineBase, domain.FareLineDistance, domain.FareLineRounding},
+		},
+		{
+			name:     "VND Minimum Then Rounding",
+			fleetID:  "vnd",
+			lines:    []domain.FareLine{{Kind: domain.FareLineBase, AmountCents: 9000}},
+			expected: 15000,
+			kinds:    []domain.FareLineKind{domain.FareLineBase, domain.FareLineMinimum},
+		},
+		{name: "Unknown Fleet", fleetID: "nope", wantErr: domain.ErrFleetNotFound},
+		{name: "Unsupported Currency", fleetID: "xxx", wantErr: domain.ErrUnsupportedCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := NewPolicyStrategy(repo, fixedFare{lines: tt.lines})
+
+			got, err := strategy.CalculatePrice(context.Background(), domain.PricingInput{FleetID: tt.fleetID})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got.TotalCents)
+			assert.Equal(t, repo.policies[tt.fleetID].Currency, got.Currency)
+
+			kinds := make([]domain.FareLineKind, len(got.Lines))
+			for i, l := range got.Lines {
+				kinds[i] = l.Kind
+			}
+			assert.Equal(t, tt.kinds, kinds)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package pricing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/core/port"
+)
+
+// PromoStrategy applies the promo code carried by the input as a discount line
+// on top of the wrapped strategy's fare.
+type PromoStrategy struct {
+	repo port.PromoRepository
+	base domain.PricingStrategy
+}
+
+func NewPromoStrategy(repo port.PromoRepository, base domain.PricingStrategy) *PromoStrategy {
+	return &PromoStrategy{
+		repo: repo,
+		base: base,
+	}
+}
+
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (s *PromoStrategy) CalculatePrice(ctx context.Context, input domain.PricingInput) (domain.Fare, error) {
+	fare, err := s.base.CalculatePrice(ctx, input)
+	if err != nil {
+		return domain.Fare{}, err
+	}
+
+	code := NormalizePromoCode(input.PromoCode)
+	if code == "" {
+		return fare, nil
+	}
+
+	promo, err := s.repo.GetPromoByCode(ctx, code)
+	if err != nil {
+		return domain.Fare{}, err
+	}
+
+	discount, err := promo.Discount(input.FleetID, input.CustomerID, fare.Total(), input.Time)
+	if err != nil {
+		return domain.Fare{}, err
+	}
+
+	if promo.MaxPerCustomer > 0 {
+		used, err := s.repo.CountCustomerRedemptions(ctx, promo.ID, input.CustomerID)
+		if err != nil {
+			return domain.Fare{}, err
+		}
+		if used >= promo.MaxPerCustomer {
+			return domain.Fare{}, domain.ErrPromoCustomerLimit
+		}
+	}
+
+	fare.Add(domain.FareLineDiscount, "Promo "+promo.Code, -discount)
+	fare.PromoCode = promo.Code
+
+	return fare, nil
+}
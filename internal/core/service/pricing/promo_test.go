@@ -0,0 +1,99 @@
+package pricing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+type fakePromoRepo struct {
+	promos map[string]*domain.Promo
+	usage  map[string]int
+}
+
+func (f *fakePromoRepo) GetPromoByCode(ctx context.Context, code string) (*domain.Promo, error) {
+	p, ok := f.promos[code]
+	if !ok {
+		return nil, domain.ErrPromoNotFound
+	}
+	return p, nil
+}
+
+func (f *fakePromoRepo) CountCustomerRedemptions(ctx context.Context, promoID, customerID string) (int, error) {
+	return f.usage[promoID+"/"+customerID], nil
+}
+
+func TestPromoStrategy_CalculatePrice(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	strategy := NewPromoStrategy(&fakePromoRepo{
+		promos: map[string]*domain.Promo{
+			"HALF":    {ID: "p1", Code: "HALF", Kind: domain.PromoKindPercentage, Value: 50, StartsAt: now.Add(-time.Hour)},
+			"CAPPED":  {ID: "p2", Code: "CAPPED", Kind: domain.PromoKindPercentage, Value: 50, MaxDiscountCents: 200, StartsAt: now.Add(-time.Hour)},
+			"FIXED":   {ID: "p3", Code: "FIXED", Kind: domain.PromoKindFixed, Value: 300, StartsAt: now.Add(-time.Hour)},
+			"HUGE":    {ID: "p4", Code: "HUGE", Kind: domain.PromoKindFixed, Value: 5000, StartsAt: now.Add(-time.Hour)},
+			"MIN":     {ID: "p5", Code: "MIN", Kind: domain.PromoKindFixed, Value: 100, MinFareCents: 2000, StartsAt: now.Add(-time.Hour)},
+			"LATER":   {ID: "p6", Code: "LATER", Kind: domain.PromoKindFixed, Value: 100, StartsAt: now.Add(time.Hour)},
+			"EXPIRED": {ID: "p7", Code: "EXPIRED", Kind: domain.PromoKindFixed, Value: 100, StartsAt: now.Add(-2 * time.Hour), EndsAt: now},
+			"FLEET2":  {ID: "p8", Code: "FLEET2", FleetID: "fleet-2", Kind: domain.PromoKindFixed, Value: 100, StartsAt: now.Add(-time.Hour)},
+			"GONE":    {ID: "p9", Code: "GONE", Kind: domain.PromoKindFixed, Value: 100, MaxRedemptions: 10, RedemptionCount: 10, StartsAt: now.Add(-time.Hour)},
+			"ONCE":    {ID: "p10", Code: "ONCE", Kind: domain.PromoKindFixed, Value: 100, MaxPerCustomer: 1, StartsAt: now.Add(-time.Hour)},
+			"USD":     {ID: "p11", Code: "USD", Kind: domain.PromoKindFixed, Value: 300, Currency: "USD", StartsAt: now.Add(-time.Hour)},
+			"VND":     {ID: "p12", Code: "VND", Kind: domain.PromoKindFixed, Value: 300, Currency: "VND", StartsAt: now.Add(-time.Hour)},
+		},
+		usage: map[string]int{"p10/repeat-customer": 1},
+	}, NewStandardStrategy())
+
+	tests := []struct {
+		name       string
+		code       string
+		customerID string
+		expected   int
+		wantErr    error
+	}{
+		{name: "No Promo", code: "", expected: 1000},
+		{name: "Percentage", code: "HALF", expected: 500},
+		{name: "Code Is Case Insensitive", code: " half ", expected: 500},
+		{name: "Percentage Capped", code: "CAPPED", expected: 800},
+		{name: "Fixed", code: "FIXED", expected: 700},
+		{name: "Fixed Never Below Zero", code: "HUGE", expected: 0},
+		{name: "Unknown Code", code: "NOPE", wantErr: domain.ErrPromoNotFound},
+		{name: "Below Minimum Fare", code: "MIN", wantErr: domain.ErrPromoMinFare},
+		{name: "Not Started", code: "LATER", wantErr: domain.ErrPromoNotActive},
+		{name: "Expired", code: "EXPIRED", wantErr: domain.ErrPromoNotActive},
+		{name: "Other Fleet", code: "FLEET2", wantErr: domain.ErrPromoNotApplicable},
+		{name: "Fare Currency", code: "USD", expected: 700},
+		{name: "Other Currency", code: "VND", wantErr: domain.ErrPromoCurrency},
+		{name: "Global Limit Reached", code: "GONE", wantErr: domain.ErrPromoExhausted},
+		{name: "Customer Limit Requires Customer", code: "ONCE", wantErr: domain.ErrPromoCustomerRequired},
+		{name: "Customer Within Limit", code: "ONCE", customerID: "new-customer", expected: 900},
+		{name: "Customer Limit Reached", code: "ONCE", customerID: "repeat-customer", wantErr: domain.ErrPromoCustomerLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := strategy.CalculatePrice(context.Background(), domain.PricingInput{
+				FleetID:        "fleet-1",
+				DistanceMeters: 10000,
+				Vehicle:        domain.VehicleBike,
+				Time:           now,
+				CustomerID:     tt.customerID,
+				PromoCode:      tt.code,
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got.TotalCents)
+			if tt.code != "" {
+				assert.Equal(t, NormalizePromoCode(tt.code), got.PromoCode)
+				assert.Equal(t, tt.expected-1000, got.Subtotal(domain.FareLineDiscount))
+			}
+		})
+	}
+}
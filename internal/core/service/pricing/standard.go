@@ -3,22 +3,43 @@ package pricing
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
 
 	"github.com/vantutran2k1/flowfleet/internal/core/domain"
 )
 
-var (
-	BaseRates = map[domain.VehicleType]int{
-		domain.VehicleBike:  500,
-		domain.VehicleVan:   1500,
-		domain.VehicleTruck: 3000,
-	}
-	PerKmRates = map[domain.VehicleType]int{
-		domain.VehicleBike:  50,
-		domain.VehicleVan:   100,
-		domain.VehicleTruck: 200,
-	}
-)
+// VehicleRates is what a vehicle type charges, in the minor unit of a
+// currency.
+type VehicleRates struct {
+	BaseCents  int
+	PerKmCents int
+}
+
+// StandardRates are the default rates of each vehicle type by currency, for
+// fleets without a tariff schedule. Every supported currency has them.
+var StandardRates = map[string]map[domain.VehicleType]VehicleRates{
+	"USD": {
+		domain.VehicleBike:  {BaseCents: 500, PerKmCents: 50},
+		domain.VehicleVan:   {BaseCents: 1500, PerKmCents: 100},
+		domain.VehicleTruck: {BaseCents: 3000, PerKmCents: 200},
+	},
+	"EUR": {
+		domain.VehicleBike:  {BaseCents: 450, PerKmCents: 45},
+		domain.VehicleVan:   {BaseCents: 1400, PerKmCents: 90},
+		domain.VehicleTruck: {BaseCents: 2800, PerKmCents: 180},
+	},
+	"SGD": {
+		domain.VehicleBike:  {BaseCents: 650, PerKmCents: 65},
+		domain.VehicleVan:   {BaseCents: 2000, PerKmCents: 130},
+		domain.VehicleTruck: {BaseCents: 4000, PerKmCents: 260},
+	},
+	"VND": {
+		domain.VehicleBike:  {BaseCents: 15000, PerKmCents: 5000},
+		domain.VehicleVan:   {BaseCents: 50000, PerKmCents: 10000},
+		domain.VehicleTruck: {BaseCents: 100000, PerKmCents: 20000},
+	},
+}
 
 type StandardStrategy struct{}
 
@@ -26,18 +47,32 @@ func NewStandardStrategy() *StandardStrategy {
 	return &StandardStrategy{}
 }
 
-func (s *StandardStrategy) CalculatePrice(ctx context.Context, input domain.PricingInput) (int, error) {
-	base, ok := BaseRates[input.Vehicle]
+func (s *StandardStrategy) CalculatePrice(ctx context.Context, input domain.PricingInput) (domain.Fare, error) {
+	currency := input.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+	rates, ok := StandardRates[currency]
 	if !ok {
-		return 0, errors.New("unsupported vehicle type")
+		return domain.Fare{}, fmt.Errorf("%w: %s", domain.ErrNoRates, currency)
+	}
+	rate, ok := rates[input.Vehicle]
+	if !ok {
+		return domain.Fare{}, errors.New("unsupported vehicle type")
 	}
 
-	ratePerKm, _ := PerKmRates[input.Vehicle]
+	return distanceFare(currency, rate.BaseCents, rate.PerKmCents, input.DistanceMeters, "Base fare"), nil
+}
 
-	distanceKM := input.DistanceMeters / 1000.0
-	variable := int(distanceKM * float64(ratePerKm))
+// distanceFare builds the base and per-km lines shared by distance based
+// strategies. The distance charge is rounded half up to the minor unit.
+func distanceFare(currency string, baseCents, perKmCents int, distanceMeters float64, baseDescription string) domain.Fare {
+	distanceKM := distanceMeters / 1000.0
+	variable := int(math.Round(distanceKM * float64(perKmCents)))
 
-	total := base + variable
+	fare := domain.NewFare(currency)
+	fare.Add(domain.FareLineBase, baseDescription, baseCents)
+	fare.Add(domain.FareLineDistance, fmt.Sprintf("%.2f km", distanceKM), variable)
 
-	return total, nil
+	return fare
 }
@@ -44,8 +44,68 @@ func TestStandardStrategy_CalculatePrice(t *testing.T) {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expected, got)
+				assert.Equal(t, tt.expected, got.TotalCents)
 			}
 		})
 	}
 }
+
+func TestStandardStrategy_CalculatePrice_Breakdown(t *testing.T) {
+	strategy := NewStandardStrategy()
+
+	got, err := strategy.CalculatePrice(context.Background(), domain.PricingInput{
+		DistanceMeters: 2500,
+		Vehicle:        domain.VehicleTruck,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DefaultCurrency, got.Currency)
+	assert.Equal(t, []domain.FareLine{
+		{Kind: domain.FareLineBase, Description: "Base fare", AmountCents: 3000},
+		{Kind: domain.FareLineDistance, Description: "2.50 km", AmountCents: 500},
+	}, got.Lines)
+	assert.Equal(t, 3500, got.TotalCents)
+}
+
+func TestStandardStrategy_CalculatePrice_RoundsDistanceHalfUp(t *testing.T) {
+	got, err := NewStandardStrategy().CalculatePrice(context.Background(), domain.PricingInput{
+		Currency:       "USD",
+		DistanceMeters: 1290,
+		Vehicle:        domain.VehicleBike,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", got.Currency)
+	assert.Equal(t, 500+65, got.TotalCents)
+}
+
+func TestStandardStrategy_CalculatePrice_UsesCurrencyRates(t *testing.T) {
+	got, err := NewStandardStrategy().CalculatePrice(context.Background(), domain.PricingInput{
+		Currency:       "VND",
+		DistanceMeters: 1290,
+		Vehicle:        domain.VehicleBike,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "VND", got.Currency)
+	assert.Equal(t, 15000+6450, got.TotalCents)
+}
+
+func TestStandardStrategy_CalculatePrice_RejectsCurrencyWithoutRates(t *testing.T) {
+	_, err := NewStandardStrategy().CalculatePrice(context.Background(), domain.PricingInput{
+		Currency:       "JPY",
+		DistanceMeters: 1000,
+		Vehicle:        domain.VehicleBike,
+	})
+
+	assert.ErrorIs(t, err, domain.ErrNoRates)
+}
+
+func TestRatesCoverEveryCurrency(t *testing.T) {
+	for _, code := range domain.CurrencyCodes() {
+		assert.Len(t, StandardRates[code], 3, code)
+		assert.Contains(t, DefaultHandlingRates, code)
+	}
+	assert.Len(t, StandardRates, len(domain.CurrencyCodes()))
+	assert.Len(t, DefaultHandlingRates, len(domain.CurrencyCodes()))
+}
@@ -0,0 +1,40 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/core/port"
+)
+
+// TariffStrategy prices trips from the fleet's tariff schedule, deferring to
+// fallback when the fleet has no schedule or no rule covers the trip.
+type TariffStrategy struct {
+	repo     port.TariffRepository
+	fallback domain.PricingStrategy
+}
+
+func NewTariffStrategy(repo port.TariffRepository, fallback domain.PricingStrategy) *TariffStrategy {
+	return &TariffStrategy{
+		repo:     repo,
+		fallback: fallback,
+	}
+}
+
+func (s *TariffStrategy) CalculatePrice(ctx context.Context, input domain.PricingInput) (domain.Fare, error) {
+	schedule, err := s.repo.GetTariffSchedule(ctx, input.FleetID)
+	if err != nil {
+		if errors.Is(err, domain.ErrTariffNotFound) {
+			return s.fallback.CalculatePrice(ctx, input)
+		}
+		return domain.Fare{}, err
+	}
+
+	rule, ok := schedule.Match(input.Vehicle, input.Time)
+	if !ok {
+		return s.fallback.CalculatePrice(ctx, input)
+	}
+
+	return distanceFare(input.Currency, rule.BaseCents, rule.PerKmCents, input.DistanceMeters, "Base fare ("+rule.Name+")"), nil
+}
@@ -0,0 +1,123 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+type fakeTariffRepo struct {
+	schedules map[string]*domain.TariffSchedule
+	err       error
+}
+
+func (f *fakeTariffRepo) GetTariffSchedule(ctx context.Context, fleetID string) (*domain.TariffSchedule, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	s, ok := f.schedules[fleetID]
+	if !ok {
+		return nil, domain.ErrTariffNotFound
+	}
+	return s, nil
+}
+
+const (
+	everyDay = 0b1111111
+	weekend  = 0b1000001
+)
+
+func newTestSchedule(t *testing.T) *domain.TariffSchedule {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	return &domain.TariffSchedule{
+		FleetID:  "fleet-1",
+		Location: loc,
+		Holidays: map[string]bool{"2026-12-25": true},
+		Rules: []domain.TariffRule{
+			{Name: "bike day", Vehicle: domain.VehicleBike, DaysOfWeek: everyDay, StartMinute: 6 * 60, EndMinute: 22 * 60, BaseCents: 500, PerKmCents: 50},
+			{Name: "bike night", Vehicle: domain.VehicleBike, DaysOfWeek: everyDay, StartMinute: 22 * 60, EndMinute: 6 * 60, BaseCents: 800, PerKmCents: 80},
+			{Name: "bike weekend", Vehicle: domain.VehicleBike, DaysOfWeek: weekend, StartMinute: 0, EndMinute: 24 * 60, Priority: 10, BaseCents: 700, PerKmCents: 60},
+			{Name: "bike holiday", Vehicle: domain.VehicleBike, HolidaysOnly: true, StartMinute: 0, EndMinute: 24 * 60, Priority: 20, BaseCents: 1000, PerKmCents: 100},
+			{Name: "van day", Vehicle: domain.VehicleVan, DaysOfWeek: everyDay, StartMinute: 6 * 60, EndMinute: 22 * 60, BaseCents: 1500, PerKmCents: 100},
+			{Name: "van night", Vehicle: domain.VehicleVan, DaysOfWeek: everyDay, StartMinute: 22 * 60, EndMinute: 6 * 60, BaseCents: 2000, PerKmCents: 150},
+		},
+	}
+}
+
+func TestTariffStrategy_CalculatePrice(t *testing.T) {
+	schedule := newTestSchedule(t)
+	strategy := NewTariffStrategy(&fakeTariffRepo{
+		schedules: map[string]*domain.TariffSchedule{"fleet-1": schedule},
+	}, NewStandardStrategy())
+
+	local := func(value string) time.Time {
+		ts, err := time.ParseInLocation("2006-01-02 15:04", value, schedule.Location)
+		require.NoError(t, err)
+		return ts
+	}
+	utc := func(value string) time.Time {
+		ts, err := time.Parse(time.RFC3339, value)
+		require.NoError(t, err)
+		return ts
+	}
+
+	tests := []struct {
+		name     string
+		fleetID  string
+		vehicle  domain.VehicleType
+		at       time.Time
+		expected int
+	}{
+		{name: "Weekday Midday", fleetID: "fleet-1", vehicle: domain.VehicleBike, at: local("2026-03-04 12:00"), expected: 1000},
+		{name: "Day Window Opens Inclusive", fleetID: "fleet-1", vehicle: domain.VehicleBike, at: local("2026-03-04 06:00"), expected: 1000},
+		{name: "Minute Before Day Window", fleetID: "fleet-1", vehicle: domain.VehicleBike, at: local("2026-03-04 05:59"), expected: 1600},
+		{name: "Day Window Closes Exclusive", fleetID: "fleet-1", vehicle: domain.VehicleBike, at: local("2026-03-04 22:00"), expected: 1600},
+		{name: "Last Minute Of Day Window", fleetID: "fleet-1", vehicle: domain.VehicleBike, at: local("2026-03-04 21:59"), expected: 1000},
+		{name: "Friday Night Before Weekend", fleetID: "fleet-1", vehicle: domain.VehicleBike, at: local("2026-03-06 23:30"), expected: 1600},
+		{name: "Weekend Beats Overnight Window", fleetID: "fleet-1", vehicle: domain.VehicleBike, at: local("2026-03-07 01:00"), expected: 1300},
+		{name: "Sunday Night Spills Into Monday", fleetID: "fleet-1", vehicle: domain.VehicleBike, at: local("2026-03-09 01:00"), expected: 1600},
+		{name: "Holiday", fleetID: "fleet-1", vehicle: domain.VehicleBike, at: local("2026-12-25 12:00"), expected: 2000},
+		{name: "Day After Holiday", fleetID: "fleet-1", vehicle: domain.VehicleBike, at: local("2026-12-26 01:00"), expected: 1300},
+		{name: "Evaluated In Fleet Time Zone", fleetID: "fleet-1", vehicle: domain.VehicleVan, at: utc("2026-03-04T12:00:00Z"), expected: 2500},
+		{name: "DST Spring Forward Uses Daylight Offset", fleetID: "fleet-1", vehicle: domain.VehicleVan, at: utc("2026-03-08T10:30:00Z"), expected: 2500},
+		{name: "DST Fall Back Uses Standard Offset", fleetID: "fleet-1", vehicle: domain.VehicleVan, at: utc("2026-11-01T10:30:00Z"), expected: 3500},
+		{name: "DST Repeated Hour First Pass", fleetID: "fleet-1", vehicle: domain.VehicleVan, at: utc("2026-11-01T05:30:00Z"), expected: 3500},
+		{name: "DST Repeated Hour Second Pass", fleetID: "fleet-1", vehicle: domain.VehicleVan, at: utc("2026-11-01T06:30:00Z"), expected: 3500},
+		{name: "No Rule Falls Back To Standard", fleetID: "fleet-1", vehicle: domain.VehicleTruck, at: local("2026-03-04 12:00"), expected: 5000},
+		{name: "No Schedule Falls Back To Standard", fleetID: "fleet-2", vehicle: domain.VehicleBike, at: local("2026-03-04 23:00"), expected: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := strategy.CalculatePrice(context.Background(), domain.PricingInput{
+				FleetID:        tt.fleetID,
+				DistanceMeters: 10000,
+				Vehicle:        tt.vehicle,
+				Time:           tt.at,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got.TotalCents)
+		})
+	}
+}
+
+func TestTariffStrategy_CalculatePrice_RepositoryError(t *testing.T) {
+	repoErr := errors.New("connection refused")
+	strategy := NewTariffStrategy(&fakeTariffRepo{err: repoErr}, NewStandardStrategy())
+
+	_, err := strategy.CalculatePrice(context.Background(), domain.PricingInput{
+		FleetID:        "fleet-1",
+		DistanceMeters: 10000,
+		Vehicle:        domain.VehicleBike,
+		Time:           time.Now(),
+	})
+
+	assert.ErrorIs(t, err, repoErr)
+}
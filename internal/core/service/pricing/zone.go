@@ -0,0 +1,79 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/core/port"
+)
+
+// ZoneStrategy prices trips by the fleet's pricing zones. A zone-to-zone fare
+// replaces the distance price entirely; otherwise the surcharges of the zones
+// touched by the trip are added on top of the base strategy's price.
+type ZoneStrategy struct {
+	repo port.ZoneRepository
+	base domain.PricingStrategy
+}
+
+func NewZoneStrategy(repo port.ZoneRepository, base domain.PricingStrategy) *ZoneStrategy {
+	return &ZoneStrategy{
+		repo: repo,
+		base: base,
+	}
+}
+
+func (s *ZoneStrategy) CalculatePrice(ctx context.Context, input domain.PricingInput) (domain.Fare, error) {
+	pickupZone, err := s.findZone(ctx, input.FleetID, input.Pickup)
+	if err != nil {
+		return domain.Fare{}, err
+	}
+
+	dropoffZone, err := s.findZone(ctx, input.FleetID, input.Dropoff)
+	if err != nil {
+		return domain.Fare{}, err
+	}
+
+	if pickupZone != nil && dropoffZone != nil {
+		amount, err := s.repo.GetZoneFare(ctx, pickupZone.ID, dropoffZone.ID, input.Vehicle)
+		if err == nil {
+			fare := domain.NewFare(input.Currency)
+			fare.Add(domain.FareLineBase, "Zone fare "+pickupZone.Name+" to "+dropoffZone.Name, amount)
+			return fare, nil
+		}
+		if !errors.Is(err, domain.ErrZoneFareNotFound) {
+			return domain.Fare{}, err
+		}
+	}
+
+	fare, err := s.base.CalculatePrice(ctx, input)
+	if err != nil {
+		return domain.Fare{}, err
+	}
+
+	addZoneSurcharge(&fare, pickupZone)
+	if pickupZone == nil || dropoffZone == nil || dropoffZone.ID != pickupZone.ID {
+		addZoneSurcharge(&fare, dropoffZone)
+	}
+
+	return fare, nil
+}
+
+func addZoneSurcharge(fare *domain.Fare, zone *domain.PricingZone) {
+	if zone == nil || zone.SurchargeCents == 0 {
+		return
+	}
+	fare.Add(domain.FareLineSurcharge, zone.Name+" zone surcharge", zone.SurchargeCents)
+}
+
+func (s *ZoneStrategy) findZone(ctx context.Context, fleetID string, loc domain.Location) (*domain.PricingZone, error) {
+	zone, err := s.repo.FindZone(ctx, fleetID, loc)
+	if err != nil {
+		if errors.Is(err, domain.ErrZoneNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return zone, nil
+}
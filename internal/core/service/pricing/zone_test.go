@@ -0,0 +1,99 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+type fakeZoneRepo struct {
+	zones map[domain.Location]*domain.PricingZone
+	fares map[string]int
+	err   error
+}
+
+func (f *fakeZoneRepo) FindZone(ctx context.Context, fleetID string, loc domain.Location) (*domain.PricingZone, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	z, ok := f.zones[loc]
+	if !ok {
+		return nil, domain.ErrZoneNotFound
+	}
+	return z, nil
+}
+
+func (f *fakeZoneRepo) GetZoneFare(ctx context.Context, fromZoneID, toZoneID string, vehicle domain.VehicleType) (int, error) {
+	fare, ok := f.fares[fromZoneID+">"+toZoneID+">"+string(vehicle)]
+	if !ok {
+		return 0, domain.ErrZoneFareNotFound
+	}
+	return fare, nil
+}
+
+func TestZoneStrategy_CalculatePrice(t *testing.T) {
+	airport := &domain.PricingZone{ID: "airport", Name: "Airport", SurchargeCents: 300}
+	downtown := &domain.PricingZone{ID: "downtown", Name: "Downtown", SurchargeCents: 100}
+
+	airportPoint := domain.Location{Lat: 10.81, Lng: 106.66}
+	downtownPoint := domain.Location{Lat: 10.77, Lng: 106.70}
+	downtownPoint2 := domain.Location{Lat: 10.78, Lng: 106.69}
+	suburbPoint := domain.Location{Lat: 10.90, Lng: 106.50}
+
+	strategy := NewZoneStrategy(&fakeZoneRepo{
+		zones: map[domain.Location]*domain.PricingZone{
+			airportPoint:   airport,
+			downtownPoint:  downtown,
+			downtownPoint2: downtown,
+		},
+		fares: map[string]int{
+			"airport>downtown>BIKE": 1200,
+		},
+	}, NewStandardStrategy())
+
+	tests := []struct {
+		name     string
+		pickup   domain.Location
+		dropoff  domain.Location
+		vehicle  domain.VehicleType
+		expected int
+	}{
+		{name: "Zone Matrix Fare", pickup: airportPoint, dropoff: downtownPoint, vehicle: domain.VehicleBike, expected: 1200},
+		{name: "Matrix Is Directional", pickup: downtownPoint, dropoff: airportPoint, vehicle: domain.VehicleBike, expected: 1000 + 100 + 300},
+		{name: "Matrix Is Per Vehicle", pickup: airportPoint, dropoff: downtownPoint, vehicle: domain.VehicleVan, expected: 2500 + 300 + 100},
+		{name: "Same Zone Surcharged Once", pickup: downtownPoint, dropoff: downtownPoint2, vehicle: domain.VehicleBike, expected: 1000 + 100},
+		{name: "Pickup Zone Only", pickup: airportPoint, dropoff: suburbPoint, vehicle: domain.VehicleBike, expected: 1000 + 300},
+		{name: "Dropoff Zone Only", pickup: suburbPoint, dropoff: downtownPoint, vehicle: domain.VehicleBike, expected: 1000 + 100},
+		{name: "Outside All Zones", pickup: suburbPoint, dropoff: suburbPoint, vehicle: domain.VehicleBike, expected: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := strategy.CalculatePrice(context.Background(), domain.PricingInput{
+				FleetID:        "fleet-1",
+				Pickup:         tt.pickup,
+				Dropoff:        tt.dropoff,
+				DistanceMeters: 10000,
+				Vehicle:        tt.vehicle,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got.TotalCents)
+		})
+	}
+}
+
+func TestZoneStrategy_CalculatePrice_RepositoryError(t *testing.T) {
+	repoErr := errors.New("connection refused")
+	strategy := NewZoneStrategy(&fakeZoneRepo{err: repoErr}, NewStandardStrategy())
+
+	_, err := strategy.CalculatePrice(context.Background(), domain.PricingInput{
+		FleetID:        "fleet-1",
+		DistanceMeters: 10000,
+		Vehicle:        domain.VehicleBike,
+	})
+
+	assert.ErrorIs(t, err, repoErr)
+}
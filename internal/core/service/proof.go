@@ -0,0 +1,233 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/core/port"
+)
+
+// maxDeliveryPINAttempts is how many wrong PINs a driver may enter for an
+// order before it is locked and the recipient's PIN can no longer prove
+// delivery.
+const maxDeliveryPINAttempts = 5
+
+// proofImageTypes maps the image types accepted as proof to the extension
+// they are stored with.
+var proofImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+func (s *DispatchService) SetBlobStore(blobs port.BlobStore) {
+	s.blobs = blobs
+}
+
+// newDeliveryPIN returns a random six-digit PIN and the hash to store for it.
+func newDeliveryPIN() (string, []byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", nil, err
+	}
+	pin := fmt.Sprintf("%06d", n.Int64())
+	return pin, hashDeliveryPIN(pin), nil
+}
+
+func hashDeliveryPIN(pin string) []byte {
+	sum := sha256.Sum256([]byte(pin))
+	return sum[:]
+}
+
+// SubmitProofInput is evidence collected at the dropoff. Empty fields leave
+// what was submitted before in place, so proof may be collected in several
+// uploads.
+type SubmitProofInput struct {
+	Photo         io.Reader
+	Signature     io.Reader
+	RecipientName string
+	PIN           string
+}
+
+// SubmitProof records proof of delivery for an order the driver has picked
+// up. Images must be JPEG or PNG and are kept in blob storage; a PIN is
+// checked against the one issued with the order.
+func (s *DispatchService) SubmitProof(ctx context.Context, driverID, orderID uuid.UUID, input SubmitProofInput) (*domain.ProofOfDelivery, error) {
+	order, err := s.store.GetOrder(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrOrderNotFound
+		}
+		return nil, err
+	}
+	if order.DriverID != (pgtype.UUID{Bytes: driverID, Valid: true}) || order.Status != postgres.OrderStatusPickedUp {
+		return nil, domain.ErrInvalidTransition
+	}
+
+	photo, photoExt, err := proofImage(input.Photo)
+	if err != nil {
+		return nil, err
+	}
+	signature, signatureExt, err := proofImage(input.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if (photo != nil || signature != nil) && s.blobs == nil {
+		return nil, errors.New("blob storage is not configured")
+	}
+
+	params := postgres.UpsertDeliveryProofParams{
+		OrderID:       orderID,
+		RecipientName: strings.TrimSpace(input.RecipientName),
+	}
+	if input.PIN != "" {
+		if err := s.verifyDeliveryPIN(ctx, orderID, input.PIN); err != nil {
+			return nil, err
+		}
+		params.PinVerifiedAt = timestamptz(time.Now())
+	}
+	if photo != nil {
+		params.PhotoKey = proofKey(orderID, "photo", photoExt)
+		if err := s.blobs.Put(ctx, params.PhotoKey, photo); err != nil {
+			return nil, err
+		}
+	}
+	if signature != nil {
+		params.SignatureKey = proofKey(orderID, "signature", signatureExt)
+		if err := s.blobs.Put(ctx, params.SignatureKey, signature); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.store.UpsertDeliveryProof(ctx, params); err != nil {
+		return nil, err
+	}
+
+	return s.GetProof(ctx, orderID)
+}
+
+// verifyDeliveryPIN checks pin against the order's, counting wrong attempts
+// until the order is locked.
+func (s *DispatchService) verifyDeliveryPIN(ctx context.Context, orderID uuid.UUID, pin string) error {
+	row, err := s.store.GetOrderDeliveryPIN(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if row.DeliveryPinAttempts >= maxDeliveryPINAttempts {
+		return domain.ErrDeliveryPINLocked
+	}
+	if len(row.DeliveryPinHash) > 0 && subtle.ConstantTimeCompare(hashDeliveryPIN(pin), row.DeliveryPinHash) == 1 {
+		return nil
+	}
+
+	if err := s.store.IncrementDeliveryPINAttempts(ctx, orderID); err != nil {
+		return err
+	}
+	return domain.ErrInvalidDeliveryPIN
+}
+
+func (s *DispatchService) GetProof(ctx context.Context, orderID uuid.UUID) (*domain.ProofOfDelivery, error) {
+	row, err := s.store.GetDeliveryProof(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrProofNotFound
+		}
+		return nil, err
+	}
+
+	return &domain.ProofOfDelivery{
+		OrderID:       row.OrderID.String(),
+		PhotoKey:      row.PhotoKey,
+		SignatureKey:  row.SignatureKey,
+		RecipientName: row.RecipientName,
+		PINVerifiedAt: row.PinVerifiedAt.Time,
+		UpdatedAt:     row.UpdatedAt,
+	}, nil
+}
+
+// OpenProofImage returns the order's proof photo or signature, by kind, and
+// the key it is stored under.
+func (s *DispatchService) OpenProofImage(ctx context.Context, orderID uuid.UUID, kind string) (io.ReadCloser, string, error) {
+	proof, err := s.GetProof(ctx, orderID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := proof.PhotoKey
+	if kind == "signature" {
+		key = proof.SignatureKey
+	}
+	if key == "" || s.blobs == nil {
+		return nil, "", domain.ErrProofNotFound
+	}
+
+	body, err := s.blobs.Get(ctx, key)
+	if errors.Is(err, port.ErrBlobNotFound) {
+		return nil, "", domain.ErrProofNotFound
+	}
+	return body, key, err
+}
+
+// checkProof refuses to deliver an order whose fleet requires evidence that
+// has not been collected.
+func checkProof(ctx context.Context, q postgres.Querier, orderID uuid.UUID) error {
+	row, err := q.GetDeliveryProofCheck(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	requirements := domain.ProofRequirements{
+		Photo:         row.PodPhoto,
+		Signature:     row.PodSignature,
+		RecipientName: row.PodRecipientName,
+		PIN:           row.PodPin,
+	}
+	missing := requirements.Missing(domain.ProofOfDelivery{
+		PhotoKey:      row.PhotoKey,
+		SignatureKey:  row.SignatureKey,
+		RecipientName: row.RecipientName,
+		PINVerifiedAt: row.PinVerifiedAt.Time,
+	})
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing %s", domain.ErrProofIncomplete, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// proofImage checks that an uploaded image is JPEG or PNG by its content and
+// returns a reader over all of it with the extension to store it under. A
+// nil upload is returned as nil.
+func proofImage(upload io.Reader) (io.Reader, string, error) {
+	if upload == nil {
+		return nil, "", nil
+	}
+
+	r := bufio.NewReaderSize(upload, 512)
+	head, err := r.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, "", err
+	}
+	ext, ok := proofImageTypes[http.DetectContentType(head)]
+	if !ok {
+		return nil, "", domain.ErrInvalidProofImage
+	}
+	return r, ext, nil
+}
+
+func proofKey(orderID uuid.UUID, kind, ext string) string {
+	return fmt.Sprintf("proofs/%s/%s%s", orderID, kind, ext)
+}
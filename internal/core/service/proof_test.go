@@ -0,0 +1,140 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+	"github.com/vantutran2k1/flowfleet/internal/core/port"
+)
+
+type memoryBlobStore map[string][]byte
+
+func (m memoryBlobStore) Put(_ context.Context, key string, body io.Reader) error {
+	data, err := io.ReadAll(body)
+	m[key] = data
+	return err
+}
+
+func (m memoryBlobStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	data, ok := m[key]
+	if !ok {
+		return nil, port.ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// pngImage is the PNG signature followed by padding, enough for content
+// sniffing.
+var pngImage = append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+
+func TestNewDeliveryPIN(t *testing.T) {
+	pin, hash, err := newDeliveryPIN()
+	require.NoError(t, err)
+
+	assert.Len(t, pin, 6)
+	assert.Equal(t, hashDeliveryPIN(pin), hash)
+}
+
+func TestDispatchService_SubmitProof(t *testing.T) {
+	driverID := uuid.New()
+	orderID := uuid.New()
+	pickedUp := postgres.GetOrderRow{
+		ID:       orderID,
+		DriverID: pgtype.UUID{Bytes: driverID, Valid: true},
+		Status:   postgres.OrderStatusPickedUp,
+	}
+
+	t.Run("Photo And PIN", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		blobs := memoryBlobStore{}
+		mockRepo.On("GetOrder", mock.Anything, orderID).Return(pickedUp, nil)
+		mockRepo.On("GetOrderDeliveryPIN", mock.Anything, orderID).Return(postgres.GetOrderDeliveryPINRow{
+			DeliveryPinHash: hashDeliveryPIN("042917"),
+		}, nil)
+		mockRepo.On("UpsertDeliveryProof", mock.Anything, mock.MatchedBy(func(arg postgres.UpsertDeliveryProofParams) bool {
+			return arg.PhotoKey == "proofs/"+orderID.String()+"/photo.png" && arg.SignatureKey == "" &&
+				arg.RecipientName == "Lan" && arg.PinVerifiedAt.Valid
+		})).Return(nil)
+		mockRepo.On("GetDeliveryProof", mock.Anything, orderID).Return(postgres.GetDeliveryProofRow{
+			OrderID: orderID, PhotoKey: "proofs/" + orderID.String() + "/photo.png", RecipientName: "Lan",
+			PinVerifiedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		}, nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		svc.SetBlobStore(blobs)
+		proof, err := svc.SubmitProof(context.Background(), driverID, orderID, SubmitProofInput{
+			Photo:         bytes.NewReader(pngImage),
+			RecipientName: " Lan ",
+			PIN:           "042917",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Lan", proof.RecipientName)
+		assert.Equal(t, pngImage, blobs[proof.PhotoKey])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Wrong PIN", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		mockRepo.On("GetOrder", mock.Anything, orderID).Return(pickedUp, nil)
+		mockRepo.On("GetOrderDeliveryPIN", mock.Anything, orderID).Return(postgres.GetOrderDeliveryPINRow{
+			DeliveryPinHash: hashDeliveryPIN("042917"), DeliveryPinAttempts: 2,
+		}, nil)
+		mockRepo.On("IncrementDeliveryPINAttempts", mock.Anything, orderID).Return(nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		_, err := svc.SubmitProof(context.Background(), driverID, orderID, SubmitProofInput{PIN: "000000"})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidDeliveryPIN)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "UpsertDeliveryProof", mock.Anything, mock.Anything)
+	})
+
+	t.Run("PIN Locked", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		mockRepo.On("GetOrder", mock.Anything, orderID).Return(pickedUp, nil)
+		mockRepo.On("GetOrderDeliveryPIN", mock.Anything, orderID).Return(postgres.GetOrderDeliveryPINRow{
+			DeliveryPinHash: hashDeliveryPIN("042917"), DeliveryPinAttempts: maxDeliveryPINAttempts,
+		}, nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		_, err := svc.SubmitProof(context.Background(), driverID, orderID, SubmitProofInput{PIN: "042917"})
+
+		assert.ErrorIs(t, err, domain.ErrDeliveryPINLocked)
+	})
+
+	t.Run("Not An Image", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		mockRepo.On("GetOrder", mock.Anything, orderID).Return(pickedUp, nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		svc.SetBlobStore(memoryBlobStore{})
+		_, err := svc.SubmitProof(context.Background(), driverID, orderID, SubmitProofInput{
+			Signature: strings.NewReader("<svg></svg>"),
+		})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidProofImage)
+	})
+
+	t.Run("Another Driver's Order", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		mockRepo.On("GetOrder", mock.Anything, orderID).Return(pickedUp, nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		_, err := svc.SubmitProof(context.Background(), uuid.New(), orderID, SubmitProofInput{RecipientName: "Lan"})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+	})
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+// recurringBatchSize caps how many templates, and how many occurrences of
+// one template, a single pass materializes; the rest wait for the next pass.
+const recurringBatchSize = 100
+
+// RecurringPolicy controls how far ahead recurring templates are booked.
+// Each template is kept materialized into scheduled orders up to Horizon
+// ahead, and topped up once less than half of that is left.
+type RecurringPolicy struct {
+	Horizon time.Duration
+}
+
+var DefaultRecurringPolicy = RecurringPolicy{
+	Horizon: 24 * time.Hour,
+}
+
+func (s *DispatchService) SetRecurringPolicy(policy RecurringPolicy) {
+	s.recurringPolicy = policy
+}
+
+// CreateRecurringOrderInput describes a template. Schedule is a five-field
+// cron expression evaluated in TimeZone, UTC when empty.
+type CreateRecurringOrderInput struct {
+	FleetID    uuid.UUID
+	CustomerID string
+	Name       string
+	Schedule   string
+	TimeZone   string
+	Pickup     domain.Location
+	Dropoff    domain.Location
+	Vehicle    domain.VehicleType
+	Notes      string
+}
+
+func (s *DispatchService) CreateRecurringOrder(ctx context.Context, input CreateRecurringOrderInput) (uuid.UUID, error) {
+	if _, err := domain.ParseCron(input.Schedule); err != nil {
+		return uuid.Nil, err
+	}
+	if input.TimeZone == "" {
+		input.TimeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(input.TimeZone); err != nil {
+		return uuid.Nil, fmt.Errorf("%w: unknown time zone %q", domain.ErrInvalidRecurrence, input.TimeZone)
+	}
+	if input.Vehicle == "" {
+		input.Vehicle = domain.VehicleBike
+	}
+
+	row, err := s.store.CreateRecurringOrder(ctx, postgres.CreateRecurringOrderParams{
+		FleetID:       input.FleetID,
+		CustomerID:    input.CustomerID,
+		Name:          input.Name,
+		Schedule:      input.Schedule,
+		TimeZone:      input.TimeZone,
+		StMakepoint:   input.Pickup.Lng,
+		StMakepoint_2: input.Pickup.Lat,
+		StMakepoint_3: input.Dropoff.Lng,
+		StMakepoint_4: input.Dropoff.Lat,
+		VehicleType:   string(input.Vehicle),
+		Notes:         input.Notes,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return row.ID, nil
+}
+
+func (s *DispatchService) GetRecurringOrder(ctx context.Context, id uuid.UUID) (*domain.RecurringOrder, error) {
+	row, err := s.store.GetRecurringOrder(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrRecurringOrderNotFound
+		}
+		return nil, err
+	}
+
+	return recurringOrder(row)
+}
+
+func (s *DispatchService) ListRecurringOrders(ctx context.Context, fleetID uuid.UUID) ([]*domain.RecurringOrder, error) {
+	rows, err := s.store.ListRecurringOrders(ctx, fleetID)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make([]*domain.RecurringOrder, 0, len(rows))
+	for _, row := range rows {
+		template, err := recurringOrder(postgres.GetRecurringOrderRow(row))
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, nil
+}
+
+// PauseRecurringOrder stops a template from booking orders and cancels the
+// occurrences it booked that have not been released for dispatch yet.
+func (s *DispatchService) PauseRecurringOrder(ctx context.Context, id uuid.UUID) error {
+	template, err := s.GetRecurringOrder(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var cancelled []uuid.UUID
+	if err := s.store.ExecTx(ctx, func(q postgres.Querier) error {
+		if _, err := q.SetRecurringOrderPaused(ctx, postgres.SetRecurringOrderPausedParams{ID: id, Paused: true}); err != nil {
+			return err
+		}
+
+		cancelled, err = q.CancelRecurringOccurrences(ctx, postgres.CancelRecurringOccurrencesParams{
+			RecurringOrderID: pgtype.UUID{Bytes: id, Valid: true},
+			FromTime:         now,
+			ToTime:           now.Add(s.schedulePolicy.MaxAhead),
+		})
+		return err
+	}); err != nil {
+		return err
+	}
+
+	s.publishCancelled(template.FleetID, cancelled)
+
+	return nil
+}
+
+// ResumeRecurringOrder lets a paused template book orders again, starting
+// with its next occurrence.
+func (s *DispatchService) ResumeRecurringOrder(ctx context.Context, id uuid.UUID) error {
+	rows, err := s.store.SetRecurringOrderPaused(ctx, postgres.SetRecurringOrderPausedParams{ID: id, Paused: false})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrRecurringOrderNotFound
+	}
+	return nil
+}
+
+// SkipRecurringDate stops the template from firing on a local date, and
+// cancels an occurrence already booked for it.
+func (s *DispatchService) SkipRecurringDate(ctx context.Context, id uuid.UUID, date time.Time) error {
+	template, err := s.GetRecurringOrder(ctx, id)
+	if err != nil {
+		return err
+	}
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, template.Location)
+
+	var cancelled []uuid.UUID
+	if err := s.store.ExecTx(ctx, func(q postgres.Querier) error {
+		if err := q.AddRecurringOrderSkip(ctx, postgres.AddRecurringOrderSkipParams{
+			RecurringOrderID: id,
+			SkipDate:         pgtype.Date{Time: start, Valid: true},
+		}); err != nil {
+			return err
+		}
+
+		cancelled, err = q.CancelRecurringOccurrences(ctx, postgres.CancelRecurringOccurrencesParams{
+			RecurringOrderID: pgtype.UUID{Bytes: id, Valid: true},
+			FromTime:         start,
+			ToTime:           start.AddDate(0, 0, 1),
+		})
+		return err
+	}); err != nil {
+		return err
+	}
+
+	s.publishCancelled(template.FleetID, cancelled)
+
+	return nil
+}
+
+// UnskipRecurringDate lets the template fire on a skipped date again. An
+// occurrence on it is booked by the next pass if it is still ahead.
+func (s *DispatchService) UnskipRecurringDate(ctx context.Context, id uuid.UUID, date time.Time) error {
+	if _, err := s.GetRecurringOrder(ctx, id); err != nil {
+		return err
+	}
+
+	return s.store.ExecTx(ctx, func(q postgres.Querier) error {
+		if _, err := q.DeleteRecurringOrderSkip(ctx, postgres.DeleteRecurringOrderSkipParams{
+			RecurringOrderID: id,
+			SkipDate:         pgtype.Date{Time: date, Valid: true},
+		}); err != nil {
+			return err
+		}
+
+		// materialization skips occurrences that are already booked, so
+		// rescanning from now only books the one that was skipped
+		return q.SetRecurringOrderMaterializedUntil(ctx, postgres.SetRecurringOrderMaterializedUntilParams{ID: id})
+	})
+}
+
+// UpcomingOccurrences lists the next times the template fires within the
+// booking horizon, skipped ones included.
+func (s *DispatchService) UpcomingOccurrences(ctx context.Context, id uuid.UUID, limit int) ([]domain.Occurrence, error) {
+	template, err := s.GetRecurringOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return template.Occurrences(now, now.Add(s.schedulePolicy.MaxAhead), limit), nil
+}
+
+// MaterializeRecurringOrders books the coming occurrences of every active
+// template that is running short, and returns how many orders it booked.
+func (s *DispatchService) MaterializeRecurringOrders(ctx context.Context) (int, error) {
+	now := time.Now()
+	rows, err := s.store.ListDueRecurringOrders(ctx, postgres.ListDueRecurringOrdersParams{
+		MaterializedUntil: timestamptz(now.Add(s.recurringPolicy.Horizon / 2)),
+		Limit:             recurringBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	booked := 0
+	for _, row := range rows {
+		template, err := recurringOrder(postgres.GetRecurringOrderRow(row))
+		if err != nil {
+			log.Printf("skipping recurring order %s: %v", row.ID, err)
+			continue
+		}
+
+		n, err := s.materialize(ctx, template, row.MaterializedUntil.Time, now)
+		booked += n
+		if err != nil {
+			log.Printf("failed to materialize recurring order %s: %v", row.ID, err)
+		}
+	}
+
+	return booked, nil
+}
+
+// materialize books the template's occurrences after from, or now if that
+// is later, up to the horizon, then records how far it got. Occurrences
+// that already have a live order are left alone.
+func (s *DispatchService) materialize(ctx context.Context, template *domain.RecurringOrder, from, now time.Time) (int, error) {
+	if from.Before(now) {
+		from = now
+	}
+	to := now.Add(s.recurringPolicy.Horizon)
+	templateID, _ := uuid.Parse(template.ID)
+	fleetID, _ := uuid.Parse(template.FleetID)
+
+	existing, err := s.store.ListRecurringOccurrences(ctx, postgres.ListRecurringOccurrencesParams{
+		RecurringOrderID: pgtype.UUID{Bytes: templateID, Valid: true},
+		FromTime:         from,
+		ToTime:           to,
+	})
+	if err != nil {
+		return 0, err
+	}
+	booked := make(map[int64]bool, len(existing))
+	for _, at := range existing {
+		booked[at.Time.Unix()] = true
+	}
+
+	occurrences := template.Occurrences(from, to, recurringBatchSize)
+	until := to
+	if len(occurrences) == recurringBatchSize {
+		until = occurrences[len(occurrences)-1].At
+	}
+
+	created := 0
+	var bookErr error
+	for _, occurrence := range occurrences {
+		if occurrence.Skipped || booked[occurrence.At.Unix()] {
+			continue
+		}
+
+		result, err := s.CreateAndDispatchOrder(ctx, CreateOrderInput{
+			FleetID:           fleetID,
+			Pickup:            template.Pickup,
+			Dropoff:           template.Dropoff,
+			Vehicle:           template.Vehicle,
+			Notes:             template.Notes,
+			ScheduledPickupAt: occurrence.At,
+			CustomerID:        template.CustomerID,
+			RecurringOrderID:  templateID,
+		})
+		if result.OrderID == uuid.Nil {
+			// retried from here on the next pass
+			until = occurrence.At.Add(-time.Second)
+			bookErr = err
+			break
+		}
+		if err != nil {
+			log.Printf("booked occurrence %s of recurring order %s but could not dispatch it: %v", occurrence.At, template.ID, err)
+		}
+		created++
+	}
+
+	if err := s.store.SetRecurringOrderMaterializedUntil(ctx, postgres.SetRecurringOrderMaterializedUntilParams{
+		ID:                templateID,
+		MaterializedUntil: timestamptz(until),
+	}); err != nil {
+		return created, err
+	}
+
+	return created, bookErr
+}
+
+func (s *DispatchService) publishCancelled(fleetID string, orderIDs []uuid.UUID) {
+	for _, id := range orderIDs {
+		s.publishStatus(fleetID, id, domain.OrderStatusCancelled, nil)
+	}
+}
+
+func recurringOrder(row postgres.GetRecurringOrderRow) (*domain.RecurringOrder, error) {
+	schedule, err := domain.ParseCron(row.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(row.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	skips := make(map[string]bool, len(row.SkipDates))
+	for _, date := range row.SkipDates {
+		skips[date.Time.Format(time.DateOnly)] = true
+	}
+
+	return &domain.RecurringOrder{
+		ID:         row.ID.String(),
+		FleetID:    row.FleetID.String(),
+		CustomerID: row.CustomerID,
+		Name:       row.Name,
+		Schedule:   schedule,
+		Location:   loc,
+		Pickup:     domain.Location{Lat: row.PickupLat, Lng: row.PickupLng},
+		Dropoff:    domain.Location{Lat: row.DropoffLat, Lng: row.DropoffLng},
+		Vehicle:    domain.VehicleType(row.VehicleType),
+		Notes:      row.Notes,
+		Paused:     row.Paused,
+		Skips:      skips,
+	}, nil
+}
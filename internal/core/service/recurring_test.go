@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+func TestDispatchService_MaterializeRecurringOrders(t *testing.T) {
+	mockRepo := new(MockQuerier)
+	svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+	svc.SetRecurringPolicy(RecurringPolicy{Horizon: 72 * time.Hour})
+	templateID := uuid.New()
+
+	// daily at 09:00 UTC fires three times in the horizon: the first is
+	// skipped and the second already booked, so only the third is created
+	schedule, err := domain.ParseCron("0 9 * * *")
+	require.NoError(t, err)
+	first := schedule.Next(time.Now(), time.UTC)
+	second := schedule.Next(first, time.UTC)
+	third := schedule.Next(second, time.UTC)
+
+	mockRepo.On("ListDueRecurringOrders", mock.Anything, mock.Anything).Return([]postgres.ListDueRecurringOrdersRow{{
+		ID:          templateID,
+		FleetID:     uuid.New(),
+		Name:        "Morning run",
+		Schedule:    "0 9 * * *",
+		TimeZone:    "UTC",
+		VehicleType: string(domain.VehicleVan),
+		Notes:       "Ring twice",
+		SkipDates:   []pgtype.Date{{Time: first, Valid: true}},
+	}}, nil)
+	mockRepo.On("ListRecurringOccurrences", mock.Anything, mock.Anything).Return([]pgtype.Timestamptz{timestamptz(second)}, nil)
+	mockRepo.On("CheckServiceArea", mock.Anything, mock.Anything).Return(postgres.CheckServiceAreaRow{}, nil)
+	mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateOrder", mock.Anything, mock.MatchedBy(func(arg postgres.CreateOrderParams) bool {
+		return arg.ScheduledPickupAt.Time.Equal(third) &&
+			arg.RecurringOrderID == pgtype.UUID{Bytes: templateID, Valid: true} &&
+			arg.VehicleType == string(domain.VehicleVan) &&
+			arg.Notes == "Ring twice"
+	})).Return(postgres.CreateOrderRow{ID: uuid.New()}, nil).Once()
+	mockRepo.On("CreateOrderStop", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateOrderFareLine", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("SetRecurringOrderMaterializedUntil", mock.Anything, mock.MatchedBy(func(arg postgres.SetRecurringOrderMaterializedUntilParams) bool {
+		return arg.ID == templateID && !arg.MaterializedUntil.Time.Before(third)
+	})).Return(nil)
+
+	booked, err := svc.MaterializeRecurringOrders(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, booked)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDispatchService_PauseRecurringOrder(t *testing.T) {
+	templateID := uuid.New()
+	row := postgres.GetRecurringOrderRow{
+		ID:       templateID,
+		FleetID:  uuid.New(),
+		Schedule: "30 8 * * 1-5",
+		TimeZone: "Europe/Berlin",
+	}
+
+	t.Run("Cancels Booked Occurrences", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		mockRepo.On("GetRecurringOrder", mock.Anything, templateID).Return(row, nil)
+		mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("SetRecurringOrderPaused", mock.Anything, postgres.SetRecurringOrderPausedParams{ID: templateID, Paused: true}).Return(int64(1), nil)
+		mockRepo.On("CancelRecurringOccurrences", mock.Anything, mock.MatchedBy(func(arg postgres.CancelRecurringOccurrencesParams) bool {
+			return arg.RecurringOrderID.Bytes == templateID && arg.ToTime.After(arg.FromTime)
+		})).Return([]uuid.UUID{uuid.New(), uuid.New()}, nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		err := svc.PauseRecurringOrder(context.Background(), templateID)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Resume Unknown Template", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		mockRepo.On("SetRecurringOrderPaused", mock.Anything, postgres.SetRecurringOrderPausedParams{ID: templateID}).Return(int64(0), nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		err := svc.ResumeRecurringOrder(context.Background(), templateID)
+
+		assert.ErrorIs(t, err, domain.ErrRecurringOrderNotFound)
+	})
+}
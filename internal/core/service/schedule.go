@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+// releaseBatchSize caps how many scheduled orders one scheduler tick
+// dispatches; the rest wait for the next tick.
+const releaseBatchSize = 100
+
+// SchedulePolicy controls orders booked for a later pickup. They are held
+// until ReleaseLead before their pickup time, then dispatched like any new
+// order, and may be booked at most MaxAhead in advance.
+type SchedulePolicy struct {
+	ReleaseLead time.Duration
+	MaxAhead    time.Duration
+}
+
+var DefaultSchedulePolicy = SchedulePolicy{
+	ReleaseLead: 15 * time.Minute,
+	MaxAhead:    30 * 24 * time.Hour,
+}
+
+func (s *DispatchService) SetSchedulePolicy(policy SchedulePolicy) {
+	s.schedulePolicy = policy
+}
+
+// checkSchedule validates a requested pickup time and reports whether the
+// order must be held. Orders due within the release lead are dispatched
+// right away.
+func (s *DispatchService) checkSchedule(pickupAt, now time.Time) (bool, error) {
+	if pickupAt.IsZero() {
+		return false, nil
+	}
+	if !pickupAt.After(now) || pickupAt.After(now.Add(s.schedulePolicy.MaxAhead)) {
+		return false, domain.ErrInvalidSchedule
+	}
+
+	return pickupAt.After(now.Add(s.schedulePolicy.ReleaseLead)), nil
+}
+
+// pricedAt is the time an order is priced for: its pickup time when it was
+// booked ahead, otherwise now.
+func pricedAt(scheduledPickupAt time.Time) time.Time {
+	if scheduledPickupAt.IsZero() {
+		return time.Now()
+	}
+	return scheduledPickupAt
+}
+
+// OrderChanges are the changes to an order that has not been released for
+// dispatch yet. Zero fields keep the order's current value.
+type OrderChanges struct {
+	ScheduledPickupAt time.Time
+	DeliverBy         time.Time
+	Priority          domain.OrderPriority
+	Notes             *string
+}
+
+// RescheduleOrder changes an order that has not been released for dispatch
+// yet. The fare quoted at booking is kept, even when the order is moved to
+// a time that would have been priced differently.
+func (s *DispatchService) RescheduleOrder(ctx context.Context, orderID uuid.UUID, changes OrderChanges) error {
+	order, err := s.store.GetOrder(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrOrderNotFound
+		}
+		return err
+	}
+
+	params := postgres.RescheduleOrderParams{
+		ID:                orderID,
+		ScheduledPickupAt: order.ScheduledPickupAt,
+		DeliverBy:         order.DeliverBy,
+		Priority:          order.Priority,
+		Notes:             order.Notes,
+	}
+	if !changes.ScheduledPickupAt.IsZero() {
+		params.ScheduledPickupAt = timestamptz(changes.ScheduledPickupAt)
+	}
+	if !changes.DeliverBy.IsZero() {
+		params.DeliverBy = timestamptz(changes.DeliverBy)
+	}
+	if changes.Priority != "" {
+		if !changes.Priority.Valid() {
+			return domain.ErrInvalidPriority
+		}
+		params.Priority = postgres.OrderPriority(changes.Priority)
+	}
+	if changes.Notes != nil {
+		params.Notes = *changes.Notes
+	}
+
+	now := time.Now()
+	if _, err := s.checkSchedule(params.ScheduledPickupAt.Time, now); err != nil {
+		return err
+	}
+	if err := checkDeadline(params.DeliverBy.Time, params.ScheduledPickupAt.Time, now); err != nil {
+		return err
+	}
+
+	rows, err := s.store.RescheduleOrder(ctx, params)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrOrderNotScheduled
+	}
+
+	data := map[string]any{
+		"scheduled_pickup_at": params.ScheduledPickupAt.Time,
+		"priority":            params.Priority,
+	}
+	if params.DeliverBy.Valid {
+		data["deliver_by"] = params.DeliverBy.Time
+	}
+	s.publishStatus(order.FleetID.String(), orderID, domain.OrderStatusScheduled, data)
+
+	return nil
+}
+
+// CancelScheduledOrder cancels an order that has not been released for
+// dispatch yet, handing back any promo redemption it used.
+func (s *DispatchService) CancelScheduledOrder(ctx context.Context, orderID uuid.UUID) error {
+	order, err := s.store.GetOrder(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrOrderNotFound
+		}
+		return err
+	}
+
+	if err := s.store.ExecTx(ctx, func(q postgres.Querier) error {
+		rows, err := q.CancelScheduledOrder(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return domain.ErrOrderNotScheduled
+		}
+
+		promoID, err := q.DeletePromoRedemptionByOrder(ctx, orderID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return q.UnclaimPromoRedemption(ctx, promoID)
+	}); err != nil {
+		return err
+	}
+
+	s.publishStatus(order.FleetID.String(), orderID, domain.OrderStatusCancelled, nil)
+
+	return nil
+}
+
+// ReleaseDueOrders dispatches the scheduled orders whose pickup is within
+// the release lead and returns how many were released.
+func (s *DispatchService) ReleaseDueOrders(ctx context.Context) (int, error) {
+	ids, err := s.store.ListDueScheduledOrders(ctx, postgres.ListDueScheduledOrdersParams{
+		ScheduledPickupAt: timestamptz(time.Now().Add(s.schedulePolicy.ReleaseLead)),
+		Limit:             releaseBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if err := s.dispatchScheduled(ctx, id); err != nil {
+			log.Printf("failed to dispatch scheduled order %s: %v", id, err)
+		}
+	}
+
+	return len(ids), nil
+}
+
+// dispatchScheduled dispatches a due order as it was booked. The order stays
+// scheduled until it is assigned, so one the process stops dispatching is
+// released again on the next tick; one no driver takes is handed to the
+// pending redispatch.
+func (s *DispatchService) dispatchScheduled(ctx context.Context, orderID uuid.UUID) error {
+	order, err := s.GetOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	fleetID, _ := uuid.Parse(order.FleetID)
+
+	route, err := s.routeStops(ctx, order.Stops)
+	if err == nil {
+		req := dispatchRequest{
+			OrderID:  orderID,
+			FleetID:  fleetID,
+			Stops:    order.Stops,
+			Items:    order.Items,
+			Load:     order.Load,
+			Fare:     order.Fare,
+			Route:    route,
+			PickupAt: order.ScheduledPickupAt,
+			Priority: order.Priority,
+			// the order is announced as unassigned once released below
+			Redispatch: true,
+		}
+		if order.SLA != nil {
+			req.DeliverBy = order.SLA.DeliverBy
+		}
+		if err = s.dispatchOrder(ctx, req); err == nil {
+			return nil
+		}
+	}
+
+	rows, releaseErr := s.store.ReleaseScheduledOrder(ctx, orderID)
+	if releaseErr != nil {
+		return errors.Join(err, releaseErr)
+	}
+	if rows > 0 {
+		s.publishStatus(order.FleetID, orderID, domain.OrderStatusPending, nil)
+		s.publishUnassigned(order.FleetID, orderID, &order.Pickup)
+	}
+	return err
+}
+
+// RunScheduler books upcoming recurring orders, releases due scheduled
+// orders, offers orders still waiting for a driver again and records missed
+// delivery deadlines every interval until ctx is cancelled.
+func (s *DispatchService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			booked, err := s.MaterializeRecurringOrders(ctx)
+			if err != nil {
+				log.Printf("failed to book recurring orders: %v", err)
+			} else if booked > 0 {
+				log.Printf("booked %d recurring orders", booked)
+			}
+
+			released, err := s.ReleaseDueOrders(ctx)
+			if err != nil {
+				log.Printf("failed to release scheduled orders: %v", err)
+			} else if released > 0 {
+				log.Printf("released %d scheduled orders for dispatch", released)
+			}
+
+			assigned, err := s.RedispatchPendingOrders(ctx)
+			if err != nil {
+				log.Printf("failed to redispatch pending orders: %v", err)
+			} else if assigned > 0 {
+				log.Printf("assigned %d pending orders on redispatch", assigned)
+			}
+
+			if _, err := s.SweepSLABreaches(ctx); err != nil {
+				log.Printf("failed to sweep delivery deadlines: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+func TestDispatchService_CheckSchedule(t *testing.T) {
+	svc := NewDispatchService(new(MockQuerier), new(MockGeoFinder), &websocket.Hub{})
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		pickupAt  time.Time
+		scheduled bool
+		wantErr   error
+	}{
+		{name: "As Soon As Possible", pickupAt: time.Time{}},
+		{name: "Within Release Lead", pickupAt: now.Add(10 * time.Minute)},
+		{name: "Later Today", pickupAt: now.Add(3 * time.Hour), scheduled: true},
+		{name: "In The Past", pickupAt: now.Add(-time.Minute), wantErr: domain.ErrInvalidSchedule},
+		{name: "Beyond Horizon", pickupAt: now.Add(31 * 24 * time.Hour), wantErr: domain.ErrInvalidSchedule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduled, err := svc.checkSchedule(tt.pickupAt, now)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.scheduled, scheduled)
+		})
+	}
+}
+
+func TestDispatchService_CreateAndDispatchOrder_Scheduled(t *testing.T) {
+	mockRepo := new(MockQuerier)
+	mockGeo := new(MockGeoFinder)
+	svc := NewDispatchService(mockRepo, mockGeo, &websocket.Hub{})
+	pickupAt := time.Now().Add(2 * time.Hour)
+
+	mockRepo.On("CheckServiceArea", mock.Anything, mock.Anything).Return(postgres.CheckServiceAreaRow{}, nil)
+	mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateOrder", mock.Anything, mock.MatchedBy(func(arg postgres.CreateOrderParams) bool {
+		return arg.Status == postgres.OrderStatusScheduled && arg.ScheduledPickupAt.Time.Equal(pickupAt)
+	})).Return(postgres.CreateOrderRow{ID: uuid.New()}, nil)
+	mockRepo.On("CreateOrderStop", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateOrderFareLine", mock.Anything, mock.Anything).Return(nil)
+
+	result, err := svc.CreateAndDispatchOrder(context.Background(), CreateOrderInput{
+		FleetID:           uuid.New(),
+		Pickup:            domain.Location{Lat: 40.0, Lng: -74.0},
+		Dropoff:           domain.Location{Lat: 40.1, Lng: -74.1},
+		ScheduledPickupAt: pickupAt,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusScheduled, result.Status)
+	mockRepo.AssertExpectations(t)
+	mockGeo.AssertNotCalled(t, "FindNearestDrivers", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDispatchService_CancelScheduledOrder(t *testing.T) {
+	orderID := uuid.New()
+
+	t.Run("Hands Back Promo", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		promoID := uuid.New()
+		mockRepo.On("GetOrder", mock.Anything, orderID).Return(postgres.GetOrderRow{ID: orderID, FleetID: uuid.New()}, nil)
+		mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("CancelScheduledOrder", mock.Anything, orderID).Return(int64(1), nil)
+		mockRepo.On("DeletePromoRedemptionByOrder", mock.Anything, orderID).Return(promoID, nil)
+		mockRepo.On("UnclaimPromoRedemption", mock.Anything, promoID).Return(nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		err := svc.CancelScheduledOrder(context.Background(), orderID)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Without Promo", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		mockRepo.On("GetOrder", mock.Anything, orderID).Return(postgres.GetOrderRow{ID: orderID, FleetID: uuid.New()}, nil)
+		mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("CancelScheduledOrder", mock.Anything, orderID).Return(int64(1), nil)
+		mockRepo.On("DeletePromoRedemptionByOrder", mock.Anything, orderID).Return(uuid.Nil, pgx.ErrNoRows)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		err := svc.CancelScheduledOrder(context.Background(), orderID)
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "UnclaimPromoRedemption", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Already Released", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		mockRepo.On("GetOrder", mock.Anything, orderID).Return(postgres.GetOrderRow{ID: orderID, FleetID: uuid.New()}, nil)
+		mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("CancelScheduledOrder", mock.Anything, orderID).Return(int64(0), nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		err := svc.CancelScheduledOrder(context.Background(), orderID)
+
+		assert.ErrorIs(t, err, domain.ErrOrderNotScheduled)
+	})
+}
+
+func TestDispatchService_RescheduleOrder(t *testing.T) {
+	now := time.Now()
+	booked := postgres.GetOrderRow{
+		FleetID:           uuid.New(),
+		Status:            postgres.OrderStatusScheduled,
+		ScheduledPickupAt: pgtype.Timestamptz{Time: now.Add(3 * time.Hour), Valid: true},
+		DeliverBy:         pgtype.Timestamptz{Time: now.Add(5 * time.Hour), Valid: true},
+		Priority:          postgres.OrderPriorityStandard,
+		Notes:             "ring twice",
+	}
+	notes := "leave at reception"
+
+	tests := []struct {
+		name     string
+		changes  OrderChanges
+		rows     int64
+		wantErr  error
+		wantSave func(postgres.RescheduleOrderParams) bool
+	}{
+		{
+			name:    "Moves Pickup",
+			changes: OrderChanges{ScheduledPickupAt: now.Add(4 * time.Hour)},
+			rows:    1,
+			wantSave: func(arg postgres.RescheduleOrderParams) bool {
+				return arg.ScheduledPickupAt.Time.Equal(now.Add(4*time.Hour)) && arg.DeliverBy == booked.DeliverBy &&
+					arg.Priority == booked.Priority && arg.Notes == booked.Notes
+			},
+		},
+		{
+			name:    "Changes Deadline, Priority And Notes",
+			changes: OrderChanges{DeliverBy: now.Add(6 * time.Hour), Priority: domain.PriorityCritical, Notes: &notes},
+			rows:    1,
+			wantSave: func(arg postgres.RescheduleOrderParams) bool {
+				return arg.ScheduledPickupAt == booked.ScheduledPickupAt && arg.DeliverBy.Time.Equal(now.Add(6*time.Hour)) &&
+					arg.Priority == postgres.OrderPriorityCritical && arg.Notes == notes
+			},
+		},
+		{name: "Pickup After Deadline", changes: OrderChanges{ScheduledPickupAt: now.Add(6 * time.Hour)}, wantErr: domain.ErrInvalidDeadline},
+		{name: "Invalid Priority", changes: OrderChanges{Priority: "urgent"}, wantErr: domain.ErrInvalidPriority},
+		{name: "Already Released", changes: OrderChanges{ScheduledPickupAt: now.Add(4 * time.Hour)}, rows: 0, wantErr: domain.ErrOrderNotScheduled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockQuerier)
+			orderID := uuid.New()
+			mockRepo.On("GetOrder", mock.Anything, orderID).Return(booked, nil)
+			mockRepo.On("RescheduleOrder", mock.Anything, mock.Anything).Return(tt.rows, nil)
+
+			svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+			err := svc.RescheduleOrder(context.Background(), orderID, tt.changes)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantSave != nil {
+				mockRepo.AssertCalled(t, "RescheduleOrder", mock.Anything, mock.MatchedBy(tt.wantSave))
+			}
+		})
+	}
+}
+
+func TestDispatchService_ReleaseDueOrders(t *testing.T) {
+	tests := []struct {
+		name         string
+		drivers      []domain.NearbyDriver
+		wantReleased bool
+	}{
+		{name: "Assigned While Scheduled", drivers: []domain.NearbyDriver{{Location: domain.Location{Lat: 40.01, Lng: -74.01}}}},
+		{name: "No Driver Hands Over To Redispatch", drivers: []domain.NearbyDriver{}, wantReleased: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockQuerier)
+			mockGeo := new(MockGeoFinder)
+			svc := NewDispatchService(mockRepo, mockGeo, &websocket.Hub{})
+
+			orderID := uuid.New()
+			driverID := uuid.New()
+			mockRepo.On("ListDueScheduledOrders", mock.Anything, mock.Anything).Return([]uuid.UUID{orderID}, nil)
+			mockRepo.On("GetOrder", mock.Anything, orderID).Return(postgres.GetOrderRow{
+				ID:                orderID,
+				FleetID:           uuid.New(),
+				Status:            postgres.OrderStatusScheduled,
+				Currency:          domain.DefaultCurrency,
+				Priority:          postgres.OrderPriorityStandard,
+				ScheduledPickupAt: pgtype.Timestamptz{Time: time.Now().Add(10 * time.Minute), Valid: true},
+			}, nil)
+			mockRepo.On("ListOrderFareLines", mock.Anything, orderID).Return([]postgres.ListOrderFareLinesRow{}, nil)
+			mockRepo.On("ListOrderItems", mock.Anything, orderID).Return([]postgres.ListOrderItemsRow{}, nil)
+			mockRepo.On("ListOrderStops", mock.Anything, orderID).Return([]postgres.ListOrderStopsRow{
+				{Position: 0, Kind: postgres.OrderStopKindPickup, Status: postgres.OrderStopStatusPending, Lat: 40.0, Lng: -74.0},
+				{Position: 1, Kind: postgres.OrderStopKindDropoff, Status: postgres.OrderStopStatusPending, Lat: 40.1, Lng: -74.1},
+			}, nil)
+			for i := range tt.drivers {
+				tt.drivers[i].ID = driverID.String()
+			}
+			mockGeo.On("FindNearestDrivers", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.drivers, nil)
+			mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, Status: postgres.DriverStatusIdle}, nil)
+			mockRepo.On("GetDriverLoad", mock.Anything, driverID).Return(postgres.GetDriverLoadRow{}, nil)
+			mockRepo.On("LockDriverStatus", mock.Anything, driverID).Return(postgres.DriverStatusIdle, nil)
+			mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("SetDriverStatus", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("UpdateOrderStopSequences", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("AssignDriverToOrder", mock.Anything, mock.Anything).Return(int64(1), nil)
+			mockRepo.On("ReleaseScheduledOrder", mock.Anything, orderID).Return(int64(1), nil)
+
+			released, err := svc.ReleaseDueOrders(context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, released)
+			if tt.wantReleased {
+				mockRepo.AssertCalled(t, "ReleaseScheduledOrder", mock.Anything, orderID)
+				return
+			}
+			mockRepo.AssertNumberOfCalls(t, "AssignDriverToOrder", 1)
+			mockRepo.AssertNotCalled(t, "ReleaseScheduledOrder", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+// checkServiceArea rejects an order with a stop outside every service area
+// of the fleet. Fleets without service areas serve anywhere.
+func (s *DispatchService) checkServiceArea(ctx context.Context, fleetID uuid.UUID, stops []domain.Stop) error {
+	params := postgres.CheckServiceAreaParams{
+		FleetID: fleetID,
+		Lngs:    make([]float64, len(stops)),
+		Lats:    make([]float64, len(stops)),
+	}
+	for i, stop := range stops {
+		params.Lngs[i] = stop.Location.Lng
+		params.Lats[i] = stop.Location.Lat
+	}
+
+	coverage, err := s.store.CheckServiceArea(ctx, params)
+	if err != nil {
+		return err
+	}
+	if !coverage.HasAreas || len(coverage.Uncovered) == 0 {
+		return nil
+	}
+
+	stop := stops[coverage.Uncovered[0]]
+	if stop.Kind == domain.StopKindPickup {
+		return fmt.Errorf("%w (stop %d)", domain.ErrPickupOutsideServiceArea, stop.Position)
+	}
+	return fmt.Errorf("%w (stop %d)", domain.ErrDropoffOutsideServiceArea, stop.Position)
+}
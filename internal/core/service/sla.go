@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+// redispatchBatchSize caps how many pending orders one scheduler tick offers
+// to drivers again; the rest wait for the next tick.
+const redispatchBatchSize = 100
+
+// SLAPolicy controls delivery deadlines and orders waiting for a driver. An
+// order is at risk once its predicted dropoff is less than AtRiskMargin
+// before its deadline. Orders left pending for RedispatchAfter are offered
+// to drivers again, highest priority and tightest deadline first, so that
+// when drivers are scarce the most urgent orders get them. When
+// ReservedDrivers is set, a new standard order is only dispatched right away
+// when more than that many drivers could take it; otherwise it waits for the
+// redispatch, keeping the last drivers nearby for more urgent orders created
+// meanwhile. None are reserved by default.
+type SLAPolicy struct {
+	AtRiskMargin    time.Duration
+	RedispatchAfter time.Duration
+	ReservedDrivers int
+}
+
+var DefaultSLAPolicy = SLAPolicy{
+	AtRiskMargin:    5 * time.Minute,
+	RedispatchAfter: 30 * time.Second,
+	ReservedDrivers: 0,
+}
+
+func (s *DispatchService) SetSLAPolicy(policy SLAPolicy) {
+	s.slaPolicy = policy
+}
+
+// checkDeadline validates a requested delivery deadline, which must leave
+// time to pick the order up.
+func checkDeadline(deliverBy, scheduledPickupAt, now time.Time) error {
+	if deliverBy.IsZero() {
+		return nil
+	}
+	if !deliverBy.After(now) || !deliverBy.After(scheduledPickupAt) {
+		return domain.ErrInvalidDeadline
+	}
+	return nil
+}
+
+// RedispatchPendingOrders offers the orders still waiting for a driver to
+// the drivers available now, most urgent first, and returns how many were
+// assigned. An order that is not assigned waits RedispatchAfter again, so
+// the orders behind it get their turn.
+func (s *DispatchService) RedispatchPendingOrders(ctx context.Context) (int, error) {
+	ids, err := s.store.ListPendingOrders(ctx, postgres.ListPendingOrdersParams{
+		UpdatedAt: time.Now().Add(-s.slaPolicy.RedispatchAfter),
+		Limit:     redispatchBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	assigned := 0
+	for _, id := range ids {
+		order, err := s.GetOrder(ctx, id)
+		if err != nil {
+			log.Printf("failed to load pending order %s: %v", id, err)
+			continue
+		}
+		route, err := s.routeStops(ctx, order.Stops)
+		if err != nil {
+			s.recordDispatchAttempt(ctx, id)
+			continue
+		}
+		fleetID, _ := uuid.Parse(order.FleetID)
+
+		req := dispatchRequest{
+			OrderID:    id,
+			FleetID:    fleetID,
+			Stops:      order.Stops,
+			Items:      order.Items,
+			Load:       order.Load,
+			Fare:       order.Fare,
+			Route:      route,
+			PickupAt:   order.ScheduledPickupAt,
+			Priority:   order.Priority,
+			Redispatch: true,
+		}
+		if order.SLA != nil {
+			req.DeliverBy = order.SLA.DeliverBy
+		}
+		if err := s.dispatchOrder(ctx, req); err != nil {
+			s.recordDispatchAttempt(ctx, id)
+			continue
+		}
+		assigned++
+	}
+
+	return assigned, nil
+}
+
+func (s *DispatchService) recordDispatchAttempt(ctx context.Context, orderID uuid.UUID) {
+	if err := s.store.RecordDispatchAttempt(ctx, orderID); err != nil {
+		log.Printf("failed to record dispatch attempt for order %s: %v", orderID, err)
+	}
+}
+
+// SweepSLABreaches records the active orders whose deadline has passed and
+// announces each breach once. It returns how many were found.
+func (s *DispatchService) SweepSLABreaches(ctx context.Context) (int, error) {
+	rows, err := s.store.MarkSLABreaches(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, row := range rows {
+		driverID := ""
+		if row.DriverID.Valid {
+			driverID = uuid.UUID(row.DriverID.Bytes).String()
+		}
+		s.publishSLABreach(row.FleetID.String(), driverID, row.ID, domain.OrderStatus(row.Status), row.DeliverBy.Time)
+	}
+
+	return len(rows), nil
+}
+
+// ListSLABreaches returns the fleet's orders that breached their deadline
+// between from and to.
+func (s *DispatchService) ListSLABreaches(ctx context.Context, fleetID uuid.UUID, from, to time.Time) ([]domain.SLABreach, error) {
+	rows, err := s.store.ListSLABreachesByFleet(ctx, postgres.ListSLABreachesByFleetParams{
+		FleetID:  fleetID,
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	breaches := make([]domain.SLABreach, len(rows))
+	for i, row := range rows {
+		breaches[i] = domain.SLABreach{
+			OrderID:    row.ID.String(),
+			Status:     domain.OrderStatus(row.Status),
+			Priority:   domain.OrderPriority(row.Priority),
+			DeliverBy:  row.DeliverBy.Time,
+			BreachedAt: row.SlaBreachedAt.Time,
+		}
+		if row.DriverID.Valid {
+			breaches[i].DriverID = uuid.UUID(row.DriverID.Bytes).String()
+		}
+	}
+
+	return breaches, nil
+}
+
+func (s *DispatchService) publishSLAAtRisk(fleetID, driverID string, orderID uuid.UUID, deliverBy, dropoffAt time.Time) {
+	if fleetID == "" {
+		return
+	}
+	s.hub.PublishOps(websocket.OpsEvent{
+		Event:    websocket.EventOrderSLAAtRisk,
+		FleetID:  fleetID,
+		DriverID: driverID,
+		OrderID:  orderID.String(),
+		Data: map[string]any{
+			"deliver_by":     deliverBy,
+			"dropoff_eta_at": dropoffAt,
+		},
+	})
+}
+
+func (s *DispatchService) publishSLABreach(fleetID, driverID string, orderID uuid.UUID, status domain.OrderStatus, deliverBy time.Time) {
+	if fleetID == "" {
+		return
+	}
+	log.Printf("order %s missed its delivery deadline of %s", orderID, deliverBy.Format(time.RFC3339))
+	s.hub.PublishOps(websocket.OpsEvent{
+		Event:    websocket.EventOrderSLABreach,
+		FleetID:  fleetID,
+		DriverID: driverID,
+		OrderID:  orderID.String(),
+		Status:   string(status),
+		Data:     map[string]any{"deliver_by": deliverBy},
+	})
+}
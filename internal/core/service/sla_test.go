@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+func TestCheckDeadline(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		deliverBy time.Time
+		pickupAt  time.Time
+		wantErr   error
+	}{
+		{name: "No Deadline"},
+		{name: "Ahead", deliverBy: now.Add(time.Hour)},
+		{name: "In The Past", deliverBy: now.Add(-time.Minute), wantErr: domain.ErrInvalidDeadline},
+		{name: "After Scheduled Pickup", deliverBy: now.Add(3 * time.Hour), pickupAt: now.Add(2 * time.Hour)},
+		{name: "Before Scheduled Pickup", deliverBy: now.Add(time.Hour), pickupAt: now.Add(2 * time.Hour), wantErr: domain.ErrInvalidDeadline},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, checkDeadline(tt.deliverBy, tt.pickupAt, now), tt.wantErr)
+		})
+	}
+}
+
+func TestDispatchService_UpdateDriverLocation_SLAAtRisk(t *testing.T) {
+	mockRepo := new(MockQuerier)
+	svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+	svc.SetRoutingProvider(stubRouter{duration: 20 * time.Minute})
+
+	driverID := uuid.New()
+	orderID := uuid.New()
+	mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: uuid.New()}, nil)
+	mockRepo.On("ListActiveOrdersByDriver", mock.Anything, pgtype.UUID{Bytes: driverID, Valid: true}).
+		Return([]postgres.ListActiveOrdersByDriverRow{{
+			ID:        orderID,
+			Status:    postgres.OrderStatusPickedUp,
+			DeliverBy: pgtype.Timestamptz{Time: time.Now().Add(10 * time.Minute), Valid: true},
+		}}, nil)
+	mockRepo.On("ListDriverPendingStops", mock.Anything, pgtype.UUID{Bytes: driverID, Valid: true}).Return(pendingStops(orderID, 1), nil)
+	mockRepo.On("UpdateOrderETA", mock.Anything, mock.MatchedBy(func(arg postgres.UpdateOrderETAParams) bool {
+		return arg.ID == orderID && arg.SlaAtRiskSince.Valid
+	})).Return(nil)
+
+	err := svc.UpdateDriverLocation(context.Background(), driverID, domain.LocationFix{Location: domain.Location{Lat: 40.0, Lng: -74.0}, RecordedAt: time.Now()})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDispatchService_RedispatchPendingOrders(t *testing.T) {
+	mockRepo := new(MockQuerier)
+	mockGeo := new(MockGeoFinder)
+	svc := NewDispatchService(mockRepo, mockGeo, &websocket.Hub{})
+	svc.SetRoutingProvider(stubRouter{duration: 10 * time.Minute})
+
+	orderID := uuid.New()
+	driverID := uuid.New()
+	deliverBy := time.Now().Add(time.Hour)
+	mockRepo.On("ListPendingOrders", mock.Anything, mock.MatchedBy(func(arg postgres.ListPendingOrdersParams) bool {
+		return arg.UpdatedAt.Before(time.Now().Add(-DefaultSLAPolicy.RedispatchAfter + time.Second))
+	})).Return([]uuid.UUID{orderID}, nil)
+	mockRepo.On("GetOrder", mock.Anything, orderID).Return(postgres.GetOrderRow{
+		ID:        orderID,
+		FleetID:   uuid.New(),
+		Status:    postgres.OrderStatusPending,
+		Currency:  domain.DefaultCurrency,
+		Priority:  postgres.OrderPriorityCritical,
+		DeliverBy: pgtype.Timestamptz{Time: deliverBy, Valid: true},
+	}, nil)
+	mockRepo.On("ListOrderFareLines", mock.Anything, orderID).Return([]postgres.ListOrderFareLinesRow{}, nil)
+	mockRepo.On("ListOrderItems", mock.Anything, orderID).Return([]postgres.ListOrderItemsRow{}, nil)
+	mockRepo.On("ListOrderStops", mock.Anything, orderID).Return([]postgres.ListOrderStopsRow{
+		{Position: 0, Kind: postgres.OrderStopKindPickup, Status: postgres.OrderStopStatusPending, Lat: 40.0, Lng: -74.0},
+		{Position: 1, Kind: postgres.OrderStopKindDropoff, Status: postgres.OrderStopStatusPending, Lat: 40.1, Lng: -74.1},
+	}, nil)
+	mockGeo.On("FindNearestDrivers", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]domain.NearbyDriver{{ID: driverID.String(), Location: domain.Location{Lat: 40.01, Lng: -74.01}}}, nil)
+	mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{
+		ID:     driverID,
+		Status: postgres.DriverStatusIdle,
+	}, nil)
+	mockRepo.On("GetDriverLoad", mock.Anything, driverID).Return(postgres.GetDriverLoadRow{}, nil)
+	mockRepo.On("LockDriverStatus", mock.Anything, driverID).Return(postgres.DriverStatusIdle, nil)
+	mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("SetDriverStatus", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("UpdateOrderStopSequences", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("AssignDriverToOrder", mock.Anything, mock.MatchedBy(func(arg postgres.AssignDriverToOrderParams) bool {
+		// 20 minutes to deliver leaves plenty of room before the deadline
+		return arg.ID == orderID && !arg.SlaAtRiskSince.Valid
+	})).Return(int64(1), nil)
+
+	assigned, err := svc.RedispatchPendingOrders(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, assigned)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDispatchService_RedispatchPendingOrders_RecordsFailedAttempt(t *testing.T) {
+	mockRepo := new(MockQuerier)
+	mockGeo := new(MockGeoFinder)
+	svc := NewDispatchService(mockRepo, mockGeo, &websocket.Hub{})
+
+	orderID := uuid.New()
+	mockRepo.On("ListPendingOrders", mock.Anything, mock.Anything).Return([]uuid.UUID{orderID}, nil)
+	mockRepo.On("GetOrder", mock.Anything, orderID).Return(postgres.GetOrderRow{
+		ID:       orderID,
+		FleetID:  uuid.New(),
+		Status:   postgres.OrderStatusPending,
+		Currency: domain.DefaultCurrency,
+		Priority: postgres.OrderPriorityStandard,
+	}, nil)
+	mockRepo.On("ListOrderFareLines", mock.Anything, orderID).Return([]postgres.ListOrderFareLinesRow{}, nil)
+	mockRepo.On("ListOrderItems", mock.Anything, orderID).Return([]postgres.ListOrderItemsRow{}, nil)
+	mockRepo.On("ListOrderStops", mock.Anything, orderID).Return([]postgres.ListOrderStopsRow{
+		{Position: 0, Kind: postgres.OrderStopKindPickup, Status: postgres.OrderStopStatusPending, Lat: 40.0, Lng: -74.0},
+		{Position: 1, Kind: postgres.OrderStopKindDropoff, Status: postgres.OrderStopStatusPending, Lat: 40.1, Lng: -74.1},
+	}, nil)
+	mockGeo.On("FindNearestDrivers", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]domain.NearbyDriver{}, nil)
+	mockRepo.On("RecordDispatchAttempt", mock.Anything, orderID).Return(nil)
+
+	assigned, err := svc.RedispatchPendingOrders(context.Background())
+
+	assert.NoError(t, err)
+	assert.Zero(t, assigned)
+	mockRepo.AssertCalled(t, "RecordDispatchAttempt", mock.Anything, orderID)
+}
+
+func TestDispatchService_CreateAndDispatchOrder_ReservesDrivers(t *testing.T) {
+	tests := []struct {
+		name         string
+		priority     domain.OrderPriority
+		reserved     int
+		wantAssigned bool
+	}{
+		{name: "Standard Waits For Redispatch", priority: domain.PriorityStandard, reserved: 1},
+		{name: "Critical Takes Last Driver", priority: domain.PriorityCritical, reserved: 1, wantAssigned: true},
+		{name: "Nothing Reserved", priority: domain.PriorityStandard, wantAssigned: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockQuerier)
+			mockGeo := new(MockGeoFinder)
+			svc := NewDispatchService(mockRepo, mockGeo, &websocket.Hub{})
+			policy := DefaultSLAPolicy
+			policy.ReservedDrivers = tt.reserved
+			svc.SetSLAPolicy(policy)
+
+			driverID := uuid.New()
+			mockRepo.On("CheckServiceArea", mock.Anything, mock.Anything).Return(postgres.CheckServiceAreaRow{}, nil)
+			mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("CreateOrder", mock.Anything, mock.Anything).Return(postgres.CreateOrderRow{ID: uuid.New()}, nil)
+			mockRepo.On("CreateOrderStop", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("CreateOrderFareLine", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, Status: postgres.DriverStatusIdle}, nil)
+			mockRepo.On("GetDriverLoad", mock.Anything, driverID).Return(postgres.GetDriverLoadRow{}, nil)
+			mockRepo.On("LockDriverStatus", mock.Anything, driverID).Return(postgres.DriverStatusIdle, nil)
+			mockRepo.On("SetDriverStatus", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("UpdateOrderStopSequences", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("AssignDriverToOrder", mock.Anything, mock.Anything).Return(int64(1), nil)
+			mockGeo.On("FindNearestDrivers", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return([]domain.NearbyDriver{{ID: driverID.String(), Location: domain.Location{Lat: 40.01, Lng: -74.01}}}, nil)
+
+			_, err := svc.CreateAndDispatchOrder(context.Background(), CreateOrderInput{
+				FleetID:  uuid.New(),
+				Pickup:   domain.Location{Lat: 40.0, Lng: -74.0},
+				Dropoff:  domain.Location{Lat: 40.1, Lng: -74.1},
+				Priority: tt.priority,
+			})
+
+			assert.NoError(t, err)
+			if tt.wantAssigned {
+				mockRepo.AssertNumberOfCalls(t, "AssignDriverToOrder", 1)
+				return
+			}
+			mockRepo.AssertNotCalled(t, "AssignDriverToOrder", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestDispatchService_SweepSLABreaches(t *testing.T) {
+	mockRepo := new(MockQuerier)
+	svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+
+	deliverBy := pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+	mockRepo.On("MarkSLABreaches", mock.Anything).Return([]postgres.MarkSLABreachesRow{
+		{ID: uuid.New(), FleetID: uuid.New(), Status: postgres.OrderStatusPending, Priority: postgres.OrderPriorityExpress, DeliverBy: deliverBy},
+		{ID: uuid.New(), FleetID: uuid.New(), DriverID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Status: postgres.OrderStatusPickedUp, DeliverBy: deliverBy},
+	}, nil)
+
+	breached, err := svc.SweepSLABreaches(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, breached)
+}
+
+func TestDispatchService_CreateAndDispatchOrder_ReservationKeepsNearestDriver(t *testing.T) {
+	mockRepo := new(MockQuerier)
+	mockGeo := new(MockGeoFinder)
+	svc := NewDispatchService(mockRepo, mockGeo, &websocket.Hub{})
+	policy := DefaultSLAPolicy
+	policy.ReservedDrivers = 1
+	svc.SetSLAPolicy(policy)
+
+	nearestID, fartherID := uuid.New(), uuid.New()
+	mockRepo.On("CheckServiceArea", mock.Anything, mock.Anything).Return(postgres.CheckServiceAreaRow{}, nil)
+	mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateOrder", mock.Anything, mock.Anything).Return(postgres.CreateOrderRow{ID: uuid.New()}, nil)
+	mockRepo.On("CreateOrderStop", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateOrderFareLine", mock.Anything, mock.Anything).Return(nil)
+	for _, id := range []uuid.UUID{nearestID, fartherID} {
+		mockRepo.On("GetDriver", mock.Anything, id).Return(postgres.GetDriverRow{ID: id, Status: postgres.DriverStatusIdle}, nil)
+		mockRepo.On("GetDriverLoad", mock.Anything, id).Return(postgres.GetDriverLoadRow{}, nil)
+		mockRepo.On("LockDriverStatus", mock.Anything, id).Return(postgres.DriverStatusIdle, nil)
+	}
+	mockRepo.On("SetDriverStatus", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("UpdateOrderStopSequences", mock.Anything, mock.Anything).Return(nil)
+	var assigned postgres.AssignDriverToOrderParams
+	mockRepo.On("AssignDriverToOrder", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		assigned = args.Get(1).(postgres.AssignDriverToOrderParams)
+	}).Return(int64(1), nil)
+	mockGeo.On("FindNearestDrivers", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]domain.NearbyDriver{
+		{ID: nearestID.String(), Location: domain.Location{Lat: 40.01, Lng: -74.01}},
+		{ID: fartherID.String(), Location: domain.Location{Lat: 40.03, Lng: -74.03}},
+	}, nil)
+
+	_, err := svc.CreateAndDispatchOrder(context.Background(), CreateOrderInput{
+		FleetID: uuid.New(),
+		Pickup:  domain.Location{Lat: 40.0, Lng: -74.0},
+		Dropoff: domain.Location{Lat: 40.1, Lng: -74.1},
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "AssignDriverToOrder", 1)
+	assert.Equal(t, nearestID, uuid.UUID(assigned.DriverID.Bytes), "the standard order goes to the nearest driver")
+}
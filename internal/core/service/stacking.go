@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+// StackingPolicy controls whether a new order may be offered to a driver who
+// is already en route with others. A stacked order is only offered when it
+// fits the driver's capacity, they carry fewer than MaxOrders and fitting its
+// stops into their route adds at most MaxDetour.
+type StackingPolicy struct {
+	Enabled   bool
+	MaxDetour time.Duration
+	MaxOrders int
+}
+
+var DefaultStackingPolicy = StackingPolicy{
+	MaxDetour: 10 * time.Minute,
+	MaxOrders: 3,
+}
+
+func (s *DispatchService) SetStackingPolicy(policy StackingPolicy) {
+	s.stackingPolicy = policy
+}
+
+// errWindowMissed means no route lets the driver reach every stop within
+// its time window.
+var errWindowMissed = errors.New("stop time window cannot be met")
+
+// errDriverChanged means a driver's status or orders changed between their
+// route being planned and the order being assigned to them.
+var errDriverChanged = errors.New("driver changed since their route was planned")
+
+// maxAssignAttempts caps how many times one dispatch plans again after the
+// driver it chose changed under it.
+const maxAssignAttempts = 3
+
+// routeStop is one stop in a driver's route across their orders.
+type routeStop struct {
+	OrderID  uuid.UUID          `json:"order_id"`
+	Position int                `json:"position"`
+	Kind     domain.StopKind    `json:"kind"`
+	Location domain.Location    `json:"location"`
+	Window   *domain.TimeWindow `json:"window,omitempty"`
+
+	// arrived stops are where the driver is; nothing is planned before them
+	arrived bool
+}
+
+// driverPlan is the route a candidate driver would follow with the new
+// order. Timed plans were routed stop by stop and carry the time until the
+// driver is done at the order's first and last stop, waits included.
+type driverPlan struct {
+	Stops []routeStop
+	// the driver's status and order count the plan was made for
+	DriverStatus postgres.DriverStatus
+	ActiveOrders int32
+
+	Stacked    bool
+	Timed      bool
+	Detour     time.Duration
+	PickupETA  time.Duration
+	DropoffETA time.Duration
+}
+
+// planDriver decides whether the candidate can take the new order and, if
+// so, the route they would follow. Idle drivers only need room for the load
+// and to make the order's time windows; en-route drivers also need stacking
+// to allow it.
+func (s *DispatchService) planDriver(ctx context.Context, candidate rankedDriver, status postgres.DriverStatus, orderID uuid.UUID, stops []domain.Stop, load domain.Load, now time.Time) (*driverPlan, bool) {
+	stacked := status == postgres.DriverStatusEnRoute
+	if status != postgres.DriverStatusIdle && !(stacked && s.stackingPolicy.Enabled) {
+		return nil, false
+	}
+
+	driverUUID, _ := uuid.Parse(candidate.ID)
+	current, err := s.store.GetDriverLoad(ctx, driverUUID)
+	if err != nil {
+		return nil, false
+	}
+	if !capacityOf(current).Fits(loadOf(current).Add(load)) {
+		return nil, false
+	}
+	planned := func(plan *driverPlan) (*driverPlan, bool) {
+		plan.DriverStatus, plan.ActiveOrders = status, current.ActiveOrders
+		return plan, true
+	}
+
+	added := make([]routeStop, len(stops))
+	windowed := false
+	for i, stop := range stops {
+		added[i] = routeStop{OrderID: orderID, Position: stop.Position, Kind: stop.Kind, Location: stop.Location, Window: stop.Window}
+		windowed = windowed || stop.Window != nil
+	}
+
+	if !stacked {
+		if !windowed {
+			return planned(&driverPlan{Stops: added})
+		}
+		plan, err := s.insertStops(ctx, candidate.Location, now, nil, added)
+		if err != nil {
+			return nil, false
+		}
+		return planned(plan)
+	}
+	if int(current.ActiveOrders) >= s.stackingPolicy.MaxOrders {
+		return nil, false
+	}
+
+	route, err := pendingRoute(ctx, s.store, driverUUID)
+	if err != nil {
+		return nil, false
+	}
+
+	plan, err := s.insertStops(ctx, candidate.Location, now, route, added)
+	if err != nil || plan.Detour > s.stackingPolicy.MaxDetour {
+		return nil, false
+	}
+
+	return planned(plan)
+}
+
+// insertStops fits the new order's stops into a driver's route starting at
+// now. Each stop is placed, in order, where it adds the least time after the
+// one before it without making a stop miss its time window, and the existing
+// stops keep their sequence. A driver early at a stop waits for its window
+// to open.
+func (s *DispatchService) insertStops(ctx context.Context, from domain.Location, now time.Time, route, added []routeStop) (*driverPlan, error) {
+	legs := make(map[[2]domain.Location]time.Duration)
+	leg := func(a, b domain.Location) (time.Duration, error) {
+		if d, ok := legs[[2]domain.Location{a, b}]; ok {
+			return d, nil
+		}
+		r, err := s.router.Route(ctx, a, b)
+		if err != nil {
+			return 0, err
+		}
+		legs[[2]domain.Location{a, b}] = r.Duration
+		return r.Duration, nil
+	}
+	// arrivals returns how long until each stop of the route can be served
+	// in turn, and whether any stop would be reached after its window closed
+	// that was not already late on the driver's route
+	late := make(map[routeStop]bool)
+	arrivals := func(stops []routeStop) ([]time.Duration, bool, error) {
+		at := make([]time.Duration, len(stops))
+		var total time.Duration
+		missed := false
+		prev := from
+		for i, stop := range stops {
+			d, err := leg(prev, stop.Location)
+			if err != nil {
+				return nil, false, err
+			}
+			served, ok := stop.Window.Serve(now.Add(total + d))
+			missed = missed || (!ok && !late[stop])
+			total = served.Sub(now)
+			at[i] = total
+			prev = stop.Location
+		}
+		return at, missed, nil
+	}
+	duration := func(stops []routeStop) (time.Duration, bool, error) {
+		at, missed, err := arrivals(stops)
+		if err != nil || len(at) == 0 {
+			return 0, missed, err
+		}
+		return at[len(at)-1], missed, nil
+	}
+
+	at, _, err := arrivals(route)
+	if err != nil {
+		return nil, err
+	}
+	var before time.Duration
+	for i, stop := range route {
+		if _, ok := stop.Window.Serve(now.Add(at[i])); !ok {
+			late[stop] = true
+		}
+		before = at[i]
+	}
+
+	earliest := 0
+	for i, stop := range route {
+		if stop.arrived {
+			earliest = i + 1
+		}
+	}
+
+	plan := route
+	positions := make([]int, len(added))
+	for i, stop := range added {
+		var best []routeStop
+		var bestDuration time.Duration
+		for at := earliest; at <= len(plan); at++ {
+			candidate := make([]routeStop, 0, len(plan)+1)
+			candidate = append(candidate, plan[:at]...)
+			candidate = append(candidate, stop)
+			candidate = append(candidate, plan[at:]...)
+
+			d, missed, err := duration(candidate)
+			if err != nil {
+				return nil, err
+			}
+			if !missed && (best == nil || d < bestDuration) {
+				best, bestDuration, positions[i] = candidate, d, at
+			}
+		}
+		if best == nil {
+			return nil, errWindowMissed
+		}
+		plan, earliest = best, positions[i]+1
+	}
+
+	at, _, err = arrivals(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	return &driverPlan{
+		Stops:      plan,
+		Stacked:    len(route) > 0,
+		Timed:      true,
+		Detour:     at[len(at)-1] - before,
+		PickupETA:  at[positions[0]],
+		DropoffETA: at[positions[len(positions)-1]],
+	}, nil
+}
+
+// pendingRoute returns the stops the driver has yet to serve across their
+// orders, in the sequence they will visit them.
+func pendingRoute(ctx context.Context, q postgres.Querier, driverID uuid.UUID) ([]routeStop, error) {
+	rows, err := q.ListDriverPendingStops(ctx, pgtype.UUID{Bytes: driverID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	route := make([]routeStop, len(rows))
+	for i, row := range rows {
+		route[i] = routeStop{
+			OrderID:  row.OrderID,
+			Position: int(row.Position),
+			Kind:     domain.StopKind(row.Kind),
+			Location: domain.Location{Lat: row.Lat, Lng: row.Lng},
+			Window:   timeWindow(row.WindowStart, row.WindowEnd),
+			arrived:  row.Status == postgres.OrderStopStatusArrived,
+		}
+	}
+	return route, nil
+}
+
+// routeArrivals returns when a driver leaving from at now can serve each
+// stop of route in turn, waiting at those whose window has not opened.
+func (s *DispatchService) routeArrivals(ctx context.Context, from domain.Location, now time.Time, route []routeStop) ([]time.Time, error) {
+	at := make([]time.Time, len(route))
+	t, prev := now, from
+	for i, stop := range route {
+		r, err := s.router.Route(ctx, prev, stop.Location)
+		if err != nil {
+			return nil, err
+		}
+		t, _ = stop.Window.Serve(t.Add(r.Duration))
+		at[i] = t
+		prev = stop.Location
+	}
+	return at, nil
+}
+
+// nextRouteStop returns the order's next stop on the route: the lowest
+// position it has yet to serve.
+func nextRouteStop(route []routeStop, orderID uuid.UUID) *routeStop {
+	var next *routeStop
+	for i := range route {
+		if route[i].OrderID == orderID && (next == nil || route[i].Position < next.Position) {
+			next = &route[i]
+		}
+	}
+	return next
+}
+
+// saveSequence stores the order in which the driver will visit the stops.
+func saveSequence(ctx context.Context, q postgres.Querier, stops []routeStop) error {
+	params := postgres.UpdateOrderStopSequencesParams{
+		OrderIds:  make([]uuid.UUID, len(stops)),
+		Positions: make([]int32, len(stops)),
+		Sequences: make([]int32, len(stops)),
+	}
+	for i, stop := range stops {
+		params.OrderIds[i] = stop.OrderID
+		params.Positions[i] = int32(stop.Position)
+		params.Sequences[i] = int32(i)
+	}
+
+	return q.UpdateOrderStopSequences(ctx, params)
+}
+
+// releaseDriver sets the driver idle once they have no active order left,
+// and reports whether it did.
+func releaseDriver(ctx context.Context, q postgres.Querier, driverID uuid.UUID) (bool, error) {
+	active, err := q.CountActiveOrdersByDriver(ctx, pgtype.UUID{Bytes: driverID, Valid: true})
+	if err != nil {
+		return false, err
+	}
+	if active > 0 {
+		return false, nil
+	}
+
+	return true, q.SetDriverStatus(ctx, postgres.SetDriverStatusParams{
+		ID:     driverID,
+		Status: postgres.DriverStatusIdle,
+	})
+}
+
+// timeWindow returns the stored window of a stop, or nil when it has none.
+func timeWindow(start, end pgtype.Timestamptz) *domain.TimeWindow {
+	if !start.Valid && !end.Valid {
+		return nil
+	}
+	return &domain.TimeWindow{Start: start.Time, End: end.Time}
+}
+
+func capacityOf(row postgres.GetDriverLoadRow) domain.Capacity {
+	return domain.Capacity{
+		Parcels:     int(row.CapacityParcels.Int32),
+		WeightGrams: int(row.CapacityWeightGrams.Int32),
+		VolumeCm3:   int(row.CapacityVolumeCm3.Int32),
+		ColdChain:   row.ColdChain,
+		MaxLengthCm: int(row.MaxItemLengthCm.Int32),
+	}
+}
+
+func loadOf(row postgres.GetDriverLoadRow) domain.Load {
+	return domain.Load{
+		Parcels:     int(row.Parcels),
+		WeightGrams: int(row.WeightGrams),
+		VolumeCm3:   int(row.VolumeCm3),
+	}
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+// along returns a point on an east-west street, km kilometres east of the
+// driver.
+func along(km float64) domain.Location {
+	return domain.Location{Lat: 10.77, Lng: 106.70 + km*0.00915}
+}
+
+func TestDispatchService_InsertStops(t *testing.T) {
+	svc := NewDispatchService(new(MockQuerier), new(MockGeoFinder), &websocket.Hub{})
+	current, added := uuid.New(), uuid.New()
+
+	t.Run("On The Way", func(t *testing.T) {
+		route := []routeStop{{OrderID: current, Position: 1, Kind: domain.StopKindDropoff, Location: along(5)}}
+		plan, err := svc.insertStops(context.Background(), along(0), time.Now(), route, []routeStop{
+			{OrderID: added, Position: 0, Kind: domain.StopKindPickup, Location: along(1)},
+			{OrderID: added, Position: 1, Kind: domain.StopKindDropoff, Location: along(4)},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []domain.Location{along(1), along(4), along(5)}, planLocations(plan))
+		assert.True(t, plan.Stacked)
+		assert.Less(t, plan.Detour, time.Minute)
+		assert.Less(t, plan.PickupETA, plan.DropoffETA)
+	})
+
+	t.Run("Nothing Before An Arrived Stop", func(t *testing.T) {
+		route := []routeStop{
+			{OrderID: current, Position: 0, Kind: domain.StopKindPickup, Location: along(2), arrived: true},
+			{OrderID: current, Position: 1, Kind: domain.StopKindDropoff, Location: along(5)},
+		}
+		plan, err := svc.insertStops(context.Background(), along(2), time.Now(), route, []routeStop{
+			{OrderID: added, Position: 0, Kind: domain.StopKindPickup, Location: along(1)},
+			{OrderID: added, Position: 1, Kind: domain.StopKindDropoff, Location: along(3)},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []domain.Location{along(2), along(1), along(3), along(5)}, planLocations(plan))
+		assert.Greater(t, plan.Detour, time.Minute)
+	})
+
+	t.Run("Waits For A Window", func(t *testing.T) {
+		now := time.Now()
+		plan, err := svc.insertStops(context.Background(), along(0), now, nil, []routeStop{
+			{OrderID: added, Position: 0, Kind: domain.StopKindPickup, Location: along(1),
+				Window: &domain.TimeWindow{Start: now.Add(30 * time.Minute)}},
+			{OrderID: added, Position: 1, Kind: domain.StopKindDropoff, Location: along(2)},
+		})
+		require.NoError(t, err)
+
+		assert.False(t, plan.Stacked)
+		assert.Equal(t, 30*time.Minute, plan.PickupETA)
+		assert.Greater(t, plan.DropoffETA, 30*time.Minute)
+	})
+
+	t.Run("Window Missed", func(t *testing.T) {
+		now := time.Now()
+		_, err := svc.insertStops(context.Background(), along(0), now, nil, []routeStop{
+			{OrderID: added, Position: 0, Kind: domain.StopKindPickup, Location: along(1)},
+			{OrderID: added, Position: 1, Kind: domain.StopKindDropoff, Location: along(40),
+				Window: &domain.TimeWindow{End: now.Add(5 * time.Minute)}},
+		})
+
+		assert.ErrorIs(t, err, errWindowMissed)
+	})
+
+	t.Run("Keeps Another Order In Its Window", func(t *testing.T) {
+		now := time.Now()
+		route := []routeStop{{OrderID: current, Position: 1, Kind: domain.StopKindDropoff, Location: along(1)}}
+		direct, err := svc.insertStops(context.Background(), along(0), now, nil, route)
+		require.NoError(t, err)
+		route[0].Window = &domain.TimeWindow{End: now.Add(direct.DropoffETA + time.Second)}
+
+		plan, err := svc.insertStops(context.Background(), along(0), now, route, []routeStop{
+			{OrderID: added, Position: 0, Kind: domain.StopKindPickup, Location: along(-1)},
+			{OrderID: added, Position: 1, Kind: domain.StopKindDropoff, Location: along(-2)},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []domain.Location{along(1), along(-1), along(-2)}, planLocations(plan))
+	})
+}
+
+func planLocations(plan *driverPlan) []domain.Location {
+	locations := make([]domain.Location, len(plan.Stops))
+	for i, stop := range plan.Stops {
+		locations[i] = stop.Location
+	}
+	return locations
+}
+
+func TestDispatchService_PlanDriver(t *testing.T) {
+	driverID := uuid.New()
+	candidate := rankedDriver{NearbyDriver: domain.NearbyDriver{ID: driverID.String(), Location: along(0)}}
+	stops := []domain.Stop{
+		{Position: 0, Kind: domain.StopKindPickup, Location: along(1)},
+		{Position: 1, Kind: domain.StopKindDropoff, Location: along(4)},
+	}
+	pending := []postgres.ListDriverPendingStopsRow{{
+		OrderID: uuid.New(), Position: 1, Kind: postgres.OrderStopKindDropoff,
+		Lat: along(5).Lat, Lng: along(5).Lng, Status: postgres.OrderStopStatusPending,
+	}}
+	enabled := StackingPolicy{Enabled: true, MaxDetour: 5 * time.Minute, MaxOrders: 2}
+
+	tests := []struct {
+		name    string
+		status  postgres.DriverStatus
+		policy  StackingPolicy
+		load    postgres.GetDriverLoadRow
+		pending []postgres.ListDriverPendingStopsRow
+		want    bool
+		stacked bool
+	}{
+		{name: "Idle", status: postgres.DriverStatusIdle, policy: DefaultStackingPolicy, want: true},
+		{
+			name:   "Idle Without Room",
+			status: postgres.DriverStatusIdle,
+			policy: DefaultStackingPolicy,
+			load:   postgres.GetDriverLoadRow{CapacityWeightGrams: pgtype.Int4{Int32: 1000, Valid: true}},
+		},
+		{name: "En Route Stacking Disabled", status: postgres.DriverStatusEnRoute, policy: DefaultStackingPolicy},
+		{
+			name:    "En Route Stacked",
+			status:  postgres.DriverStatusEnRoute,
+			policy:  enabled,
+			load:    postgres.GetDriverLoadRow{ActiveOrders: 1, Parcels: 1},
+			pending: pending,
+			want:    true,
+			stacked: true,
+		},
+		{
+			name:   "En Route At Max Orders",
+			status: postgres.DriverStatusEnRoute,
+			policy: enabled,
+			load:   postgres.GetDriverLoadRow{ActiveOrders: 2, Parcels: 2},
+		},
+		{
+			name:   "En Route Without Room",
+			status: postgres.DriverStatusEnRoute,
+			policy: enabled,
+			load: postgres.GetDriverLoadRow{
+				CapacityParcels: pgtype.Int4{Int32: 1, Valid: true}, ActiveOrders: 1, Parcels: 1,
+			},
+		},
+		{
+			name:   "En Route Detour Too Long",
+			status: postgres.DriverStatusEnRoute,
+			policy: enabled,
+			load:   postgres.GetDriverLoadRow{ActiveOrders: 1, Parcels: 1},
+			pending: []postgres.ListDriverPendingStopsRow{{
+				OrderID: uuid.New(), Position: 1, Kind: postgres.OrderStopKindDropoff,
+				Lat: along(-5).Lat, Lng: along(-5).Lng, Status: postgres.OrderStopStatusPending,
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockQuerier)
+			mockRepo.On("GetDriverLoad", mock.Anything, driverID).Return(tt.load, nil)
+			mockRepo.On("ListDriverPendingStops", mock.Anything, mock.Anything).Return(tt.pending, nil)
+
+			svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+			svc.SetStackingPolicy(tt.policy)
+
+			plan, ok := svc.planDriver(context.Background(), candidate, tt.status, uuid.New(), stops,
+				domain.Load{Parcels: 1, WeightGrams: 2000}, time.Now())
+
+			assert.Equal(t, tt.want, ok)
+			if tt.want {
+				assert.Equal(t, tt.stacked, plan.Stacked)
+			}
+		})
+	}
+}
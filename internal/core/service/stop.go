@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+func isPickup(stop domain.Stop) bool  { return stop.Kind == domain.StopKindPickup }
+func isDropoff(stop domain.Stop) bool { return stop.Kind == domain.StopKindDropoff }
+
+// ArriveAtStop records the driver's arrival at the stop at position, which
+// must be the order's next one. The arrival is checked against their live
+// position as the geofence policy says.
+func (s *DispatchService) ArriveAtStop(ctx context.Context, driverID uuid.UUID, orderID uuid.UUID, position int) error {
+	return s.advanceStop(ctx, driverID, orderID, atPosition(position), false, true)
+}
+
+// CompleteStop completes the stop at position, which must be the order's
+// next one, once its time window has opened. Completing the last stop
+// delivers the order, once the proof of delivery its fleet requires has
+// been collected.
+func (s *DispatchService) CompleteStop(ctx context.Context, driverID uuid.UUID, orderID uuid.UUID, position int) error {
+	return s.advanceStop(ctx, driverID, orderID, atPosition(position), true, false)
+}
+
+func atPosition(position int) func(domain.Stop) bool {
+	return func(stop domain.Stop) bool { return stop.Position == position }
+}
+
+// advanceStop arrives at or completes the order's next stop if match accepts
+// it. The order follows its stops: arriving at the first moves it to arrived,
+// completing the first to picked_up and completing the last to delivered,
+// or to returned when it is a return stop, after which the driver goes idle
+// unless they carry other orders. A driver arriving before the stop's window
+// opens is told to wait, and one arriving after it closed has the stop
+// recorded as late.
+func (s *DispatchService) advanceStop(ctx context.Context, driverID uuid.UUID, orderID uuid.UUID, match func(domain.Stop) bool, complete, checkArrival bool) error {
+	stops, err := s.listStops(ctx, s.store, orderID)
+	if err != nil {
+		return err
+	}
+	next := domain.NextStop(stops)
+	if next == nil || !match(*next) {
+		return domain.ErrInvalidTransition
+	}
+	last := next.Position == stops[len(stops)-1].Position
+	now := time.Now()
+	if complete && next.Window != nil && now.Before(next.Window.Start) {
+		return domain.ErrStopWindowNotOpen
+	}
+
+	if checkArrival && !complete {
+		if err := s.checkManualArrival(ctx, driverID, next.Location); err != nil {
+			return err
+		}
+	}
+
+	driver := pgtype.UUID{Bytes: driverID, Valid: true}
+	status := domain.OrderStatusPickedUp
+	var idle bool
+	var missedDeadline time.Time
+	if err := s.store.ExecTx(ctx, func(q postgres.Querier) error {
+		if !complete {
+			rows, err := q.MarkOrderStopArrived(ctx, postgres.MarkOrderStopArrivedParams{
+				OrderID: orderID, Position: int32(next.Position), DriverID: driver,
+			})
+			if err != nil {
+				return err
+			}
+			if rows == 0 {
+				return domain.ErrInvalidTransition
+			}
+
+			if next.Position == 0 {
+				status = domain.OrderStatusArrived
+				return markOrder(q.MarkOrderArrived(ctx, postgres.MarkOrderArrivedParams{ID: orderID, DriverID: driver}))
+			}
+			return nil
+		}
+
+		rows, err := q.MarkOrderStopCompleted(ctx, postgres.MarkOrderStopCompletedParams{
+			OrderID: orderID, Position: int32(next.Position), DriverID: driver,
+		})
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return domain.ErrInvalidTransition
+		}
+
+		if next.Position == 0 {
+			// the driver may complete the first stop without reporting
+			// arrival first, in which case the order is still assigned
+			if _, err := q.MarkOrderArrived(ctx, postgres.MarkOrderArrivedParams{ID: orderID, DriverID: driver}); err != nil {
+				return err
+			}
+			if err := markOrder(q.MarkOrderPickedUp(ctx, postgres.MarkOrderPickedUpParams{ID: orderID, DriverID: driver})); err != nil {
+				return err
+			}
+		}
+
+		if !last {
+			return nil
+		}
+
+		if next.Kind == domain.StopKindReturn {
+			status = domain.OrderStatusReturned
+			if err := markOrder(q.MarkOrderReturned(ctx, postgres.MarkOrderReturnedParams{ID: orderID, DriverID: driver})); err != nil {
+				return err
+			}
+			if err := recordDriverEarning(ctx, q, driverID, orderID); err != nil {
+				return err
+			}
+			idle, err = releaseDriver(ctx, q, driverID)
+			return err
+		}
+
+		status = domain.OrderStatusDelivered
+		if err := checkProof(ctx, q, orderID); err != nil {
+			return err
+		}
+		if err := markOrder(q.MarkOrderDelivered(ctx, postgres.MarkOrderDeliveredParams{ID: orderID, DriverID: driver})); err != nil {
+			return err
+		}
+		if err := recordDriverEarning(ctx, q, driverID, orderID); err != nil {
+			return err
+		}
+		// deadlines missed since the last breach sweep are recorded here
+		deliverBy, err := q.MarkLateDelivery(ctx, orderID)
+		switch {
+		case err == nil:
+			missedDeadline = deliverBy.Time
+		case !errors.Is(err, pgx.ErrNoRows):
+			return err
+		}
+		idle, err = releaseDriver(ctx, q, driverID)
+		return err
+	}); err != nil {
+		return err
+	}
+	s.forgetActiveOrder(driverID)
+	s.forgetArrivalPrompts(orderID)
+
+	if complete {
+		next.Status = domain.StopStatusCompleted
+	} else {
+		next.Status = domain.StopStatusArrived
+	}
+	reached := next.ArrivedAt.IsZero()
+	if reached {
+		next.ArrivedAt = now
+	}
+	next.Late = next.Window.Late(next.ArrivedAt)
+
+	fleetID := s.driverFleet(ctx, driverID)
+	if idle {
+		s.publishDriverStatus(fleetID, driverID, postgres.DriverStatusIdle)
+	}
+	s.publishStatus(fleetID, orderID, status, map[string]any{"stop": next.WithoutAddress()})
+	if !complete && next.Window != nil && now.Before(next.Window.Start) {
+		s.hub.SendToDriver(driverID.String(), map[string]any{
+			"event":      "STOP_WAIT",
+			"order_id":   orderID,
+			"position":   next.Position,
+			"wait_until": next.Window.Start,
+		})
+	}
+	if next.Late && reached {
+		s.publishStopLate(fleetID, driverID.String(), orderID, *next)
+	}
+	if !missedDeadline.IsZero() {
+		s.publishSLABreach(fleetID, driverID.String(), orderID, status, missedDeadline)
+	}
+
+	return nil
+}
+
+// publishStopLate announces a stop the driver reached after its window
+// closed, once, when they reach it.
+func (s *DispatchService) publishStopLate(fleetID, driverID string, orderID uuid.UUID, stop domain.Stop) {
+	if fleetID == "" {
+		return
+	}
+	s.hub.PublishOps(websocket.OpsEvent{
+		Event:    websocket.EventOrderStopLate,
+		FleetID:  fleetID,
+		DriverID: driverID,
+		OrderID:  orderID.String(),
+		Location: &stop.Location,
+		Data: map[string]any{
+			"position":   stop.Position,
+			"window_end": stop.Window.End,
+			"arrived_at": stop.ArrivedAt,
+		},
+	})
+}
+
+// markOrder turns an order transition that changed no row into
+// ErrInvalidTransition.
+func markOrder(rows int64, err error) error {
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrInvalidTransition
+	}
+	return nil
+}
+
+func (s *DispatchService) listStops(ctx context.Context, q postgres.Querier, orderID uuid.UUID) ([]domain.Stop, error) {
+	rows, err := q.ListOrderStops(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	stops := make([]domain.Stop, len(rows))
+	for i, row := range rows {
+		stops[i] = domain.Stop{
+			Position:    int(row.Position),
+			Kind:        domain.StopKind(row.Kind),
+			Location:    domain.Location{Lat: row.Lat, Lng: row.Lng},
+			Status:      domain.StopStatus(row.Status),
+			Window:      timeWindow(row.WindowStart, row.WindowEnd),
+			ArrivedAt:   row.ArrivedAt.Time,
+			CompletedAt: row.CompletedAt.Time,
+			Late:        row.Late,
+		}
+		if row.AddressLine1 != "" || row.City != "" {
+			stops[i].Address = &domain.Address{
+				Line1:        row.AddressLine1,
+				Line2:        row.AddressLine2,
+				City:         row.City,
+				Postcode:     row.Postcode,
+				ContactName:  row.ContactName,
+				ContactPhone: row.ContactPhone,
+				AccessNotes:  row.AccessNotes,
+				DoorCode:     row.DoorCode,
+			}
+		}
+	}
+
+	return stops, nil
+}
+
+// createStop stores one stop of the order with its window and address.
+func createStop(ctx context.Context, q postgres.Querier, orderID uuid.UUID, stop domain.Stop) error {
+	params := postgres.CreateOrderStopParams{
+		OrderID:  orderID,
+		Position: int32(stop.Position),
+		Kind:     postgres.OrderStopKind(stop.Kind),
+		Lng:      stop.Location.Lng,
+		Lat:      stop.Location.Lat,
+	}
+	if stop.Window != nil {
+		params.WindowStart = timestamptz(stop.Window.Start)
+		params.WindowEnd = timestamptz(stop.Window.End)
+	}
+	if a := stop.Address; a != nil {
+		params.AddressLine1 = a.Line1
+		params.AddressLine2 = a.Line2
+		params.City = a.City
+		params.Postcode = a.Postcode
+		params.ContactName = a.ContactName
+		params.ContactPhone = a.ContactPhone
+		params.AccessNotes = a.AccessNotes
+		params.DoorCode = a.DoorCode
+	}
+	return q.CreateOrderStop(ctx, params)
+}
+
+// orderStops returns the stops of a new order: the given ones, or its pickup
+// and dropoff when none are, with their time windows checked at now and
+// their addresses validated.
+func orderStops(input CreateOrderInput, now time.Time) ([]domain.Stop, error) {
+	stops := input.Stops
+	if len(stops) == 0 {
+		stops = []domain.Stop{
+			{Kind: domain.StopKindPickup, Location: input.Pickup},
+			{Kind: domain.StopKindDropoff, Location: input.Dropoff},
+		}
+	}
+	if err := domain.ValidateStops(stops); err != nil {
+		return nil, err
+	}
+
+	normalized := make([]domain.Stop, len(stops))
+	for i, stop := range stops {
+		normalized[i] = domain.Stop{
+			Position: i, Kind: stop.Kind, Location: stop.Location, Status: domain.StopStatusPending,
+			Window: stop.Window, Address: stop.Address,
+		}
+	}
+	first, last := &normalized[0], &normalized[len(normalized)-1]
+	if first.Window == nil {
+		first.Window = input.PickupWindow
+	}
+	if first.Address == nil {
+		first.Address = input.PickupAddress
+	}
+	if last.Window == nil {
+		last.Window = input.DropoffWindow
+	}
+	if last.Address == nil {
+		last.Address = input.DropoffAddress
+	}
+	for _, stop := range normalized {
+		if stop.Window != nil {
+			if err := stop.Window.Validate(now); err != nil {
+				return nil, err
+			}
+		}
+		if stop.Address != nil {
+			if err := stop.Address.Validate(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return normalized, nil
+}
+
+// routeStops returns the road route through every stop in order.
+func (s *DispatchService) routeStops(ctx context.Context, stops []domain.Stop) (domain.Route, error) {
+	var total domain.Route
+	for i := 1; i < len(stops); i++ {
+		leg, err := s.router.Route(ctx, stops[i-1].Location, stops[i].Location)
+		if err != nil {
+			return domain.Route{}, fmt.Errorf("route to stop %d: %w", i, err)
+		}
+		total.DistanceMeters += leg.DistanceMeters
+		total.Duration += leg.Duration
+	}
+
+	return total, nil
+}
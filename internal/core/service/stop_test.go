@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+func stopRows(statuses ...postgres.OrderStopStatus) []postgres.ListOrderStopsRow {
+	rows := make([]postgres.ListOrderStopsRow, len(statuses))
+	for i, status := range statuses {
+		rows[i] = postgres.ListOrderStopsRow{
+			Position: int32(i),
+			Kind:     postgres.OrderStopKindDropoff,
+			Lat:      10.77 + float64(i)/100,
+			Lng:      106.70,
+			Status:   status,
+		}
+	}
+	rows[0].Kind = postgres.OrderStopKindPickup
+	return rows
+}
+
+func TestDispatchService_CompleteStop(t *testing.T) {
+	driverID := uuid.New()
+	orderID := uuid.New()
+
+	t.Run("Out Of Order", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		mockRepo.On("ListOrderStops", mock.Anything, orderID).Return(stopRows(
+			postgres.OrderStopStatusCompleted, postgres.OrderStopStatusPending, postgres.OrderStopStatusPending,
+		), nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		err := svc.CompleteStop(context.Background(), driverID, orderID, 2)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+		mockRepo.AssertNotCalled(t, "ExecTx", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Before The Window Opens", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		rows := stopRows(postgres.OrderStopStatusCompleted, postgres.OrderStopStatusArrived)
+		rows[1].WindowStart = pgtype.Timestamptz{Time: time.Now().Add(20 * time.Minute), Valid: true}
+		mockRepo.On("ListOrderStops", mock.Anything, orderID).Return(rows, nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		err := svc.CompleteStop(context.Background(), driverID, orderID, 1)
+
+		assert.ErrorIs(t, err, domain.ErrStopWindowNotOpen)
+		mockRepo.AssertNotCalled(t, "ExecTx", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Intermediate Dropoff", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		mockRepo.On("ListOrderStops", mock.Anything, orderID).Return(stopRows(
+			postgres.OrderStopStatusCompleted, postgres.OrderStopStatusArrived, postgres.OrderStopStatusPending,
+		), nil)
+		mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("MarkOrderStopCompleted", mock.Anything, mock.MatchedBy(func(arg postgres.MarkOrderStopCompletedParams) bool {
+			return arg.OrderID == orderID && arg.Position == 1
+		})).Return(int64(1), nil)
+		mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: uuid.New()}, nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		err := svc.CompleteStop(context.Background(), driverID, orderID, 1)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "MarkOrderDelivered", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Proof Missing", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		mockRepo.On("ListOrderStops", mock.Anything, orderID).Return(stopRows(
+			postgres.OrderStopStatusCompleted, postgres.OrderStopStatusArrived,
+		), nil)
+		mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("MarkOrderStopCompleted", mock.Anything, mock.Anything).Return(int64(1), nil)
+		mockRepo.On("GetDeliveryProofCheck", mock.Anything, orderID).Return(postgres.GetDeliveryProofCheckRow{
+			PodSignature: true, PodPin: true, RecipientName: "Lan",
+		}, nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		err := svc.CompleteOrder(context.Background(), driverID, orderID)
+
+		assert.ErrorIs(t, err, domain.ErrProofIncomplete)
+		assert.ErrorContains(t, err, "signature, pin")
+		mockRepo.AssertNotCalled(t, "MarkOrderDelivered", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Last Dropoff Delivers", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		fleetID := uuid.New()
+		mockRepo.On("ListOrderStops", mock.Anything, orderID).Return(stopRows(
+			postgres.OrderStopStatusCompleted, postgres.OrderStopStatusCompleted, postgres.OrderStopStatusPending,
+		), nil)
+		mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("MarkOrderStopCompleted", mock.Anything, mock.Anything).Return(int64(1), nil)
+		mockRepo.On("GetDeliveryProofCheck", mock.Anything, orderID).Return(postgres.GetDeliveryProofCheckRow{
+			PodPhoto: true, PhotoKey: "proofs/order/photo.jpg",
+		}, nil)
+		mockRepo.On("MarkOrderDelivered", mock.Anything, mock.Anything).Return(int64(1), nil)
+		mockRepo.On("GetOrder", mock.Anything, orderID).Return(postgres.GetOrderRow{
+			ID: orderID, FleetID: fleetID, AmountCents: 1000, Currency: domain.DefaultCurrency,
+		}, nil)
+		mockRepo.On("GetFleetPricingPolicy", mock.Anything, fleetID).Return(postgres.GetFleetPricingPolicyRow{
+			Currency: domain.DefaultCurrency, DriverSharePercent: 80, RoundingIncrement: 1,
+		}, nil)
+		mockRepo.On("CreateDriverEarning", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("CountActiveOrdersByDriver", mock.Anything, mock.Anything).Return(int64(0), nil)
+		mockRepo.On("MarkLateDelivery", mock.Anything, orderID).Return(pgtype.Timestamptz{}, pgx.ErrNoRows)
+		mockRepo.On("SetDriverStatus", mock.Anything, postgres.SetDriverStatusParams{
+			ID: driverID, Status: postgres.DriverStatusIdle,
+		}).Return(nil)
+		mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: fleetID}, nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		err := svc.CompleteOrder(context.Background(), driverID, orderID)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Return Stop Returns", func(t *testing.T) {
+		mockRepo := new(MockQuerier)
+		fleetID := uuid.New()
+		rows := stopRows(postgres.OrderStopStatusCompleted, postgres.OrderStopStatusFailed, postgres.OrderStopStatusArrived)
+		rows[2].Kind = postgres.OrderStopKindReturn
+		mockRepo.On("ListOrderStops", mock.Anything, orderID).Return(rows, nil)
+		mockRepo.On("ExecTx", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("MarkOrderStopCompleted", mock.Anything, mock.Anything).Return(int64(1), nil)
+		mockRepo.On("MarkOrderReturned", mock.Anything, mock.Anything).Return(int64(1), nil)
+		mockRepo.On("GetOrder", mock.Anything, orderID).Return(postgres.GetOrderRow{
+			ID: orderID, FleetID: fleetID, AmountCents: 1500, Currency: domain.DefaultCurrency,
+		}, nil)
+		mockRepo.On("GetFleetPricingPolicy", mock.Anything, fleetID).Return(postgres.GetFleetPricingPolicyRow{
+			Currency: domain.DefaultCurrency, DriverSharePercent: 80, RoundingIncrement: 1,
+		}, nil)
+		mockRepo.On("CreateDriverEarning", mock.Anything, mock.MatchedBy(func(arg postgres.CreateDriverEarningParams) bool {
+			return arg.AmountCents == 1200
+		})).Return(nil)
+		mockRepo.On("CountActiveOrdersByDriver", mock.Anything, mock.Anything).Return(int64(0), nil)
+		mockRepo.On("SetDriverStatus", mock.Anything, postgres.SetDriverStatusParams{
+			ID: driverID, Status: postgres.DriverStatusIdle,
+		}).Return(nil)
+		mockRepo.On("GetDriver", mock.Anything, driverID).Return(postgres.GetDriverRow{ID: driverID, FleetID: fleetID}, nil)
+
+		svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+		err := svc.CompleteStop(context.Background(), driverID, orderID, 2)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "GetDeliveryProofCheck", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "MarkLateDelivery", mock.Anything, mock.Anything)
+	})
+}
+
+func TestOrderStops(t *testing.T) {
+	pickup := domain.Location{Lat: 10.77, Lng: 106.70}
+	dropoff := domain.Location{Lat: 10.80, Lng: 106.72}
+
+	stops, err := orderStops(CreateOrderInput{Pickup: pickup, Dropoff: dropoff}, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Stop{
+		{Position: 0, Kind: domain.StopKindPickup, Location: pickup, Status: domain.StopStatusPending},
+		{Position: 1, Kind: domain.StopKindDropoff, Location: dropoff, Status: domain.StopStatusPending},
+	}, stops)
+
+	_, err = orderStops(CreateOrderInput{Stops: []domain.Stop{
+		{Kind: domain.StopKindDropoff, Location: dropoff},
+		{Kind: domain.StopKindPickup, Location: pickup},
+	}}, time.Now())
+	assert.ErrorIs(t, err, domain.ErrInvalidStops)
+
+	now := time.Now()
+	window := &domain.TimeWindow{Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)}
+	stops, err = orderStops(CreateOrderInput{Pickup: pickup, Dropoff: dropoff, DropoffWindow: window}, now)
+	assert.NoError(t, err)
+	assert.Nil(t, stops[0].Window)
+	assert.Equal(t, window, stops[1].Window)
+
+	_, err = orderStops(CreateOrderInput{
+		Pickup:        pickup,
+		Dropoff:       dropoff,
+		DropoffWindow: &domain.TimeWindow{Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)},
+	}, now)
+	assert.ErrorIs(t, err, domain.ErrInvalidTimeWindow)
+
+	address := &domain.Address{Line1: "12 Nguyen Hue", City: "Ho Chi Minh City", ContactPhone: "0912344567"}
+	stops, err = orderStops(CreateOrderInput{Pickup: pickup, Dropoff: dropoff, DropoffAddress: address}, now)
+	assert.NoError(t, err)
+	assert.Nil(t, stops[0].Address)
+	assert.Equal(t, address, stops[1].Address)
+
+	_, err = orderStops(CreateOrderInput{Pickup: pickup, Dropoff: dropoff, PickupAddress: &domain.Address{Line1: "12 Nguyen Hue"}}, now)
+	assert.ErrorIs(t, err, domain.ErrInvalidAddress)
+}
+
+func TestDispatchService_GetOrder_MasksPhonesOnceDone(t *testing.T) {
+	tests := []struct {
+		status postgres.OrderStatus
+		want   string
+	}{
+		{status: postgres.OrderStatusPickedUp, want: "0912344567"},
+		{status: postgres.OrderStatusDelivered, want: "******4567"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			orderID := uuid.New()
+			rows := stopRows(postgres.OrderStopStatusCompleted, postgres.OrderStopStatusPending)
+			rows[1].AddressLine1, rows[1].City, rows[1].ContactPhone = "12 Nguyen Hue", "Ho Chi Minh City", "0912344567"
+
+			mockRepo := new(MockQuerier)
+			mockRepo.On("GetOrder", mock.Anything, orderID).Return(postgres.GetOrderRow{
+				ID: orderID, Status: tt.status, Currency: domain.DefaultCurrency,
+			}, nil)
+			mockRepo.On("ListOrderFareLines", mock.Anything, orderID).Return([]postgres.ListOrderFareLinesRow{}, nil)
+			mockRepo.On("ListOrderItems", mock.Anything, orderID).Return([]postgres.ListOrderItemsRow{}, nil)
+			mockRepo.On("ListOrderStops", mock.Anything, orderID).Return(rows, nil)
+
+			svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+			order, err := svc.GetOrder(context.Background(), orderID)
+
+			assert.NoError(t, err)
+			assert.Nil(t, order.Stops[0].Address)
+			assert.Equal(t, tt.want, order.Stops[1].Address.ContactPhone)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+// newTrackingToken returns a random URL-safe token and the hash to store for
+// it. Only the hash is persisted, so a leaked database cannot be used to
+// follow orders.
+func newTrackingToken() (string, []byte, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashTrackingToken(token), nil
+}
+
+func hashTrackingToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// AuthorizeTracking checks a customer's tracking token and returns the order
+// it grants access to.
+func (s *DispatchService) AuthorizeTracking(ctx context.Context, orderID uuid.UUID, token string) (*domain.Order, error) {
+	hash, err := s.store.GetOrderTrackingTokenHash(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	if token == "" || len(hash) == 0 || subtle.ConstantTimeCompare(hashTrackingToken(token), hash) != 1 {
+		return nil, domain.ErrInvalidTrackingToken
+	}
+
+	return s.GetOrder(ctx, orderID)
+}
+
+// CreateTrackingLink issues a shareable link token for the order, valid for
+// ttl (the default when zero, capped at the maximum). The token is only
+// returned here.
+func (s *DispatchService) CreateTrackingLink(ctx context.Context, orderID uuid.UUID, ttl time.Duration) (*domain.TrackingLink, string, error) {
+	if _, err := s.store.GetOrder(ctx, orderID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, "", domain.ErrOrderNotFound
+		}
+		return nil, "", err
+	}
+
+	if ttl <= 0 {
+		ttl = domain.DefaultTrackingLinkTTL
+	}
+	ttl = min(ttl, domain.MaxTrackingLinkTTL)
+
+	token, hash, err := newTrackingToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	row, err := s.store.CreateTrackingLink(ctx, postgres.CreateTrackingLinkParams{
+		OrderID:   orderID,
+		TokenHash: hash,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &domain.TrackingLink{
+		ID:        row.ID.String(),
+		OrderID:   orderID.String(),
+		ExpiresAt: expiresAt,
+		CreatedAt: row.CreatedAt,
+	}, token, nil
+}
+
+func (s *DispatchService) RevokeTrackingLink(ctx context.Context, orderID, linkID uuid.UUID) error {
+	rows, err := s.store.RevokeTrackingLink(ctx, postgres.RevokeTrackingLinkParams{
+		ID:      linkID,
+		OrderID: orderID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrTrackingLinkNotFound
+	}
+
+	return nil
+}
+
+// GetPublicTracking resolves a tracking link token to the order's public
+// view. The driver position is only included while the order is active and
+// is coarsened by domain.PublicDriverLocation.
+func (s *DispatchService) GetPublicTracking(ctx context.Context, token string) (*domain.PublicTracking, error) {
+	row, err := s.store.GetTrackingLinkByTokenHash(ctx, hashTrackingToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTrackingLinkNotFound
+		}
+		return nil, err
+	}
+
+	link := domain.TrackingLink{ExpiresAt: row.ExpiresAt, RevokedAt: row.RevokedAt.Time}
+	if !link.ValidAt(time.Now()) {
+		return nil, domain.ErrTrackingLinkExpired
+	}
+
+	order, err := s.GetOrder(ctx, row.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &domain.PublicTracking{
+		OrderID:       order.ID,
+		Status:        order.Status,
+		LinkExpiresAt: row.ExpiresAt,
+	}
+	if !order.Status.IsActive() {
+		return view, nil
+	}
+
+	view.ETA = order.ETA
+	if order.DriverID != "" {
+		loc, err := s.geo.GetDriverLocation(ctx, order.DriverID)
+		switch {
+		case err == nil:
+			public := domain.PublicDriverLocation(order.Status, loc)
+			view.DriverLocation = &public
+		case !errors.Is(err, domain.ErrDriverLocationUnknown):
+			log.Println("redis error:", err)
+		}
+	}
+
+	return view, nil
+}
+
+// publishStatus notifies the order's trackers and its fleet's dispatchers of
+// a status change, and closes the trackers' streams once the order can no
+// longer change.
+func (s *DispatchService) publishStatus(fleetID string, orderID uuid.UUID, status domain.OrderStatus, extra map[string]any) {
+	event := map[string]any{
+		"event":    "ORDER_STATUS",
+		"order_id": orderID,
+		"status":   status,
+	}
+	for k, v := range extra {
+		event[k] = v
+	}
+	s.hub.PublishOrderEvent(orderID.String(), event)
+
+	if fleetID != "" {
+		s.hub.PublishOps(websocket.OpsEvent{
+			Event:   websocket.EventOrderStatus,
+			FleetID: fleetID,
+			OrderID: orderID.String(),
+			Status:  string(status),
+			Data:    extra,
+		})
+	}
+
+	if !status.IsActive() {
+		s.hub.CloseOrderTracking(orderID.String())
+	}
+}
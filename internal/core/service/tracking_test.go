@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/storage/postgres"
+	"github.com/vantutran2k1/flowfleet/internal/adapter/websocket"
+	"github.com/vantutran2k1/flowfleet/internal/core/domain"
+)
+
+func TestDispatchService_AuthorizeTracking(t *testing.T) {
+	token, hash, err := newTrackingToken()
+	require.NoError(t, err)
+
+	orderID := uuid.New()
+	missingID := uuid.New()
+
+	mockRepo := new(MockQuerier)
+	mockRepo.On("GetOrderTrackingTokenHash", mock.Anything, orderID).Return(hash, nil)
+	mockRepo.On("GetOrderTrackingTokenHash", mock.Anything, missingID).Return([]byte(nil), pgx.ErrNoRows)
+	mockRepo.On("GetOrder", mock.Anything, orderID).Return(postgres.GetOrderRow{
+		ID:       orderID,
+		Status:   postgres.OrderStatusAssigned,
+		Currency: domain.DefaultCurrency,
+	}, nil)
+	mockRepo.On("ListOrderFareLines", mock.Anything, orderID).Return([]postgres.ListOrderFareLinesRow{}, nil)
+	mockRepo.On("ListOrderItems", mock.Anything, orderID).Return([]postgres.ListOrderItemsRow{}, nil)
+	mockRepo.On("ListOrderStops", mock.Anything, orderID).Return([]postgres.ListOrderStopsRow{}, nil)
+
+	svc := NewDispatchService(mockRepo, new(MockGeoFinder), &websocket.Hub{})
+
+	tests := []struct {
+		name    string
+		orderID uuid.UUID
+		token   string
+		wantErr error
+	}{
+		{name: "Valid Token", orderID: orderID, token: token},
+		{name: "Wrong Token", orderID: orderID, token: token + "x", wantErr: domain.ErrInvalidTrackingToken},
+		{name: "Empty Token", orderID: orderID, token: "", wantErr: domain.ErrInvalidTrackingToken},
+		{name: "Unknown Order", orderID: missingID, token: token, wantErr: domain.ErrOrderNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := svc.AuthorizeTracking(context.Background(), tt.orderID, tt.token)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, orderID.String(), order.ID)
+			assert.Equal(t, domain.OrderStatusAssigned, order.Status)
+		})
+	}
+}
+
+func TestDispatchService_GetPublicTracking(t *testing.T) {
+	orderID := uuid.New()
+	driverID := uuid.New()
+
+	mockRepo := new(MockQuerier)
+	mockGeo := new(MockGeoFinder)
+	mockRepo.On("GetTrackingLinkByTokenHash", mock.Anything, hashTrackingToken("live")).Return(postgres.GetTrackingLinkByTokenHashRow{
+		ID: uuid.New(), OrderID: orderID, ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	mockRepo.On("GetTrackingLinkByTokenHash", mock.Anything, hashTrackingToken("expired")).Return(postgres.GetTrackingLinkByTokenHashRow{
+		ID: uuid.New(), OrderID: orderID, ExpiresAt: time.Now().Add(-time.Minute),
+	}, nil)
+	mockRepo.On("GetTrackingLinkByTokenHash", mock.Anything, hashTrackingToken("revoked")).Return(postgres.GetTrackingLinkByTokenHashRow{
+		ID: uuid.New(), OrderID: orderID, ExpiresAt: time.Now().Add(time.Hour),
+		RevokedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}, nil)
+	mockRepo.On("GetTrackingLinkByTokenHash", mock.Anything, mock.Anything).Return(postgres.GetTrackingLinkByTokenHashRow{}, pgx.ErrNoRows)
+	mockRepo.On("GetOrder", mock.Anything, orderID).Return(postgres.GetOrderRow{
+		ID:       orderID,
+		DriverID: pgtype.UUID{Bytes: driverID, Valid: true},
+		Status:   postgres.OrderStatusAssigned,
+		Currency: domain.DefaultCurrency,
+	}, nil)
+	mockRepo.On("ListOrderFareLines", mock.Anything, orderID).Return([]postgres.ListOrderFareLinesRow{}, nil)
+	mockRepo.On("ListOrderItems", mock.Anything, orderID).Return([]postgres.ListOrderItemsRow{}, nil)
+	mockRepo.On("ListOrderStops", mock.Anything, orderID).Return([]postgres.ListOrderStopsRow{}, nil)
+	mockGeo.On("GetDriverLocation", mock.Anything, driverID.String()).Return(domain.Location{Lat: 10.776543, Lng: 106.701234}, nil)
+
+	svc := NewDispatchService(mockRepo, mockGeo, &websocket.Hub{})
+
+	view, err := svc.GetPublicTracking(context.Background(), "live")
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusAssigned, view.Status)
+	assert.Equal(t, &domain.Location{Lat: 10.78, Lng: 106.70}, view.DriverLocation, "fuzzed to ~1 km before pickup")
+
+	_, err = svc.GetPublicTracking(context.Background(), "expired")
+	assert.ErrorIs(t, err, domain.ErrTrackingLinkExpired)
+
+	_, err = svc.GetPublicTracking(context.Background(), "revoked")
+	assert.ErrorIs(t, err, domain.ErrTrackingLinkExpired)
+
+	_, err = svc.GetPublicTracking(context.Background(), "unknown")
+	assert.ErrorIs(t, err, domain.ErrTrackingLinkNotFound)
+}
@@ -0,0 +1,65 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	TypeFeatureCollection = "FeatureCollection"
+	TypeFeature           = "Feature"
+	TypeLineString        = "LineString"
+	TypePolygon           = "Polygon"
+	TypeMultiPolygon      = "MultiPolygon"
+)
+
+var ErrInvalidGeoJSON = errors.New("invalid geojson")
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string          `json:"type"`
+	Properties json.RawMessage `json:"properties,omitempty"`
+	Geometry   json.RawMessage `json:"geometry"`
+}
+
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: TypeFeatureCollection, Features: features}
+}
+
+// GeometryType returns the "type" member of a raw GeoJSON geometry object.
+func GeometryType(geometry json.RawMessage) (string, error) {
+	var g struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(geometry, &g); err != nil || g.Type == "" {
+		return "", ErrInvalidGeoJSON
+	}
+	return g.Type, nil
+}
+
+// IsPolygonal reports whether the geometry is a Polygon or MultiPolygon.
+func IsPolygonal(geometry json.RawMessage) bool {
+	t, err := GeometryType(geometry)
+	return err == nil && (t == TypePolygon || t == TypeMultiPolygon)
+}
+
+// NewLineString builds a LineString geometry from [lng, lat] positions. A
+// LineString needs at least two positions, so shorter input yields null.
+func NewLineString(positions [][2]float64) json.RawMessage {
+	if len(positions) < 2 {
+		return json.RawMessage("null")
+	}
+
+	geometry, _ := json.Marshal(struct {
+		Type        string       `json:"type"`
+		Coordinates [][2]float64 `json:"coordinates"`
+	}{Type: TypeLineString, Coordinates: positions})
+	return geometry
+}
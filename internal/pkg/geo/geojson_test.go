@@ -0,0 +1,24 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLineString(t *testing.T) {
+	t.Run("Positions", func(t *testing.T) {
+		geometry := NewLineString([][2]float64{{106.70, 10.77}, {106.71, 10.78}})
+
+		assert.JSONEq(t, `{"type":"LineString","coordinates":[[106.7,10.77],[106.71,10.78]]}`, string(geometry))
+		geometryType, err := GeometryType(geometry)
+		require.NoError(t, err)
+		assert.Equal(t, TypeLineString, geometryType)
+	})
+
+	t.Run("TooFewPositions", func(t *testing.T) {
+		assert.Equal(t, "null", string(NewLineString([][2]float64{{106.70, 10.77}})))
+		assert.Equal(t, "null", string(NewLineString(nil)))
+	})
+}
@@ -0,0 +1,79 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+var ErrInvalidPolyline = errors.New("invalid polyline")
+
+// EncodePolyline encodes [lat, lng] points with the Google polyline
+// algorithm at precision 5, the format OSRM returns by default.
+func EncodePolyline(points [][2]float64) string {
+	var sb strings.Builder
+	var prevLat, prevLng int64
+	for _, p := range points {
+		lat := int64(math.Round(p[0] * 1e5))
+		lng := int64(math.Round(p[1] * 1e5))
+		encodeValue(&sb, lat-prevLat)
+		encodeValue(&sb, lng-prevLng)
+		prevLat, prevLng = lat, lng
+	}
+	return sb.String()
+}
+
+func encodeValue(sb *strings.Builder, v int64) {
+	u := v << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		sb.WriteByte(byte((0x20 | (u & 0x1f)) + 63))
+		u >>= 5
+	}
+	sb.WriteByte(byte(u + 63))
+}
+
+func DecodePolyline(encoded string) ([][2]float64, error) {
+	var points [][2]float64
+	var lat, lng int64
+	for i := 0; i < len(encoded); {
+		dLat, n, err := decodeValue(encoded[i:])
+		if err != nil {
+			return nil, err
+		}
+		i += n
+
+		dLng, n, err := decodeValue(encoded[i:])
+		if err != nil {
+			return nil, err
+		}
+		i += n
+
+		lat += dLat
+		lng += dLng
+		points = append(points, [2]float64{float64(lat) / 1e5, float64(lng) / 1e5})
+	}
+	return points, nil
+}
+
+func decodeValue(s string) (int64, int, error) {
+	var result int64
+	var shift uint
+	for i := 0; i < len(s); i++ {
+		b := int64(s[i]) - 63
+		if b < 0 {
+			return 0, 0, ErrInvalidPolyline
+		}
+		result |= (b & 0x1f) << shift
+		shift += 5
+		if b < 0x20 {
+			if result&1 != 0 {
+				return ^(result >> 1), i + 1, nil
+			}
+			return result >> 1, i + 1, nil
+		}
+	}
+	return 0, 0, ErrInvalidPolyline
+}
@@ -0,0 +1,22 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolyline(t *testing.T) {
+	points := [][2]float64{{38.5, -120.2}, {40.7, -120.95}, {43.252, -126.453}}
+	encoded := "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+
+	assert.Equal(t, encoded, EncodePolyline(points))
+
+	decoded, err := DecodePolyline(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, points, decoded)
+
+	_, err = DecodePolyline("_p~iF~ps|U_")
+	assert.ErrorIs(t, err, ErrInvalidPolyline)
+}
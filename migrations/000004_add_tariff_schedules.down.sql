@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS tariff_holidays;
+DROP TABLE IF EXISTS tariff_rules;
+DROP TABLE IF EXISTS tariff_schedules;
@@ -0,0 +1,34 @@
+CREATE TABLE tariff_schedules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    fleet_id UUID NOT NULL UNIQUE REFERENCES fleets(id) ON DELETE CASCADE,
+    time_zone TEXT NOT NULL DEFAULT 'UTC',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- days_of_week is a bitmask where bit 0 is Sunday and bit 6 is Saturday.
+-- start_minute/end_minute are minutes since local midnight; a window whose
+-- end is before its start runs past midnight into the next day.
+CREATE TABLE tariff_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    schedule_id UUID NOT NULL REFERENCES tariff_schedules(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    vehicle_type TEXT NOT NULL,
+    days_of_week SMALLINT NOT NULL DEFAULT 127 CHECK (days_of_week BETWEEN 0 AND 127),
+    holidays_only BOOLEAN NOT NULL DEFAULT FALSE,
+    start_minute INTEGER NOT NULL DEFAULT 0 CHECK (start_minute BETWEEN 0 AND 1440),
+    end_minute INTEGER NOT NULL DEFAULT 1440 CHECK (end_minute BETWEEN 0 AND 1440),
+    priority INTEGER NOT NULL DEFAULT 0,
+    base_cents INTEGER NOT NULL,
+    per_km_cents INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_tariff_rules_schedule ON tariff_rules(schedule_id);
+
+CREATE TABLE tariff_holidays (
+    schedule_id UUID NOT NULL REFERENCES tariff_schedules(id) ON DELETE CASCADE,
+    holiday_date DATE NOT NULL,
+    name TEXT NOT NULL,
+    PRIMARY KEY (schedule_id, holiday_date)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS zone_fares;
+DROP TABLE IF EXISTS pricing_zones;
@@ -0,0 +1,20 @@
+CREATE TABLE pricing_zones (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    fleet_id UUID NOT NULL REFERENCES fleets(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    surcharge_cents INTEGER NOT NULL DEFAULT 0,
+    area GEOMETRY(MULTIPOLYGON, 4326) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (fleet_id, name)
+);
+
+CREATE INDEX idx_pricing_zones_area ON pricing_zones USING GIST (area);
+
+CREATE TABLE zone_fares (
+    from_zone_id UUID NOT NULL REFERENCES pricing_zones(id) ON DELETE CASCADE,
+    to_zone_id UUID NOT NULL REFERENCES pricing_zones(id) ON DELETE CASCADE,
+    vehicle_type TEXT NOT NULL,
+    fare_cents INTEGER NOT NULL,
+    PRIMARY KEY (from_zone_id, to_zone_id, vehicle_type)
+);
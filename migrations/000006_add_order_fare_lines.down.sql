@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS order_fare_lines;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
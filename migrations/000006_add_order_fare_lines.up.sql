@@ -0,0 +1,11 @@
+ALTER TABLE orders ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD';
+
+CREATE TABLE order_fare_lines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    description TEXT NOT NULL,
+    amount_cents INTEGER NOT NULL,
+    UNIQUE (order_id, position)
+);
@@ -0,0 +1,29 @@
+CREATE TYPE promo_kind AS ENUM ('percentage', 'fixed');
+
+CREATE TABLE promo_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code TEXT NOT NULL UNIQUE,
+    fleet_id UUID REFERENCES fleets(id) ON DELETE CASCADE,
+    kind promo_kind NOT NULL,
+    value INTEGER NOT NULL CHECK (value > 0),
+    min_fare_cents INTEGER NOT NULL DEFAULT 0,
+    max_discount_cents INTEGER,
+    max_redemptions INTEGER,
+    max_per_customer INTEGER,
+    redemption_count INTEGER NOT NULL DEFAULT 0,
+    starts_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ends_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE promo_redemptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    promo_id UUID NOT NULL REFERENCES promo_codes(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+    customer_id TEXT,
+    discount_cents INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_promo_redemptions_customer ON promo_redemptions(promo_id, customer_id);
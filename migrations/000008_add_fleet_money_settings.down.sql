@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS driver_earnings;
+
+ALTER TABLE fleets
+    DROP COLUMN IF EXISTS driver_share_percent,
+    DROP COLUMN IF EXISTS minimum_fare_cents,
+    DROP COLUMN IF EXISTS rounding_mode,
+    DROP COLUMN IF EXISTS rounding_increment,
+    DROP COLUMN IF EXISTS currency;
+
+DROP TYPE IF EXISTS rounding_mode;
@@ -0,0 +1,20 @@
+CREATE TYPE rounding_mode AS ENUM ('nearest', 'up', 'down');
+
+-- Amounts are in the minor unit of the fleet currency (cents for USD, dong for VND).
+ALTER TABLE fleets
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD',
+    ADD COLUMN rounding_increment INTEGER NOT NULL DEFAULT 1 CHECK (rounding_increment > 0),
+    ADD COLUMN rounding_mode rounding_mode NOT NULL DEFAULT 'nearest',
+    ADD COLUMN minimum_fare_cents INTEGER NOT NULL DEFAULT 0 CHECK (minimum_fare_cents >= 0),
+    ADD COLUMN driver_share_percent INTEGER NOT NULL DEFAULT 80 CHECK (driver_share_percent BETWEEN 0 AND 100);
+
+CREATE TABLE driver_earnings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    driver_id UUID NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+    amount_cents INTEGER NOT NULL,
+    currency TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_driver_earnings_driver ON driver_earnings(driver_id);
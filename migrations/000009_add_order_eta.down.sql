@@ -0,0 +1,9 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS late_since,
+    DROP COLUMN IF EXISTS eta_updated_at,
+    DROP COLUMN IF EXISTS dropoff_eta_at,
+    DROP COLUMN IF EXISTS pickup_eta_at,
+    DROP COLUMN IF EXISTS promised_dropoff_at,
+    DROP COLUMN IF EXISTS promised_pickup_at;
+
+DROP INDEX IF EXISTS idx_orders_active_driver;
@@ -0,0 +1,12 @@
+-- promised_* are fixed when a driver is assigned; *_eta_at are refreshed from
+-- the driver's live position and compared against the promise.
+ALTER TABLE orders
+    ADD COLUMN promised_pickup_at TIMESTAMPTZ,
+    ADD COLUMN promised_dropoff_at TIMESTAMPTZ,
+    ADD COLUMN pickup_eta_at TIMESTAMPTZ,
+    ADD COLUMN dropoff_eta_at TIMESTAMPTZ,
+    ADD COLUMN eta_updated_at TIMESTAMPTZ,
+    ADD COLUMN late_since TIMESTAMPTZ;
+
+CREATE INDEX idx_orders_active_driver ON orders(driver_id)
+    WHERE status IN ('assigned', 'arrived', 'picked_up');
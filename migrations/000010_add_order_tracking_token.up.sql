@@ -0,0 +1,2 @@
+-- SHA-256 of the token handed to the customer when the order is created.
+ALTER TABLE orders ADD COLUMN tracking_token_hash BYTEA;
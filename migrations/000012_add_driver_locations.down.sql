@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS driver_locations;
+DROP FUNCTION IF EXISTS ensure_driver_locations_partition(DATE);
@@ -0,0 +1,25 @@
+-- Breadcrumb history of driver positions, partitioned by month so old months
+-- can be detached or dropped cheaply. Partitions are created ahead of time by
+-- ensure_driver_locations_partition, which the location writer calls.
+CREATE TABLE driver_locations (
+    driver_id UUID NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL,
+    location GEOMETRY(POINT, 4326) NOT NULL
+) PARTITION BY RANGE (recorded_at);
+
+CREATE INDEX idx_driver_locations_driver_time ON driver_locations(driver_id, recorded_at);
+
+CREATE FUNCTION ensure_driver_locations_partition(month DATE) RETURNS VOID AS $$
+DECLARE
+    start_date DATE := date_trunc('month', month)::date;
+    end_date DATE := (date_trunc('month', month) + INTERVAL '1 month')::date;
+BEGIN
+    EXECUTE format(
+        'CREATE TABLE IF NOT EXISTS %I PARTITION OF driver_locations FOR VALUES FROM (%L) TO (%L)',
+        'driver_locations_' || to_char(start_date, 'YYYYMM'), start_date, end_date
+    );
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT ensure_driver_locations_partition(CURRENT_DATE);
+SELECT ensure_driver_locations_partition((CURRENT_DATE + INTERVAL '1 month')::date);
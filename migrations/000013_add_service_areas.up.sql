@@ -0,0 +1,11 @@
+CREATE TABLE service_areas (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    fleet_id UUID NOT NULL REFERENCES fleets(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    area GEOMETRY(MULTIPOLYGON, 4326) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (fleet_id, name)
+);
+
+CREATE INDEX idx_service_areas_area ON service_areas USING GIST (area);
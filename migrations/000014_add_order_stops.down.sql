@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS order_stops;
+DROP TYPE IF EXISTS order_stop_status;
+DROP TYPE IF EXISTS order_stop_kind;
@@ -0,0 +1,30 @@
+CREATE TYPE order_stop_kind AS ENUM ('pickup', 'dropoff');
+CREATE TYPE order_stop_status AS ENUM ('pending', 'arrived', 'completed');
+
+CREATE TABLE order_stops (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    kind order_stop_kind NOT NULL,
+    location GEOMETRY(POINT, 4326) NOT NULL,
+    status order_stop_status NOT NULL DEFAULT 'pending',
+    arrived_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (order_id, position)
+);
+
+-- every existing order becomes a pickup followed by a dropoff
+INSERT INTO order_stops (order_id, position, kind, location, status)
+SELECT id, 0, 'pickup', pickup_location,
+       CASE
+           WHEN status IN ('picked_up', 'delivered') THEN 'completed'
+           WHEN status = 'arrived' THEN 'arrived'
+           ELSE 'pending'
+       END::order_stop_status
+FROM orders;
+
+INSERT INTO order_stops (order_id, position, kind, location, status)
+SELECT id, 1, 'dropoff', dropoff_location,
+       CASE WHEN status = 'delivered' THEN 'completed' ELSE 'pending' END::order_stop_status
+FROM orders;
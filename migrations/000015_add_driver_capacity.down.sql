@@ -0,0 +1,11 @@
+ALTER TABLE order_stops DROP COLUMN IF EXISTS sequence;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS volume_cm3,
+    DROP COLUMN IF EXISTS weight_grams,
+    DROP COLUMN IF EXISTS parcel_count;
+
+ALTER TABLE drivers
+    DROP COLUMN IF EXISTS capacity_volume_cm3,
+    DROP COLUMN IF EXISTS capacity_weight_grams,
+    DROP COLUMN IF EXISTS capacity_parcels;
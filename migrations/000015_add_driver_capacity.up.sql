@@ -0,0 +1,13 @@
+ALTER TABLE drivers
+    ADD COLUMN capacity_parcels INTEGER,
+    ADD COLUMN capacity_weight_grams INTEGER,
+    ADD COLUMN capacity_volume_cm3 INTEGER;
+
+ALTER TABLE orders
+    ADD COLUMN parcel_count INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN weight_grams INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN volume_cm3 INTEGER NOT NULL DEFAULT 0;
+
+-- sequence is the visiting order of a stop among all stops of its driver's
+-- active orders; NULL until the driver's route is planned
+ALTER TABLE order_stops ADD COLUMN sequence INTEGER;
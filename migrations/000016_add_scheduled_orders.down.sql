@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_orders_scheduled_pickup_at;
+
+-- the scheduled enum value cannot be dropped; orders still waiting on it are
+-- cancelled instead
+UPDATE orders SET status = 'cancelled', updated_at = NOW() WHERE status = 'scheduled';
+
+ALTER TABLE orders DROP COLUMN IF EXISTS scheduled_pickup_at;
@@ -0,0 +1,8 @@
+-- new enum values cannot be used in the transaction that adds them, so the
+-- index does not filter on the scheduled status
+ALTER TYPE order_status ADD VALUE 'scheduled';
+
+ALTER TABLE orders ADD COLUMN scheduled_pickup_at TIMESTAMPTZ;
+
+CREATE INDEX idx_orders_scheduled_pickup_at ON orders (scheduled_pickup_at)
+    WHERE scheduled_pickup_at IS NOT NULL;
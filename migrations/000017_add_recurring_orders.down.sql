@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_orders_recurring_occurrence;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS notes,
+    DROP COLUMN IF EXISTS vehicle_type,
+    DROP COLUMN IF EXISTS recurring_order_id;
+
+DROP TABLE IF EXISTS recurring_order_skips;
+DROP TABLE IF EXISTS recurring_orders;
@@ -0,0 +1,37 @@
+-- schedule is a five-field cron expression evaluated in time_zone.
+-- materialized_until is how far ahead scheduled orders have been created from
+-- the template; NULL makes the next pass start again from now.
+CREATE TABLE recurring_orders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    fleet_id UUID NOT NULL REFERENCES fleets(id) ON DELETE CASCADE,
+    customer_id TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL,
+    schedule TEXT NOT NULL,
+    time_zone TEXT NOT NULL DEFAULT 'UTC',
+    pickup_location GEOMETRY(POINT, 4326) NOT NULL,
+    dropoff_location GEOMETRY(POINT, 4326) NOT NULL,
+    vehicle_type TEXT NOT NULL DEFAULT 'BIKE',
+    notes TEXT NOT NULL DEFAULT '',
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    materialized_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_recurring_orders_fleet ON recurring_orders(fleet_id);
+
+-- skip_date is a local date, in the template's time zone, with no occurrence
+CREATE TABLE recurring_order_skips (
+    recurring_order_id UUID NOT NULL REFERENCES recurring_orders(id) ON DELETE CASCADE,
+    skip_date DATE NOT NULL,
+    PRIMARY KEY (recurring_order_id, skip_date)
+);
+
+ALTER TABLE orders
+    ADD COLUMN recurring_order_id UUID REFERENCES recurring_orders(id) ON DELETE SET NULL,
+    ADD COLUMN vehicle_type TEXT NOT NULL DEFAULT 'BIKE',
+    ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+
+-- one live order per occurrence of a template
+CREATE UNIQUE INDEX idx_orders_recurring_occurrence ON orders (recurring_order_id, scheduled_pickup_at)
+    WHERE recurring_order_id IS NOT NULL AND status <> 'cancelled';
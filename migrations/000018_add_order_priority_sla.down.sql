@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_orders_sla_breached;
+DROP INDEX IF EXISTS idx_orders_open_deliver_by;
+DROP INDEX IF EXISTS idx_orders_pending_priority;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS sla_breached_at,
+    DROP COLUMN IF EXISTS sla_at_risk_since,
+    DROP COLUMN IF EXISTS deliver_by,
+    DROP COLUMN IF EXISTS priority;
+
+DROP TYPE IF EXISTS order_priority;
@@ -0,0 +1,19 @@
+CREATE TYPE order_priority AS ENUM ('standard', 'express', 'critical');
+
+-- deliver_by is the promised delivery deadline. sla_at_risk_since is set while
+-- the predicted dropoff is past it, and sla_breached_at once it has passed
+-- without delivery.
+ALTER TABLE orders
+    ADD COLUMN priority order_priority NOT NULL DEFAULT 'standard',
+    ADD COLUMN deliver_by TIMESTAMPTZ,
+    ADD COLUMN sla_at_risk_since TIMESTAMPTZ,
+    ADD COLUMN sla_breached_at TIMESTAMPTZ;
+
+CREATE INDEX idx_orders_pending_priority ON orders(priority DESC, deliver_by, created_at)
+    WHERE status = 'pending';
+
+CREATE INDEX idx_orders_open_deliver_by ON orders(deliver_by)
+    WHERE sla_breached_at IS NULL AND status IN ('pending', 'assigned', 'arrived', 'picked_up');
+
+CREATE INDEX idx_orders_sla_breached ON orders(fleet_id, sla_breached_at)
+    WHERE sla_breached_at IS NOT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE order_stops
+    DROP COLUMN IF EXISTS late,
+    DROP COLUMN IF EXISTS window_end,
+    DROP COLUMN IF EXISTS window_start;
@@ -0,0 +1,7 @@
+-- window_start and window_end bound when the stop may be served; either may
+-- be open. late is recorded once the driver reaches the stop after its window
+-- closed.
+ALTER TABLE order_stops
+    ADD COLUMN window_start TIMESTAMPTZ,
+    ADD COLUMN window_end TIMESTAMPTZ,
+    ADD COLUMN late BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS delivery_proofs;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS delivery_pin_attempts,
+    DROP COLUMN IF EXISTS delivery_pin_hash;
+
+ALTER TABLE fleets
+    DROP COLUMN IF EXISTS pod_pin,
+    DROP COLUMN IF EXISTS pod_recipient_name,
+    DROP COLUMN IF EXISTS pod_signature,
+    DROP COLUMN IF EXISTS pod_photo;
@@ -0,0 +1,24 @@
+-- the evidence a fleet's drivers must collect before an order counts as
+-- delivered
+ALTER TABLE fleets
+    ADD COLUMN pod_photo BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN pod_signature BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN pod_recipient_name BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN pod_pin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- only the hash of the one-time PIN shared with the recipient is kept, and
+-- wrong attempts are counted so it cannot be guessed
+ALTER TABLE orders
+    ADD COLUMN delivery_pin_hash BYTEA,
+    ADD COLUMN delivery_pin_attempts INTEGER NOT NULL DEFAULT 0;
+
+-- photo_key and signature_key name the images in blob storage
+CREATE TABLE delivery_proofs (
+    order_id UUID PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+    photo_key TEXT NOT NULL DEFAULT '',
+    signature_key TEXT NOT NULL DEFAULT '',
+    recipient_name TEXT NOT NULL DEFAULT '',
+    pin_verified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
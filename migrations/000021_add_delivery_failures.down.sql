@@ -0,0 +1,14 @@
+-- the order_status, order_stop_kind and order_stop_status values added in the
+-- up migration cannot be removed from their enums, so refuse to go down while
+-- any row still uses them rather than leave orders the older code cannot read
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM orders WHERE status IN ('delivery_failed', 'returned'))
+        OR EXISTS (SELECT 1 FROM order_stops WHERE kind = 'return' OR status = 'failed') THEN
+        RAISE EXCEPTION 'orders with failed deliveries remain; resolve them before rolling back';
+    END IF;
+END
+$$;
+
+DROP TABLE IF EXISTS delivery_attempts;
+DROP TYPE IF EXISTS delivery_failure_reason;
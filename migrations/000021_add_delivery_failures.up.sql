@@ -0,0 +1,26 @@
+-- an order whose delivery failed for good is delivery_failed while its driver
+-- takes it back to the sender, and returned once they have. The failed stop
+-- is marked failed and a return stop back at the pickup is added to the
+-- route.
+ALTER TYPE order_status ADD VALUE 'delivery_failed';
+ALTER TYPE order_status ADD VALUE 'returned';
+ALTER TYPE order_stop_kind ADD VALUE 'return';
+ALTER TYPE order_stop_status ADD VALUE 'failed';
+
+CREATE TYPE delivery_failure_reason AS ENUM (
+    'recipient_absent', 'address_not_found', 'refused', 'access_denied', 'damaged', 'other'
+);
+
+-- every failed attempt at a dropoff, including the ones retried
+CREATE TABLE delivery_attempts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    driver_id UUID NOT NULL REFERENCES drivers(id),
+    reason delivery_failure_reason NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    retried BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_delivery_attempts_order ON delivery_attempts(order_id, position);
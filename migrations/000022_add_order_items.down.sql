@@ -0,0 +1,10 @@
+ALTER TABLE drivers
+    DROP COLUMN IF EXISTS max_item_length_cm,
+    DROP COLUMN IF EXISTS cold_chain;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS longest_item_cm,
+    DROP COLUMN IF EXISTS cold_chain,
+    DROP COLUMN IF EXISTS fragile;
+
+DROP TABLE IF EXISTS order_items;
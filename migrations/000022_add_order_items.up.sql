@@ -0,0 +1,26 @@
+-- what an order carries; its load and handling are derived from its items
+CREATE TABLE order_items (
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    description TEXT NOT NULL,
+    quantity INTEGER NOT NULL,
+    weight_grams INTEGER NOT NULL DEFAULT 0,
+    length_cm INTEGER NOT NULL DEFAULT 0,
+    width_cm INTEGER NOT NULL DEFAULT 0,
+    height_cm INTEGER NOT NULL DEFAULT 0,
+    fragile BOOLEAN NOT NULL DEFAULT FALSE,
+    cold_chain BOOLEAN NOT NULL DEFAULT FALSE,
+    declared_value_cents INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (order_id, position)
+);
+
+ALTER TABLE orders
+    ADD COLUMN fragile BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN cold_chain BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN longest_item_cm INTEGER NOT NULL DEFAULT 0;
+
+-- cold_chain is whether the driver's vehicle is refrigerated and
+-- max_item_length_cm the longest item it takes, NULL for unlimited
+ALTER TABLE drivers
+    ADD COLUMN cold_chain BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN max_item_length_cm INTEGER;
@@ -0,0 +1,9 @@
+ALTER TABLE order_stops
+    DROP COLUMN IF EXISTS door_code,
+    DROP COLUMN IF EXISTS access_notes,
+    DROP COLUMN IF EXISTS contact_phone,
+    DROP COLUMN IF EXISTS contact_name,
+    DROP COLUMN IF EXISTS postcode,
+    DROP COLUMN IF EXISTS city,
+    DROP COLUMN IF EXISTS address_line2,
+    DROP COLUMN IF EXISTS address_line1;
@@ -0,0 +1,11 @@
+-- where each stop is, who to meet there and how to get in; empty when the
+-- order was booked with coordinates only
+ALTER TABLE order_stops
+    ADD COLUMN address_line1 TEXT NOT NULL DEFAULT '',
+    ADD COLUMN address_line2 TEXT NOT NULL DEFAULT '',
+    ADD COLUMN city TEXT NOT NULL DEFAULT '',
+    ADD COLUMN postcode TEXT NOT NULL DEFAULT '',
+    ADD COLUMN contact_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN contact_phone TEXT NOT NULL DEFAULT '',
+    ADD COLUMN access_notes TEXT NOT NULL DEFAULT '',
+    ADD COLUMN door_code TEXT NOT NULL DEFAULT '';
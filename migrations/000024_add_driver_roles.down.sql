@@ -0,0 +1,3 @@
+ALTER TABLE drivers DROP COLUMN IF EXISTS role;
+
+DROP TYPE IF EXISTS account_role;
@@ -0,0 +1,5 @@
+CREATE TYPE account_role AS ENUM ('driver', 'dispatcher', 'admin');
+
+-- what an account may do within its fleet; dispatchers run its orders and
+-- drivers, admins also configure it
+ALTER TABLE drivers ADD COLUMN role account_role NOT NULL DEFAULT 'driver';
@@ -0,0 +1,13 @@
+-- the currency of a promo's fixed value, minimum fare and discount cap; the
+-- promo only applies to fares in it. NULL for promos without amounts, which
+-- apply in any currency.
+ALTER TABLE promo_codes ADD COLUMN currency TEXT;
+
+UPDATE promo_codes p SET currency = f.currency
+FROM fleets f
+WHERE p.fleet_id = f.id;
+
+-- promos of every fleet were written for the default currency
+UPDATE promo_codes SET currency = 'USD'
+WHERE currency IS NULL
+  AND (kind = 'fixed' OR min_fare_cents > 0 OR max_discount_cents IS NOT NULL);